@@ -2,8 +2,8 @@ package controllers
 
 import (
 	"kalorize-api/app/services"
+	"kalorize-api/app/storage"
 	"kalorize-api/utils"
-	"net/http"
 	"strings"
 
 	vl "github.com/go-playground/validator/v10"
@@ -17,36 +17,37 @@ type AdminController struct {
 	validate     vl.Validate
 }
 
-func NewAdminController(db *gorm.DB) AdminController {
-	service := services.NewAdminService(db)
+func NewAdminController(db *gorm.DB, fileStorage storage.Storage) AdminController {
+	service := services.NewAdminService(db, fileStorage)
 	controller := AdminController{
 		adminService: service,
-		validate:     *vl.New(),
+		validate:     *utils.NewValidator(),
 	}
 	return controller
 }
 
+type registerGymPayload struct {
+	NamaGym    string  `form:"namaGym" validate:"required"`
+	AlamatGym  string  `form:"alamatGym" validate:"required"`
+	Latitude   float64 `form:"latitude" validate:"required,lat"`
+	Longitude  float64 `form:"longitude" validate:"required,lng"`
+	LinkGoogle string  `form:"linkGoogle" validate:"required,url"`
+}
+
 func (controller *AdminController) RegisterGym(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	type payload struct {
-		NamaGym    string  `form:"namaGym" validate:"required"`
-		AlamatGym  string  `form:"alamatGym" validate:"required"`
-		Latitude   float64 `form:"latitude" validate:"required"`
-		Longitude  float64 `form:"longitude" validate:"required"`
-		LinkGoogle string  `form:"linkGoogle" validate:"required"`
-	}
 
-	payloadValidator := new(payload)
+	payloadValidator := new(registerGymPayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	var registGymPayload utils.GymRequest = utils.GymRequest{
@@ -58,48 +59,53 @@ func (controller *AdminController) RegisterGym(c echo.Context) error {
 	}
 
 	if err := c.Request().ParseMultipartForm(1024); err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+		return utils.Invalid("invalid_form")
 	}
 
-	alias := c.Request().FormValue("alias")
 	uploadedFile, handler, err := c.Request().FormFile("file")
 	if err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Invalid("file_required")
+	}
+	if handler.Size > utils.MaxPhotoSize {
+		return utils.Invalid("photo_too_large")
 	}
 
 	photoRequest := utils.UploadedPhoto{
-		Alias:   alias,
 		File:    uploadedFile,
 		Handler: handler,
 	}
 
-	response := controller.adminService.RegisterGym(token, registGymPayload, photoRequest)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.adminService.RegisterGym(token, requestMeta(c), registGymPayload, photoRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type registerFranchisePayload struct {
+	NamaFranchise      string  `json:"namaFranchise" validate:"required,max=100"`
+	LongitudeFranchise float64 `json:"longitudeFranchise" validate:"required,lng"`
+	LatitudeFranchise  float64 `json:"latitudeFranchise" validate:"required,lat"`
+	EmailFranchise     string  `json:"emailFranchise" validate:"required,email"`
+	PasswordFranchise  string  `json:"passwordFranchise" validate:"required,min=8"`
+	NoTeleponFranchise string  `json:"noTeleponFranchise" validate:"required,phone"`
+	FotoFranchise      string  `json:"fotoFranchise" validate:"required"`
+	LokasiFranchise    string  `json:"lokasiFranchise" validate:"required"`
 }
 
 func (controller *AdminController) RegisterFranchise(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	type payload struct {
-		NamaFranchise      string  `json:"namaFranchise" validate:"required"`
-		LongitudeFranchise float64 `json:"longitudeFranchise" validate:"required"`
-		LatitudeFranchise  float64 `json:"latitudeFranchise" validate:"required"`
-		EmailFranchise     string  `json:"emailFranchise" validate:"required,email"`
-		PasswordFranchise  string  `json:"passwordFranchise" validate:"required"`
-		NoTeleponFranchise string  `json:"noTeleponFranchise" validate:"required"`
-		FotoFranchise      string  `json:"fotoFranchise" validate:"required"`
-		LokasiFranchise    string  `json:"lokasiFranchise" validate:"required"`
-	}
-	payloadValidator := new(payload)
+	payloadValidator := new(registerFranchisePayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 	var registerFranchisePayload utils.FranchiseRequest = utils.FranchiseRequest{
 		NamaFranchise:      payloadValidator.NamaFranchise,
@@ -111,81 +117,197 @@ func (controller *AdminController) RegisterFranchise(c echo.Context) error {
 		FotoFranchise:      payloadValidator.FotoFranchise,
 		LokasiFranchise:    payloadValidator.LokasiFranchise,
 	}
-	response := controller.adminService.RegisterFranchise(token, registerFranchisePayload)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.adminService.RegisterFranchise(token, requestMeta(c), registerFranchisePayload)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type registerMakananPayload struct {
+	NamaMakanan   string      `json:"namaMakanan" validate:"required,max=100"`
+	Kalori        int         `json:"kalori" validate:"required,nutrisi"`
+	Protein       int         `json:"protein" validate:"required,nutrisi"`
+	Bahan         []string    `json:"bahan" validate:"required,min=1,dive,required"`
+	ListFranchise []uuid.UUID `json:"listFranchise"`
+	CookingStep   []string    `json:"cookingStep" validate:"required,min=1,dive,required"`
 }
 
 func (controller *AdminController) RegisterMakanan(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	type payload struct {
-		NamaMakanan   string   `json:"namaMakanan" validate:"required"`
-		Kalori        int      `json:"kalori" validate:"required"`
-		Protein       int      `json:"protein" validate:"required"`
-		Bahan         []string `json:"bahan" validate:"required"`
-		ListFranchise []string `json:"listFranchise" validate:"required"`
-		CookingStep   []string `json:"cookingStep" validate:"required"`
-	}
-	payloadValidator := new(payload)
+	payloadValidator := new(registerMakananPayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 	var registerMakananPayload utils.MakananRequest = utils.MakananRequest{
-		Nama:        payloadValidator.NamaMakanan,
-		Kalori:      payloadValidator.Kalori,
-		Protein:     payloadValidator.Protein,
+		Nama:          payloadValidator.NamaMakanan,
+		Kalori:        payloadValidator.Kalori,
+		Protein:       payloadValidator.Protein,
+		Bahan:         payloadValidator.Bahan,
+		CookingStep:   payloadValidator.CookingStep,
+		ListFranchise: payloadValidator.ListFranchise,
+	}
+	response, err := controller.adminService.RegisterMakanan(token, requestMeta(c), registerMakananPayload)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type attachFranchiseMakananPayload struct {
+	IdFranchise uuid.UUID `json:"idFranchise" validate:"required"`
+	IdMakanan   string    `json:"idMakanan" validate:"required"`
+}
+
+func (controller *AdminController) AttachFranchiseMakanan(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	payloadValidator := new(attachFranchiseMakananPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	response, err := controller.adminService.AttachFranchiseMakanan(token, requestMeta(c), payloadValidator.IdFranchise, payloadValidator.IdMakanan)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) DetachFranchiseMakanan(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	idFranchise, err := uuid.Parse(c.Param("franchiseId"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.DetachFranchiseMakanan(token, requestMeta(c), idFranchise, c.Param("makananId"))
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) UpdateMakananPhoto(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	if err := c.Request().ParseMultipartForm(1024); err != nil {
+		return utils.Invalid("invalid_form")
+	}
+	uploadedFile, handler, err := c.Request().FormFile("file")
+	if err != nil {
+		return utils.Invalid("file_required")
+	}
+	if handler.Size > utils.MaxPhotoSize {
+		return utils.Invalid("photo_too_large")
+	}
+
+	photoRequest := utils.UploadedPhoto{
+		File:    uploadedFile,
+		Handler: handler,
+	}
+	response, err := controller.adminService.UpdateMakananPhoto(token, requestMeta(c), c.Param("id"), photoRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type translateMakananPayload struct {
+	Nama        string   `json:"nama" validate:"required,max=100"`
+	Bahan       []string `json:"bahan" validate:"required,min=1,dive,required"`
+	CookingStep []string `json:"cookingStep" validate:"required,min=1,dive,required"`
+}
+
+func (controller *AdminController) TranslateMakanan(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	payloadValidator := new(translateMakananPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	translationRequest := utils.MakananTranslationRequest{
+		IdMakanan:   c.Param("id"),
+		Locale:      c.Param("locale"),
+		Nama:        payloadValidator.Nama,
 		Bahan:       payloadValidator.Bahan,
 		CookingStep: payloadValidator.CookingStep,
 	}
-	response := controller.adminService.RegisterMakanan(token, registerMakananPayload)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.adminService.TranslateMakanan(token, requestMeta(c), translationRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type registerUserPayload struct {
+	Email        string `form:"email" validate:"required,email"`
+	FullName     string `form:"fullname" validate:"required"`
+	JenisKelamin int    `form:"jenis_kelamin" validate:"jenis_kelamin"`
+	NoTelepon    string `form:"no_telepon" validate:"required,phone"`
+	ReferalCode  string `form:"referal_code" validate:"required"`
+	Umur         int    `form:"umur" validate:"required,umur"`
+	BeratBadan   int    `form:"berat_badan" validate:"required,berat_badan"`
+	TinggiBadan  int    `form:"tinggi_badan" validate:"required,tinggi_badan"`
+	FrekuensiGym int    `form:"frekuensi_gym" validate:"frekuensi_gym"`
+	TargetKalori int    `form:"target_kalori" validate:"target_kalori"`
+	Password     string `form:"password" validate:"required"`
+	Role         string `form:"role" validate:"required,enum=role"`
 }
 
 func (controller *AdminController) RegisterUser(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	type payload struct {
-		Email        string `form:"email" validate:"required,email"`
-		FullName     string `form:"fullname" validate:"required"`
-		JenisKelamin int    `form:"jenis_kelamin" validate:"required"`
-		NoTelepon    string `form:"no_telepon" validate:"required"`
-		ReferalCode  string `form:"referal_code" validate:"required"`
-		Umur         int    `form:"umur" validate:"required"`
-		BeratBadan   int    `form:"berat_badan" validate:"required"`
-		TinggiBadan  int    `form:"tinggi_badan" validate:"required"`
-		FrekuensiGym int    `form:"frekuensi_gym" validate:"required"`
-		TargetKalori int    `form:"target_kalori" validate:"required"`
-		Password     string `form:"password" validate:"required"`
-		Role         string `form:"role" validate:"required"`
-	}
-	payloadValidator := new(payload)
+	payloadValidator := new(registerUserPayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	if err := c.Request().ParseMultipartForm(1024); err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+		return utils.Invalid("invalid_form")
 	}
-	alias := c.Request().FormValue("alias")
 	uploadedFile, handler, err := c.Request().FormFile("file")
 	if err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Invalid("file_required")
+	}
+	if handler.Size > utils.MaxPhotoSize {
+		return utils.Invalid("photo_too_large")
 	}
 
 	photoRequest := utils.UploadedPhoto{
-		Alias:   alias,
 		File:    uploadedFile,
 		Handler: handler,
 	}
@@ -204,81 +326,213 @@ func (controller *AdminController) RegisterUser(c echo.Context) error {
 		FrekuensiGym: payloadValidator.FrekuensiGym,
 		TargetKalori: payloadValidator.TargetKalori,
 	}
-	response := controller.adminService.RegisterUser(token, registerUserPayload, photoRequest)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.adminService.RegisterUser(token, requestMeta(c), registerUserPayload, photoRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type generateGymTokenPayload struct {
+	Uid            uuid.UUID `json:"uid" validate:"required"`
+	Mode           string    `json:"mode" validate:"omitempty,enum=kode_gym_mode"`
+	Plan           string    `json:"plan" validate:"omitempty,enum=plan"`
+	MaxRedemptions int       `json:"maxRedemptions" validate:"min=0"`
+	ExpiredDays    int       `json:"expiredDays" validate:"min=0"`
 }
 
 func (controller *AdminController) GenerateGymToken(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
 
-	type payload struct {
-		Uid uuid.UUID `json:"uid" validate:"required"`
+	payloadValidator := new(generateGymTokenPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
 	}
-	payloadValidator := new(payload)
+	kodeGymRequest := utils.KodeGymRequest{
+		IdGym:          payloadValidator.Uid,
+		Mode:           payloadValidator.Mode,
+		Plan:           payloadValidator.Plan,
+		MaxRedemptions: payloadValidator.MaxRedemptions,
+		ExpiredDays:    payloadValidator.ExpiredDays,
+	}
+	response, err := controller.adminService.GenerateGymToken(token, requestMeta(c), kodeGymRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) GetAllKodeGym(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	idGym := uuid.Nil
+	if gym := c.QueryParam("gym"); gym != "" {
+		id, err := uuid.Parse(gym)
+		if err != nil {
+			return utils.Invalid("invalid_id")
+		}
+		idGym = id
+	}
+	response, err := controller.adminService.GetAllKodeGym(token, idGym)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) RevokeKodeGym(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.RevokeKodeGym(token, requestMeta(c), id)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type assignGymOwnerPayload struct {
+	IdUser uuid.UUID `json:"idUser" validate:"required"`
+	IdGym  uuid.UUID `json:"idGym" validate:"required"`
+}
+
+func (controller *AdminController) AssignGymOwner(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	payloadValidator := new(assignGymOwnerPayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
-	response := controller.adminService.GenerateGymToken(token, payloadValidator.Uid)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.adminService.AssignGymOwner(token, requestMeta(c), payloadValidator.IdUser, payloadValidator.IdGym)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
 }
 
 func (controller *AdminController) GetAllUser(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	filter, err := bindUserFilter(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.adminService.GetAllUser(token, filter)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type bulkUpdateUsersPayload struct {
+	IdUsers []uuid.UUID `json:"idUsers" validate:"required,min=1"`
+	Action  string      `json:"action" validate:"required,enum=bulk_action"`
+	Role    string      `json:"role" validate:"required_if=Action change_role,omitempty,enum=role"`
+	Days    int         `json:"days" validate:"required_if=Action extend_membership,omitempty,min=1,max=3650"`
+}
+
+func (controller *AdminController) BulkUpdateUsers(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	response := controller.adminService.GetAllUser(token)
-	return c.JSON(response.StatusCode, response)
+
+	payloadValidator := new(bulkUpdateUsersPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	response, err := controller.adminService.BulkUpdateUsers(token, requestMeta(c), utils.BulkUserRequest{
+		IdUsers: payloadValidator.IdUsers,
+		Action:  payloadValidator.Action,
+		Role:    payloadValidator.Role,
+		Days:    payloadValidator.Days,
+	})
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
 }
 
 func (controller *AdminController) GetUserById(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
 
-	id := c.Param("id")
-	uuid := uuid.MustParse(id)
-	response := controller.adminService.GetUserById(token, uuid)
-	return c.JSON(response.StatusCode, response)
+	idUser, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.GetUserById(token, idUser)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type updateUserPayload struct {
+	FullName     string `form:"fullname" validate:"max=100"`
+	Email        string `form:"email" validate:"omitempty,email"`
+	NoTelepon    string `form:"noTelepon" validate:"omitempty,phone"`
+	Password     string `form:"password"`
+	Umur         int    `form:"umur" validate:"umur"`
+	BeratBadan   int    `form:"beratBadan" validate:"berat_badan"`
+	TinggiBadan  int    `form:"tinggiBadan" validate:"tinggi_badan"`
+	FrekuensiGym int    `form:"frekuensiGym" validate:"frekuensi_gym"`
+	TargetKalori int    `form:"targetKalori" validate:"target_kalori"`
+	Role         string `form:"role" validate:"omitempty,enum=role"`
 }
 
 func (controller *AdminController) UpdateUser(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
 
-	id := c.Param("id")
-	uuid := uuid.MustParse(id)
-	type payload struct {
-		FullName     string `form:"fullname"`
-		Email        string `form:"email"`
-		NoTelepon    string `form:"noTelepon"`
-		Password     string `form:"password"`
-		Umur         int    `form:"umur"`
-		BeratBadan   int    `form:"beratBadan"`
-		TinggiBadan  int    `form:"tinggiBadan"`
-		FrekuensiGym int    `form:"frekuensiGym"`
-		TargetKalori int    `form:"targetKalori"`
-		Role         string `form:"role"`
+	idUser, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
 	}
-	payloadValidator := new(payload)
+	payloadValidator := new(updateUserPayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 	var updateUserPayload utils.UserRequest = utils.UserRequest{
 		Email:        payloadValidator.Email,
@@ -292,19 +546,364 @@ func (controller *AdminController) UpdateUser(c echo.Context) error {
 		FrekuensiGym: payloadValidator.FrekuensiGym,
 		TargetKalori: payloadValidator.TargetKalori,
 	}
-	response := controller.adminService.UpdateUser(token, uuid, updateUserPayload)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.adminService.UpdateUser(token, requestMeta(c), idUser, updateUserPayload)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
 }
 
 func (controller *AdminController) DeleteUser(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	idUser, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.DeleteUser(token, requestMeta(c), idUser)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) RestoreUser(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.RestoreUser(token, requestMeta(c), id)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) EraseUser(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.EraseUser(token, requestMeta(c), id)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) GetAllGym(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	response, err := controller.adminService.GetAllGym(token)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) GetGymById(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.GetGymById(token, idGym)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type updateGymPayload struct {
+	NamaGym    string  `json:"namaGym"`
+	AlamatGym  string  `json:"alamatGym"`
+	Latitude   float64 `json:"latitude" validate:"lat"`
+	Longitude  float64 `json:"longitude" validate:"lng"`
+	LinkGoogle string  `json:"linkGoogle" validate:"omitempty,url"`
+}
+
+func (controller *AdminController) UpdateGym(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+
+	payloadValidator := new(updateGymPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	gymRequest := utils.GymRequest{
+		NamaGym:    payloadValidator.NamaGym,
+		AlamatGym:  payloadValidator.AlamatGym,
+		Latitude:   payloadValidator.Latitude,
+		Longitude:  payloadValidator.Longitude,
+		LinkGoogle: payloadValidator.LinkGoogle,
+	}
+	response, err := controller.adminService.UpdateGym(token, requestMeta(c), idGym, gymRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) UpdateGymPhoto(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
 
-	id := c.Param("id")
-	uuid := uuid.MustParse(id)
-	response := controller.adminService.DeleteUser(token, uuid)
-	return c.JSON(response.StatusCode, response)
+	if err := c.Request().ParseMultipartForm(1024); err != nil {
+		return utils.Invalid("invalid_form")
+	}
+	uploadedFile, handler, err := c.Request().FormFile("file")
+	if err != nil {
+		return utils.Invalid("file_required")
+	}
+	if handler.Size > utils.MaxPhotoSize {
+		return utils.Invalid("photo_too_large")
+	}
+
+	photoRequest := utils.UploadedPhoto{
+		File:    uploadedFile,
+		Handler: handler,
+	}
+	response, err := controller.adminService.UpdateGymPhoto(token, requestMeta(c), idGym, photoRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) DeactivateGym(c echo.Context) error {
+	return controller.setGymActive(c, false)
+}
+
+func (controller *AdminController) ActivateGym(c echo.Context) error {
+	return controller.setGymActive(c, true)
+}
+
+func (controller *AdminController) setGymActive(c echo.Context, active bool) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.SetGymActive(token, requestMeta(c), idGym, active)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) DeleteGym(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.DeleteGym(token, requestMeta(c), idGym)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) GetGymMembers(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.GetGymMembers(token, idGym)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) GetAllFranchise(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	response, err := controller.adminService.GetAllFranchise(token)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) GetFranchiseById(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idFranchise, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.GetFranchiseById(token, idFranchise)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type updateFranchisePayload struct {
+	NamaFranchise      string  `json:"namaFranchise"`
+	LongitudeFranchise float64 `json:"longitudeFranchise" validate:"lng"`
+	LatitudeFranchise  float64 `json:"latitudeFranchise" validate:"lat"`
+	EmailFranchise     string  `json:"emailFranchise" validate:"omitempty,email"`
+	PasswordFranchise  string  `json:"passwordFranchise" validate:"omitempty,min=8"`
+	NoTeleponFranchise string  `json:"noTeleponFranchise" validate:"omitempty,phone"`
+	LokasiFranchise    string  `json:"lokasiFranchise"`
+}
+
+func (controller *AdminController) UpdateFranchise(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idFranchise, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+
+	payloadValidator := new(updateFranchisePayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	franchiseRequest := utils.FranchiseRequest{
+		NamaFranchise:      payloadValidator.NamaFranchise,
+		EmailFranchise:     payloadValidator.EmailFranchise,
+		PasswordFranchise:  payloadValidator.PasswordFranchise,
+		NoTeleponFranchise: payloadValidator.NoTeleponFranchise,
+		LongitudeFranchise: payloadValidator.LongitudeFranchise,
+		LatitudeFranchise:  payloadValidator.LatitudeFranchise,
+		LokasiFranchise:    payloadValidator.LokasiFranchise,
+	}
+	response, err := controller.adminService.UpdateFranchise(token, requestMeta(c), idFranchise, franchiseRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) UpdateFranchisePhoto(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idFranchise, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+
+	if err := c.Request().ParseMultipartForm(1024); err != nil {
+		return utils.Invalid("invalid_form")
+	}
+	uploadedFile, handler, err := c.Request().FormFile("file")
+	if err != nil {
+		return utils.Invalid("file_required")
+	}
+	if handler.Size > utils.MaxPhotoSize {
+		return utils.Invalid("photo_too_large")
+	}
+
+	photoRequest := utils.UploadedPhoto{
+		File:    uploadedFile,
+		Handler: handler,
+	}
+	response, err := controller.adminService.UpdateFranchisePhoto(token, requestMeta(c), idFranchise, photoRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) DeleteFranchise(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idFranchise, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.adminService.DeleteFranchise(token, requestMeta(c), idFranchise)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AdminController) GetAuditLogs(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	filter, err := bindAuditLogFilter(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.adminService.GetAuditLogs(token, filter)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
 }
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"kalorize-api/utils"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// requestMeta describes the request for the audit log.
+func requestMeta(c echo.Context) utils.RequestMeta {
+	return utils.RequestMeta{
+		IpAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+		Method:    c.Request().Method,
+		Path:      c.Request().URL.Path,
+	}
+}
+
+// bindAuditLogFilter reads actor, entity, entityId, action, from, to (RFC
+// 3339 or YYYY-MM-DD), page and limit from the query string.
+func bindAuditLogFilter(c echo.Context) (utils.AuditLogFilter, error) {
+	filter := utils.AuditLogFilter{
+		EntityType: c.QueryParam("entity"),
+		EntityId:   c.QueryParam("entityId"),
+		Action:     c.QueryParam("action"),
+		Page:       1,
+		Limit:      defaultPageLimit,
+	}
+	var err error
+	if actor := c.QueryParam("actor"); actor != "" {
+		idActor, err := uuid.Parse(actor)
+		if err != nil {
+			return filter, invalidQuery("actor", "uuid")
+		}
+		filter.IdActor = &idActor
+	}
+	if from := c.QueryParam("from"); from != "" {
+		if filter.From, err = parseAuditTime(from); err != nil {
+			return filter, invalidQuery("from", "datetime")
+		}
+	}
+	if to := c.QueryParam("to"); to != "" {
+		if filter.To, err = parseAuditTime(to); err != nil {
+			return filter, invalidQuery("to", "datetime")
+		}
+		if len(to) == len("2006-01-02") {
+			// A bare date includes the whole day.
+			filter.To = filter.To.AddDate(0, 0, 1)
+		}
+	}
+	if page := c.QueryParam("page"); page != "" {
+		if filter.Page, err = strconv.Atoi(page); err != nil || filter.Page < 1 {
+			return filter, invalidQuery("page", "gt", 0)
+		}
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 1 || filter.Limit > maxPageLimit {
+			return filter, invalidQuery("limit", "between", 1, maxPageLimit)
+		}
+	}
+	return filter, nil
+}
+
+func parseAuditTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
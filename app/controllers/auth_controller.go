@@ -19,61 +19,90 @@ func NewAuthController(db *gorm.DB) AuthController {
 	service := services.NewAuthService(db)
 	controller := AuthController{
 		authService: service,
-		validate:    *vl.New(),
+		validate:    *utils.NewValidator(),
 	}
 	return controller
 }
 
+type refreshPayload struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
 func (controller *AuthController) Refresh(c echo.Context) error {
-	type payload struct {
-		RefreshToken string `json:"refreshToken"`
+	response, err := controller.refresh(c)
+	if err != nil {
+		return err
 	}
+	return respond(c, response)
+}
 
-	payloadValidator := new(payload)
+func (controller *AuthController) refresh(c echo.Context) (utils.Response, error) {
+	payloadValidator := new(refreshPayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
+	}
+
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return utils.Response{}, err
 	}
 
-	response := controller.authService.Refresh(payloadValidator.RefreshToken)
-	return c.JSON(response.StatusCode, response)
+	return controller.authService.Refresh(payloadValidator.RefreshToken)
+}
+
+type loginPayload struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 func (controller *AuthController) Login(c echo.Context) error {
-	type payload struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+	response, err := controller.login(c)
+	if err != nil {
+		return err
 	}
+	return respond(c, response)
+}
 
-	payloadValidator := new(payload)
+func (controller *AuthController) login(c echo.Context) (utils.Response, error) {
+	payloadValidator := new(loginPayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 
-	response := controller.authService.Login(payloadValidator.Email, payloadValidator.Password)
-	return c.JSON(response.StatusCode, response)
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+
+	return controller.authService.Login(payloadValidator.Email, payloadValidator.Password)
+}
+
+type registerPayload struct {
+	NamaLengkap          string `json:"namaLengkap" validate:"required"`
+	Email                string `json:"email" validate:"required,email"`
+	Password             string `json:"password" validate:"required"`
+	PasswordConfirmation string `json:"passwordConfirmation" validate:"required,eqfield=Password"`
+	GymKode              string `json:"gymKode"`
+	ReferalCode          string `json:"referalCode"`
 }
 
 func (controller *AuthController) Register(c echo.Context) error {
-	type payload struct {
-		NamaLengkap          string `json:"namaLengkap" validate:"required"`
-		Email                string `json:"email" validate:"required,email"`
-		Password             string `json:"password" validate:"required"`
-		PasswordConfirmation string `json:"passwordConfirmation" validate:"required,eqfield=Password"`
-		GymKode              string `json:"gymKode"`
-		ReferalCode          string `json:"referalCode"`
-		Role                 string `json:"role"`
+	response, err := controller.register(c)
+	if err != nil {
+		return err
 	}
+	return respond(c, response)
+}
 
-	payloadValidator := new(payload)
+func (controller *AuthController) register(c echo.Context) (utils.Response, error) {
+	payloadValidator := new(registerPayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 	var regisUserPayload utils.UserRequest = utils.UserRequest{
 		Fullname:             payloadValidator.NamaLengkap,
@@ -81,31 +110,43 @@ func (controller *AuthController) Register(c echo.Context) error {
 		Password:             payloadValidator.Password,
 		PasswordConfirmation: payloadValidator.PasswordConfirmation,
 		ReferalCode:          payloadValidator.ReferalCode,
-		Role:                 payloadValidator.Role,
 	}
 
-	response := controller.authService.Register(regisUserPayload, payloadValidator.GymKode)
-	return c.JSON(response.StatusCode, response)
+	return controller.authService.Register(regisUserPayload, payloadValidator.GymKode)
 }
 
 func (controller *AuthController) GetUser(c echo.Context) error {
+	response, err := controller.getUser(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AuthController) getUser(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	response := controller.authService.GetLoggedInUser(token)
-	return c.JSON(response.StatusCode, response)
+	return controller.authService.GetLoggedInUser(token)
 }
 
 func (controller *AuthController) Logout(c echo.Context) error {
+	response, err := controller.logout(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *AuthController) logout(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	response := controller.authService.Logout(token)
-	return c.JSON(response.StatusCode, response)
+	return controller.authService.Logout(token)
 }
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"kalorize-api/formatter"
+	v2 "kalorize-api/formatter/v2"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (controller *AuthController) LoginV2(c echo.Context) error {
+	response, err := controller.login(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatTokens(response.Data.(formatter.TokenFormat)))
+}
+
+func (controller *AuthController) RegisterV2(c echo.Context) error {
+	response, err := controller.register(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusCreated, v2.FormatTokens(response.Data.(formatter.TokenFormat)))
+}
+
+func (controller *AuthController) RefreshV2(c echo.Context) error {
+	response, err := controller.refresh(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatTokens(response.Data.(formatter.TokenFormat)))
+}
+
+func (controller *AuthController) LogoutV2(c echo.Context) error {
+	if _, err := controller.logout(c); err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusNoContent, nil)
+}
+
+func (controller *AuthController) GetUserV2(c echo.Context) error {
+	response, err := controller.getUser(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatMe(response.Data.(formatter.LoggedInUserFormat)))
+}
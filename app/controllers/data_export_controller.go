@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"kalorize-api/app/services"
+	"kalorize-api/app/storage"
+	"kalorize-api/utils"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type DataExportController struct {
+	dataExportService services.DataExportService
+}
+
+func NewDataExportController(db *gorm.DB, fileStorage storage.Storage) DataExportController {
+	return DataExportController{
+		dataExportService: services.NewDataExportService(db, fileStorage),
+	}
+}
+
+func (controller *DataExportController) RequestExport(c echo.Context) error {
+	response, err := controller.requestExport(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *DataExportController) requestExport(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	return controller.dataExportService.RequestExport(token)
+}
+
+func (controller *DataExportController) GetExport(c echo.Context) error {
+	response, err := controller.getExport(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *DataExportController) getExport(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idExport, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Response{}, utils.Invalid("invalid_id")
+	}
+	return controller.dataExportService.GetExport(token, idExport)
+}
+
+func (controller *DataExportController) DownloadExport(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idExport, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	reader, err := controller.dataExportService.DownloadExport(token, idExport)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="kalorize-data-`+idExport.String()+`.zip"`)
+	return c.Stream(200, "application/zip", reader)
+}
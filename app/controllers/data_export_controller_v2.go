@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"kalorize-api/app/models"
+	v2 "kalorize-api/formatter/v2"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (controller *DataExportController) RequestExportV2(c echo.Context) error {
+	response, err := controller.requestExport(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusAccepted, v2.FormatDataExport(response.Data.(models.DataExport)))
+}
+
+func (controller *DataExportController) GetExportV2(c echo.Context) error {
+	response, err := controller.getExport(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatDataExport(response.Data.(models.DataExport)))
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"errors"
+	"kalorize-api/utils"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	vl "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPErrorHandler writes the errors returned by handlers. Service errors
+// keep their code and field details, validation failures are reported per
+// field and anything unexpected becomes a 500 without leaking the cause.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	appErr, status := toError(err)
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request().Context(), "request failed", "error", err)
+	}
+
+	response := utils.Response{
+		StatusCode:  status,
+		Messages:    appErr.Code,
+		MessageArgs: appErr.Args,
+		Code:        appErr.Code,
+		Errors:      appErr.Fields,
+	}
+	switch {
+	case c.Request().Method == http.MethodHead:
+		err = c.NoContent(status)
+	case isV2(c):
+		err = respondErrorV2(c, status, appErr)
+	default:
+		err = respond(c, response)
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "writing error response failed", "error", err)
+	}
+}
+
+// toError converts err to a service error and picks the response status.
+// Errors raised by echo itself, such as an unknown route or a malformed
+// body, keep their status.
+func toError(err error) (*utils.Error, int) {
+	var appErr *utils.Error
+	if errors.As(err, &appErr) {
+		return appErr, appErr.StatusCode()
+	}
+	var validationErrs vl.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		appErr = utils.ValidationFailed(validationErrs)
+		return appErr, appErr.StatusCode()
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		code := strings.ReplaceAll(strings.ToLower(http.StatusText(httpErr.Code)), " ", "_")
+		if httpErr.Code >= http.StatusInternalServerError {
+			return &utils.Error{Code: code, Err: err}, httpErr.Code
+		}
+		return &utils.Error{Kind: utils.KindValidation, Code: code}, httpErr.Code
+	}
+	return utils.Internal("unhandled error", err), http.StatusInternalServerError
+}
+
+// respond writes response as JSON in the language the client asked for.
+func respond(c echo.Context, response utils.Response) error {
+	locale := requestLocale(c)
+	c.Response().Header().Set("Content-Language", locale)
+	return c.JSON(response.StatusCode, utils.Localize(locale, response))
+}
+
+// requestLocale picks the language of the response from Accept-Language.
+// Responses that depend on it are marked as varying with the header.
+func requestLocale(c echo.Context) string {
+	if locale, ok := c.Get("locale").(string); ok {
+		return locale
+	}
+	locale := utils.Locale(c.Request().Header.Get("Accept-Language"))
+	c.Set("locale", locale)
+	c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+	return locale
+}
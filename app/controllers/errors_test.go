@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"kalorize-api/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	vl "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	validationErr := vl.New().Struct(struct {
+		Email string `validate:"required"`
+	}{})
+
+	tests := []struct {
+		name        string
+		err         error
+		want        int
+		wantCode    string
+		wantMessage string
+		wantField   string
+	}{
+		{name: "service error", err: utils.Conflict("email_taken"), want: http.StatusConflict, wantCode: "email_taken", wantMessage: "Email is already registered"},
+		{name: "wrapped service error", err: errors.Join(errors.New("context"), utils.NotFound("gym_not_found")), want: http.StatusNotFound, wantCode: "gym_not_found"},
+		{name: "field errors", err: utils.Invalid("validation_failed", utils.Field("limit", "between", 1, 100)), want: http.StatusBadRequest, wantCode: "validation_failed", wantField: "limit"},
+		{name: "validator errors", err: validationErr, want: http.StatusBadRequest, wantCode: "validation_failed", wantMessage: "Invalid request", wantField: "Email"},
+		{name: "unknown route", err: echo.ErrNotFound, want: http.StatusNotFound, wantCode: "not_found", wantMessage: "Not found"},
+		{name: "body too large", err: echo.NewHTTPError(http.StatusRequestEntityTooLarge), want: http.StatusRequestEntityTooLarge, wantCode: "request_entity_too_large"},
+		{name: "echo server error", err: echo.NewHTTPError(http.StatusServiceUnavailable, "database down"), want: http.StatusServiceUnavailable, wantCode: "service_unavailable"},
+		{name: "internal error", err: utils.Internal("Failed to get gym", errors.New("dial tcp: secret-host")), want: http.StatusInternalServerError, wantCode: "internal_error", wantMessage: "Something went wrong on our side, please try again later"},
+		{name: "unexpected error", err: errors.New("dial tcp: secret-host"), want: http.StatusInternalServerError, wantCode: "internal_error"},
+	}
+	e := echo.New()
+	for _, test := range tests {
+		for _, version := range []int{1, 2} {
+			t.Run(test.name+" v"+strconv.Itoa(version), func(t *testing.T) {
+				request := httptest.NewRequest(http.MethodGet, "/", nil)
+				request.Header.Set("Accept-Language", "en")
+				recorder := httptest.NewRecorder()
+				c := e.NewContext(request, recorder)
+				c.Set(apiVersionKey, version)
+
+				HTTPErrorHandler(test.err, c)
+
+				if recorder.Code != test.want {
+					t.Errorf("status = %d, want %d", recorder.Code, test.want)
+				}
+				if strings.Contains(recorder.Body.String(), "secret-host") {
+					t.Errorf("body %s leaks the cause", recorder.Body)
+				}
+				var code, message string
+				var fields []utils.FieldError
+				if version == 2 {
+					var body errorBodyV2
+					if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+						t.Fatalf("body %s: %v", recorder.Body, err)
+					}
+					code, message, fields = body.Error.Code, body.Error.Message, body.Error.Fields
+					if strings.Contains(recorder.Body.String(), "statusCode") {
+						t.Errorf("v2 body %s has a statusCode", recorder.Body)
+					}
+				} else {
+					var body struct {
+						StatusCode int                `json:"statusCode"`
+						Messages   string             `json:"messages"`
+						Code       string             `json:"code"`
+						Errors     []utils.FieldError `json:"errors"`
+					}
+					if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+						t.Fatalf("body %s: %v", recorder.Body, err)
+					}
+					if body.StatusCode != test.want {
+						t.Errorf("statusCode = %d, want %d", body.StatusCode, test.want)
+					}
+					code, message, fields = body.Code, body.Messages, body.Errors
+				}
+				if code != test.wantCode {
+					t.Errorf("code = %q, want %q", code, test.wantCode)
+				}
+				if test.wantMessage != "" && message != test.wantMessage {
+					t.Errorf("message = %q, want %q", message, test.wantMessage)
+				}
+				if test.wantField != "" && (len(fields) != 1 || fields[0].Field != test.wantField || fields[0].Message == "") {
+					t.Errorf("fields = %+v, want a localised error for %s", fields, test.wantField)
+				}
+				if language := recorder.Header().Get("Content-Language"); language != "en" {
+					t.Errorf("Content-Language = %q, want en", language)
+				}
+			})
+		}
+	}
+}
+
+func TestHTTPErrorHandlerWithoutBody(t *testing.T) {
+	e := echo.New()
+	t.Run("HEAD", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodHead, "/", nil), recorder)
+		HTTPErrorHandler(utils.NotFound("gym_not_found"), c)
+		if recorder.Code != http.StatusNotFound || recorder.Body.Len() != 0 {
+			t.Errorf("HEAD = %d %q, want 404 without a body", recorder.Code, recorder.Body)
+		}
+	})
+	t.Run("response already sent", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), recorder)
+		if err := c.String(http.StatusOK, "partial"); err != nil {
+			t.Fatal(err)
+		}
+		HTTPErrorHandler(utils.Internal("late failure", nil), c)
+		if recorder.Code != http.StatusOK || recorder.Body.String() != "partial" {
+			t.Errorf("response = %d %q, want the one already sent", recorder.Code, recorder.Body)
+		}
+	})
+}
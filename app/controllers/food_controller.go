@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"kalorize-api/app/services"
+	"kalorize-api/utils"
 	"strings"
 
 	vl "github.com/go-playground/validator/v10"
@@ -18,33 +19,65 @@ func NewMakananController(db *gorm.DB) MakananController {
 	service := services.NewMakananService(db)
 	controller := MakananController{
 		makananService: service,
-		validate:       *vl.New(),
+		validate:       *utils.NewValidator(),
 	}
 	return controller
 }
 
 func (controller *MakananController) GetMakananCSV(c echo.Context) error {
 
+	locale := requestLocale(c)
 	c.Response().Header().Set("Content-Type", "text/csv")
 	c.Response().Header().Set("Content-Disposition", "attachment; filename=makanan.csv")
-	controller.makananService.GetMakananCSV(c)
-	return nil
+	c.Response().Header().Set("Content-Language", locale)
+	_, err := controller.makananService.GetMakananCSV(c, locale)
+	return err
 }
 
 func (controller *MakananController) GetAllMakanan(c echo.Context) error {
+	response, err := controller.getAllMakanan(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *MakananController) getAllMakanan(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	return controller.makananService.GetAllMakanan(requestLocale(c))
+}
+
+func (controller *MakananController) GetFranchiseByMakanan(c echo.Context) error {
+	response, err := controller.getFranchiseByMakanan(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *MakananController) getFranchiseByMakanan(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
-	response := controller.makananService.GetAllMakanan()
-	return c.JSON(response.StatusCode, response)
+	return controller.makananService.GetFranchiseByMakanan(c.Param("makananId"))
 }
 
 func (controller *MakananController) GetMakananById(c echo.Context) error {
+	response, err := controller.getMakananById(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *MakananController) getMakananById(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
-	response := controller.makananService.GetMakananById(c.Param("makananId"))
-	return c.JSON(response.StatusCode, response)
+	return controller.makananService.GetMakananById(c.Param("makananId"), requestLocale(c))
 }
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"kalorize-api/formatter"
+	v2 "kalorize-api/formatter/v2"
+	"kalorize-api/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (controller *MakananController) GetAllMakananV2(c echo.Context) error {
+	page, limit, err := bindPage(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.getAllMakanan(c)
+	if err != nil {
+		return err
+	}
+	makanan := response.Data.([]formatter.MakananFormat)
+	start, end := utils.PageBounds(len(makanan), page, limit)
+	return respondListV2(c, v2.FormatMakanans(makanan[start:end]), v2.FormatPagination(page, limit, len(makanan)))
+}
+
+func (controller *MakananController) GetMakananByIdV2(c echo.Context) error {
+	response, err := controller.getMakananById(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatMakanan(response.Data.(formatter.MakananFormat)))
+}
+
+func (controller *MakananController) GetFranchiseByMakananV2(c echo.Context) error {
+	page, limit, err := bindPage(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.getFranchiseByMakanan(c)
+	if err != nil {
+		return err
+	}
+	franchises := response.Data.([]formatter.FranchiseFormat)
+	start, end := utils.PageBounds(len(franchises), page, limit)
+	return respondListV2(c, v2.FormatFranchises(franchises[start:end]), v2.FormatPagination(page, limit, len(franchises)))
+}
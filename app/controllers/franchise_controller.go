@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"kalorize-api/app/services"
+	"kalorize-api/utils"
+	"strings"
+	"time"
+
+	vl "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type FranchiseController struct {
+	franchiseService *services.FranchiseService
+	validate         vl.Validate
+}
+
+func NewFranchiseController(db *gorm.DB) FranchiseController {
+	service := services.NewFranchiseService(db)
+	controller := FranchiseController{
+		franchiseService: service,
+		validate:         *utils.NewValidator(),
+	}
+	return controller
+}
+
+func (controller *FranchiseController) GetAllFranchise(c echo.Context) error {
+	response, err := controller.franchiseService.GetAllFranchise()
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *FranchiseController) GetFranchiseById(c echo.Context) error {
+	response, err := controller.franchiseService.GetFranchiseById(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *FranchiseController) GetFranchiseMenu(c echo.Context) error {
+	response, err := controller.franchiseService.GetFranchiseMenu(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *FranchiseController) GetNearbyFranchise(c echo.Context) error {
+	response, err := controller.getNearbyFranchise(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *FranchiseController) getNearbyFranchise(c echo.Context) (utils.Response, error) {
+	nearbyRequest, err := bindNearbyRequest(c)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	return controller.franchiseService.GetNearbyFranchise(nearbyRequest)
+}
+
+type franchiseLoginPayload struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (controller *FranchiseController) Login(c echo.Context) error {
+	payloadValidator := new(franchiseLoginPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	response, err := controller.franchiseService.Login(payloadValidator.Email, payloadValidator.Password)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *FranchiseController) GetOwnMenu(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	response, err := controller.franchiseService.GetOwnMenu(token)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type updateMenuItemPayload struct {
+	Harga    *int  `json:"harga" validate:"omitempty,gte=0"`
+	Tersedia *bool `json:"tersedia"`
+}
+
+func (controller *FranchiseController) UpdateMenuItem(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	payloadValidator := new(updateMenuItemPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	menuRequest := utils.FranchiseMenuRequest{
+		Harga:    payloadValidator.Harga,
+		Tersedia: payloadValidator.Tersedia,
+	}
+	response, err := controller.franchiseService.UpdateMenuItem(token, c.Param("makananId"), menuRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type setOutOfStockPayload struct {
+	HabisSampai time.Time `json:"habisSampai" validate:"required"`
+}
+
+func (controller *FranchiseController) SetOutOfStock(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	payloadValidator := new(setOutOfStockPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	response, err := controller.franchiseService.SetOutOfStock(token, c.Param("makananId"), payloadValidator.HabisSampai)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *FranchiseController) ClearOutOfStock(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	response, err := controller.franchiseService.SetOutOfStock(token, c.Param("makananId"), time.Time{})
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"kalorize-api/formatter"
+	v2 "kalorize-api/formatter/v2"
+	"kalorize-api/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (controller *FranchiseController) GetAllFranchiseV2(c echo.Context) error {
+	page, limit, err := bindPage(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.franchiseService.GetAllFranchise()
+	if err != nil {
+		return err
+	}
+	franchises := response.Data.([]formatter.FranchiseFormat)
+	start, end := utils.PageBounds(len(franchises), page, limit)
+	return respondListV2(c, v2.FormatFranchises(franchises[start:end]), v2.FormatPagination(page, limit, len(franchises)))
+}
+
+func (controller *FranchiseController) GetFranchiseByIdV2(c echo.Context) error {
+	response, err := controller.franchiseService.GetFranchiseById(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatFranchise(response.Data.(formatter.FranchiseFormat)))
+}
+
+func (controller *FranchiseController) GetFranchiseMenuV2(c echo.Context) error {
+	page, limit, err := bindPage(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.franchiseService.GetFranchiseMenu(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	menu := response.Data.([]formatter.FranchiseMenuFormat)
+	start, end := utils.PageBounds(len(menu), page, limit)
+	return respondListV2(c, v2.FormatMenu(menu[start:end]), v2.FormatPagination(page, limit, len(menu)))
+}
+
+func (controller *FranchiseController) GetNearbyFranchiseV2(c echo.Context) error {
+	response, err := controller.getNearbyFranchise(c)
+	if err != nil {
+		return err
+	}
+	page := response.Data.(utils.Page)
+	return respondListV2(c, v2.FormatNearbyFranchises(page.Items.([]formatter.NearbyFranchiseFormat)), v2.FormatPagination(page.Page, page.Limit, page.Total))
+}
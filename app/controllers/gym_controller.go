@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"kalorize-api/app/services"
+	"kalorize-api/utils"
 	"strings"
 
 	vl "github.com/go-playground/validator/v10"
@@ -18,55 +19,88 @@ func NewGymController(db *gorm.DB) GymController {
 	service := services.NewGymService(db)
 	controller := GymController{
 		gymService: service,
-		validate:   *vl.New(),
+		validate:   *utils.NewValidator(),
 	}
 	return controller
 }
 
-func (controller *GymController) CheckGymCode(c echo.Context) error {
-	type payload struct {
-		GymCode string `json:"gym_code" validate:"required"`
-	}
+type checkGymCodePayload struct {
+	GymCode string `json:"gym_code" validate:"required"`
+}
 
-	payloadValidator := new(payload)
+func (controller *GymController) CheckGymCode(c echo.Context) error {
+	payloadValidator := new(checkGymCodePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
-	response := controller.gymService.CheckGymCode(payloadValidator.GymCode)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.gymService.CheckGymCode(payloadValidator.GymCode)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
 }
 
-func (controller *GymController) IsUsed(c echo.Context) error {
-	type payload struct {
-		GymCode string `json:"gym_code" validate:"required"`
-	}
+type isUsedPayload struct {
+	GymCode string `json:"gym_code" validate:"required"`
+}
 
-	payloadValidator := new(payload)
+func (controller *GymController) IsUsed(c echo.Context) error {
+	payloadValidator := new(isUsedPayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
-	response := controller.gymService.IsUsed(payloadValidator.GymCode)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.gymService.IsUsed(payloadValidator.GymCode)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
 }
 
 // Get All Gym
 func (controller *GymController) GetAllGym(c echo.Context) error {
+	response, err := controller.getAllGym(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *GymController) getAllGym(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	return controller.gymService.GetAllGym()
+}
+
+func (controller *GymController) GetNearbyGym(c echo.Context) error {
+	response, err := controller.getNearbyGym(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *GymController) getNearbyGym(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	nearbyRequest, err := bindNearbyRequest(c)
+	if err != nil {
+		return utils.Response{}, err
 	}
-	response := controller.gymService.GetAllGym()
-	return c.JSON(response.StatusCode, response)
+	return controller.gymService.GetNearbyGym(nearbyRequest)
 }
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/formatter"
+	v2 "kalorize-api/formatter/v2"
+	"kalorize-api/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAllGymV2 lists the active gyms. Unlike version 1, having none is an
+// empty page rather than a 404.
+func (controller *GymController) GetAllGymV2(c echo.Context) error {
+	page, limit, err := bindPage(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.getAllGym(c)
+	var appErr *utils.Error
+	if errors.As(err, &appErr) && appErr.Code == "gym_not_found" {
+		response.Data, err = []models.Gym{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	gyms := response.Data.([]models.Gym)
+	start, end := utils.PageBounds(len(gyms), page, limit)
+	return respondListV2(c, v2.FormatGyms(gyms[start:end]), v2.FormatPagination(page, limit, len(gyms)))
+}
+
+func (controller *GymController) GetNearbyGymV2(c echo.Context) error {
+	response, err := controller.getNearbyGym(c)
+	if err != nil {
+		return err
+	}
+	page := response.Data.(utils.Page)
+	return respondListV2(c, v2.FormatNearbyGyms(page.Items.([]formatter.NearbyGymFormat)), v2.FormatPagination(page.Page, page.Limit, page.Total))
+}
+
+type checkKodeGymPayload struct {
+	GymKode string `json:"gymKode" validate:"required"`
+}
+
+// CheckKodeGymV2 answers 204 when the code can be used to register.
+func (controller *GymController) CheckKodeGymV2(c echo.Context) error {
+	payloadValidator := new(checkKodeGymPayload)
+
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+
+	if _, err := controller.gymService.CheckGymCode(payloadValidator.GymKode); err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusNoContent, nil)
+}
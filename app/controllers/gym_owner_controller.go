@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"kalorize-api/app/services"
+	"kalorize-api/utils"
+	"strconv"
 	"strings"
 
 	vl "github.com/go-playground/validator/v10"
@@ -19,32 +21,192 @@ func NewGymOwnerController(db *gorm.DB) GymOwnerController {
 	service := services.NewGymOwnerService(db)
 	controller := GymOwnerController{
 		gymOwnerService: service,
-		validate:        *vl.New(),
+		validate:        *utils.NewValidator(),
 	}
 	return controller
 }
 
-func (controller *GymOwnerController) GenerateKodeGym(c echo.Context) error {
+func (controller *GymOwnerController) GetGyms(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	response, err := controller.gymOwnerService.GetGyms(token)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type gymOwnerUpdateGymPayload struct {
+	NamaGym    string  `json:"namaGym"`
+	AlamatGym  string  `json:"alamatGym"`
+	Latitude   float64 `json:"latitude" validate:"lat"`
+	Longitude  float64 `json:"longitude" validate:"lng"`
+	LinkGoogle string  `json:"linkGoogle" validate:"omitempty,url"`
+}
 
+func (controller *GymOwnerController) UpdateGym(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
 	}
 
-	type payload struct {
-		IdGym uuid.UUID `json:"idGym" validate:"required"`
+	payloadValidator := new(gymOwnerUpdateGymPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
 	}
+	gymRequest := utils.GymRequest{
+		NamaGym:    payloadValidator.NamaGym,
+		AlamatGym:  payloadValidator.AlamatGym,
+		Latitude:   payloadValidator.Latitude,
+		Longitude:  payloadValidator.Longitude,
+		LinkGoogle: payloadValidator.LinkGoogle,
+	}
+	response, err := controller.gymOwnerService.UpdateGym(token, requestMeta(c), idGym, gymRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
 
-	payloadValidator := new(payload)
+type generateKodeGymPayload struct {
+	Mode           string `json:"mode" validate:"omitempty,enum=kode_gym_mode"`
+	Plan           string `json:"plan" validate:"omitempty,enum=plan"`
+	MaxRedemptions int    `json:"maxRedemptions" validate:"min=0"`
+	ExpiredDays    int    `json:"expiredDays" validate:"min=0"`
+}
+
+func (controller *GymOwnerController) GenerateKodeGym(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+
+	payloadValidator := new(generateKodeGymPayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
+	}
+
+	kodeGymRequest := utils.KodeGymRequest{
+		IdGym:          idGym,
+		Mode:           payloadValidator.Mode,
+		Plan:           payloadValidator.Plan,
+		MaxRedemptions: payloadValidator.MaxRedemptions,
+		ExpiredDays:    payloadValidator.ExpiredDays,
+	}
+	response, err := controller.gymOwnerService.GenerateKodeGym(token, requestMeta(c), kodeGymRequest)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *GymOwnerController) GetKodeGym(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.gymOwnerService.GetKodeGym(token, idGym)
+	if err != nil {
+		return err
 	}
+	return respond(c, response)
+}
 
-	response := controller.gymOwnerService.GenerateKodeGym(payloadValidator.IdGym)
-	return c.JSON(response.StatusCode, response)
+func (controller *GymOwnerController) RevokeKodeGym(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	idKodeGym, err := uuid.Parse(c.Param("kodeId"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.gymOwnerService.RevokeKodeGym(token, requestMeta(c), idGym, idKodeGym)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *GymOwnerController) GetMembers(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	response, err := controller.gymOwnerService.GetMembers(token, idGym)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *GymOwnerController) GetAdherence(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idGym, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+	days, err := bindAdherenceDays(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.gymOwnerService.GetAdherence(token, idGym, days)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+// bindAdherenceDays reads how many days back adherence covers, 30 unless the
+// days query parameter asks for 1 to 365.
+func bindAdherenceDays(c echo.Context) (int, error) {
+	days := 30
+	if daysParam := c.QueryParam("days"); daysParam != "" {
+		var err error
+		days, err = strconv.Atoi(daysParam)
+		if err != nil || days < 1 || days > 365 {
+			return days, utils.Invalid("validation_failed", utils.Field("days", "between", 1, 365))
+		}
+	}
+	return days, nil
 }
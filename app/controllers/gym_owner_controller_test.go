@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"errors"
+	"kalorize-api/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBindAdherenceDays(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    int
+		wantErr bool
+	}{
+		{query: "", want: 30},
+		{query: "days=1", want: 1},
+		{query: "days=365", want: 365},
+		{query: "days=0", wantErr: true},
+		{query: "days=-7", wantErr: true},
+		{query: "days=366", wantErr: true},
+		{query: "days=week", wantErr: true},
+		{query: "days=7.5", wantErr: true},
+	}
+	e := echo.New()
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/adherence?"+test.query, nil), httptest.NewRecorder())
+			got, err := bindAdherenceDays(c)
+			if !test.wantErr {
+				if err != nil || got != test.want {
+					t.Errorf("bindAdherenceDays = %d, %v, want %d", got, err, test.want)
+				}
+				return
+			}
+			var serviceErr *utils.Error
+			if !errors.As(err, &serviceErr) || len(serviceErr.Fields) != 1 || serviceErr.Fields[0].Field != "days" {
+				t.Errorf("bindAdherenceDays error = %v, want days rejected", err)
+			}
+		})
+	}
+}
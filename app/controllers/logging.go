@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"kalorize-api/app/logging"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RequestID gives every request an ID, taken from its X-Request-ID header
+// when the client or proxy sent one. The ID is echoed in the response and
+// tags the log records of the request.
+var RequestID = middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+	RequestIDHandler: func(c echo.Context, id string) {
+		c.SetRequest(c.Request().WithContext(logging.WithRequestID(c.Request().Context(), id)))
+	},
+})
+
+// AccessLog logs every request once it is answered, as an error when it
+// failed on the server and a warning when the client was at fault.
+var AccessLog = middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+	HandleError:     true,
+	LogLatency:      true,
+	LogMethod:       true,
+	LogURI:          true,
+	LogRoutePath:    true,
+	LogStatus:       true,
+	LogRemoteIP:     true,
+	LogUserAgent:    true,
+	LogResponseSize: true,
+	LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+		level := slog.LevelInfo
+		switch {
+		case v.Status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case v.Status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(c.Request().Context(), level, "request",
+			slog.String("method", v.Method),
+			slog.String("route", v.RoutePath),
+			slog.String("uri", logging.RedactURI(v.URI)),
+			slog.Int("status", v.Status),
+			slog.Float64("latency_ms", float64(v.Latency)/float64(time.Millisecond)),
+			slog.Int64("bytes_out", v.ResponseSize),
+			slog.String("remote_ip", v.RemoteIP),
+			slog.String("user_agent", v.UserAgent),
+		)
+		return nil
+	},
+})
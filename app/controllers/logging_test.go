@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"kalorize-api/app/logging"
+	"kalorize-api/utils"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequestLogging(t *testing.T) {
+	var output bytes.Buffer
+	logger, err := logging.New(&output, "debug", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	e.Use(RequestID, AccessLog)
+	e.GET("/gym/:id", func(c echo.Context) error {
+		switch c.Param("id") {
+		case "missing":
+			return utils.NotFound("gym_not_found")
+		case "broken":
+			return utils.Internal("Failed to get gym", nil)
+		}
+		slog.InfoContext(c.Request().Context(), "handled")
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		path      string
+		requestID string
+		wantLevel string
+	}{
+		{"found", "/gym/1?token=abc", "", "INFO"},
+		{"client error", "/gym/missing", "from-proxy", "WARN"},
+		{"server error", "/gym/broken", "", "ERROR"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output.Reset()
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.requestID != "" {
+				request.Header.Set(echo.HeaderXRequestID, test.requestID)
+			}
+			recorder := httptest.NewRecorder()
+			e.ServeHTTP(recorder, request)
+
+			id := recorder.Header().Get(echo.HeaderXRequestID)
+			if test.requestID != "" && id != test.requestID {
+				t.Errorf("X-Request-ID = %q, want the incoming %q", id, test.requestID)
+			}
+			if id == "" {
+				t.Fatal("the response has no X-Request-ID")
+			}
+
+			var access map[string]interface{}
+			for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+				var record map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("record %s: %v", line, err)
+				}
+				if record["request_id"] != id {
+					t.Errorf("record %s is not tagged with request %s", line, id)
+				}
+				if record["msg"] == "request" {
+					access = record
+				}
+			}
+			if access == nil {
+				t.Fatalf("no access log in %s", output.String())
+			}
+			if access["level"] != test.wantLevel || access["route"] != "/gym/:id" || access["status"] != float64(recorder.Code) {
+				t.Errorf("access log = %v, want %s for route /gym/:id with status %d", access, test.wantLevel, recorder.Code)
+			}
+			if strings.Contains(output.String(), "abc") {
+				t.Errorf("log %s contains the token", output.String())
+			}
+		})
+	}
+
+	t.Run("generated IDs differ", func(t *testing.T) {
+		ids := map[string]bool{}
+		for i := 0; i < 3; i++ {
+			recorder := httptest.NewRecorder()
+			e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/gym/1", nil))
+			ids[recorder.Header().Get(echo.HeaderXRequestID)] = true
+		}
+		if len(ids) != 3 {
+			t.Errorf("IDs = %v, want a new one per request", ids)
+		}
+	})
+}
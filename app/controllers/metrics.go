@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"kalorize-api/app/metrics"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// Metrics records the latency of every request under its route, so paths
+// carrying IDs share one series.
+var Metrics = middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+	HandleError:  true,
+	LogLatency:   true,
+	LogMethod:    true,
+	LogRoutePath: true,
+	LogStatus:    true,
+	LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+		metrics.ObserveRequest(v.Method, v.RoutePath, v.Status, v.Latency)
+		return nil
+	},
+})
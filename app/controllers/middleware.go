@@ -1,2 +1,33 @@
 package controllers
 
+import (
+	"kalorize-api/app/repositories"
+	"kalorize-api/utils"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// RejectDeactivated refuses requests made with the token of a user who has
+// been deactivated since it was issued, so deactivation takes effect before
+// the token expires. Requests without a user token are left to the handlers.
+func RejectDeactivated(db *gorm.DB) echo.MiddlewareFunc {
+	userRepo := repositories.NewDBUserRepository(db)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorizationHeader := c.Request().Header.Get("Authorization")
+			if !strings.HasPrefix(authorizationHeader, "Bearer ") {
+				return next(c)
+			}
+			idUser, err := utils.ParseDataId(strings.TrimPrefix(authorizationHeader, "Bearer "))
+			if err != nil {
+				return next(c)
+			}
+			if user, err := userRepo.GetUserById(idUser); err == nil && !user.Active() {
+				return utils.Forbidden("account_deactivated")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"kalorize-api/utils"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultNearbyRadiusKm = 5
+	maxNearbyRadiusKm     = 50
+	defaultPageLimit      = 20
+	maxPageLimit          = 100
+)
+
+// bindNearbyRequest reads lat, lng, radius (km), page and limit from the
+// query string.
+func bindNearbyRequest(c echo.Context) (utils.NearbyRequest, error) {
+	nearbyRequest := utils.NearbyRequest{
+		RadiusKm: defaultNearbyRadiusKm,
+	}
+	var ok bool
+	if nearbyRequest.Latitude, ok = parseFloatBetween(c.QueryParam("lat"), -90, 90); !ok {
+		return nearbyRequest, invalidQuery("lat", "lat")
+	}
+	if nearbyRequest.Longitude, ok = parseFloatBetween(c.QueryParam("lng"), -180, 180); !ok {
+		return nearbyRequest, invalidQuery("lng", "lng")
+	}
+	if radius := c.QueryParam("radius"); radius != "" {
+		if nearbyRequest.RadiusKm, ok = parseFloatBetween(radius, 0, maxNearbyRadiusKm); !ok || nearbyRequest.RadiusKm == 0 {
+			return nearbyRequest, invalidQuery("radius", "radius", maxNearbyRadiusKm)
+		}
+	}
+	var err error
+	nearbyRequest.Page, nearbyRequest.Limit, err = bindPage(c)
+	return nearbyRequest, err
+}
+
+// parseFloatBetween parses value and reports whether it lies within min and
+// max. ParseFloat accepts "NaN", which fails every comparison, so the range
+// is checked inclusively rather than by rejecting what lies outside it.
+func parseFloatBetween(value string, min float64, max float64) (float64, bool) {
+	number, err := strconv.ParseFloat(value, 64)
+	return number, err == nil && number >= min && number <= max
+}
+
+// bindPage reads page and limit from the query string.
+func bindPage(c echo.Context) (int, int, error) {
+	page, limit := 1, defaultPageLimit
+	var err error
+	if value := c.QueryParam("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			return page, limit, invalidQuery("page", "gt", 0)
+		}
+	}
+	if value := c.QueryParam("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxPageLimit {
+			return page, limit, invalidQuery("limit", "between", 1, maxPageLimit)
+		}
+	}
+	return page, limit, nil
+}
+
+// invalidQuery reports a query parameter that breaks rule.
+func invalidQuery(field string, rule string, params ...interface{}) error {
+	return utils.Invalid("invalid_query", utils.Field(field, rule, params...))
+}
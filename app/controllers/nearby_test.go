@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"errors"
+	"kalorize-api/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBindNearbyRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  utils.NearbyRequest
+		// wantField is the query parameter reported as invalid.
+		wantField string
+	}{
+		{name: "defaults", query: "lat=-6.2&lng=106.8", want: utils.NearbyRequest{Latitude: -6.2, Longitude: 106.8, RadiusKm: 5, Page: 1, Limit: 20}},
+		{name: "radius and page", query: "lat=-6.2&lng=106.8&radius=12.5&page=2&limit=10", want: utils.NearbyRequest{Latitude: -6.2, Longitude: 106.8, RadiusKm: 12.5, Page: 2, Limit: 10}},
+		{name: "largest radius", query: "lat=0&lng=0&radius=50", want: utils.NearbyRequest{RadiusKm: 50, Page: 1, Limit: 20}},
+		{name: "poles and antimeridian", query: "lat=90&lng=-180", want: utils.NearbyRequest{Latitude: 90, Longitude: -180, RadiusKm: 5, Page: 1, Limit: 20}},
+		{name: "other pole and antimeridian", query: "lat=-90&lng=180", want: utils.NearbyRequest{Latitude: -90, Longitude: 180, RadiusKm: 5, Page: 1, Limit: 20}},
+		{name: "missing lat", query: "lng=106.8", wantField: "lat"},
+		{name: "missing lng", query: "lat=-6.2", wantField: "lng"},
+		{name: "lat not a number", query: "lat=south&lng=106.8", wantField: "lat"},
+		{name: "lat above 90", query: "lat=90.01&lng=106.8", wantField: "lat"},
+		{name: "lat below -90", query: "lat=-91&lng=106.8", wantField: "lat"},
+		{name: "lat NaN", query: "lat=NaN&lng=106.8", wantField: "lat"},
+		{name: "lat infinite", query: "lat=-Inf&lng=106.8", wantField: "lat"},
+		{name: "lng above 180", query: "lat=-6.2&lng=180.5", wantField: "lng"},
+		{name: "lng below -180", query: "lat=-6.2&lng=-181", wantField: "lng"},
+		{name: "lng NaN", query: "lat=-6.2&lng=nan", wantField: "lng"},
+		{name: "zero radius", query: "lat=-6.2&lng=106.8&radius=0", wantField: "radius"},
+		{name: "negative radius", query: "lat=-6.2&lng=106.8&radius=-1", wantField: "radius"},
+		{name: "radius above 50", query: "lat=-6.2&lng=106.8&radius=50.1", wantField: "radius"},
+		{name: "radius NaN", query: "lat=-6.2&lng=106.8&radius=NaN", wantField: "radius"},
+		{name: "radius infinite", query: "lat=-6.2&lng=106.8&radius=Inf", wantField: "radius"},
+		{name: "radius not a number", query: "lat=-6.2&lng=106.8&radius=far", wantField: "radius"},
+		{name: "page 0", query: "lat=-6.2&lng=106.8&page=0", wantField: "page"},
+		{name: "limit above 100", query: "lat=-6.2&lng=106.8&limit=101", wantField: "limit"},
+	}
+	e := echo.New()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/gyms/nearby?"+test.query, nil), httptest.NewRecorder())
+			got, err := bindNearbyRequest(c)
+			if test.wantField == "" {
+				if err != nil {
+					t.Fatalf("bindNearbyRequest: %v", err)
+				}
+				if got != test.want {
+					t.Errorf("bindNearbyRequest = %+v, want %+v", got, test.want)
+				}
+				return
+			}
+			var serviceErr *utils.Error
+			if !errors.As(err, &serviceErr) || serviceErr.Code != "invalid_query" || len(serviceErr.Fields) != 1 || serviceErr.Fields[0].Field != test.wantField {
+				t.Errorf("bindNearbyRequest error = %v, want invalid_query for %s", err, test.wantField)
+			}
+		})
+	}
+}
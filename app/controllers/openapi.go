@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"kalorize-api/app/models"
+	"kalorize-api/app/openapi"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The types below describe response data that services build as maps.
+
+type franchiseLoginResponse struct {
+	AccessToken string                    `json:"accessToken"`
+	Franchise   formatter.FranchiseFormat `json:"franchise"`
+}
+
+type bulkUpdateResponse struct {
+	Updated int `json:"updated"`
+}
+
+type gymMemberResponse struct {
+	IdUser    uuid.UUID `json:"idUser"`
+	Fullname  string    `json:"fullname"`
+	Email     string    `json:"email"`
+	Foto      string    `json:"foto"`
+	KodeGym   string    `json:"kodeGym"`
+	ExpiredAt time.Time `json:"expiredAt"`
+}
+
+const (
+	tagAuth          = "Auth"
+	tagUser          = "User"
+	tagQuestionnaire = "Questionnaire"
+	tagMakanan       = "Makanan"
+	tagGym           = "Gym"
+	tagGymOwner      = "Gym owner"
+	tagFranchise     = "Franchise"
+	tagOrder         = "Order"
+	tagDataExport    = "Data export"
+	tagAdmin         = "Admin"
+)
+
+var (
+	pageQuery = []openapi.Parameter{
+		openapi.Query("page", "integer", "Page number, from 1"),
+		openapi.Query("limit", "integer", "Items per page, 1 to 100 (default 20)"),
+	}
+	nearbyQuery = append([]openapi.Parameter{
+		openapi.RequiredQuery("lat", "number", "Latitude, -90 to 90"),
+		openapi.RequiredQuery("lng", "number", "Longitude, -180 to 180"),
+		openapi.Query("radius", "number", "Radius in km, up to 50 (default 5)"),
+	}, pageQuery...)
+	userFilterQuery = append([]openapi.Parameter{
+		openapi.Query("q", "string", "Search in name and email"),
+		openapi.Query("role", "string", "user, admin or gym_owner"),
+		openapi.Query("gym", "string", "Id of the gym of the membership"),
+		openapi.Query("status", "string", "Membership status: active, grace, expired, upcoming or none"),
+		openapi.Query("deactivated", "boolean", "Only deactivated (true) or active (false) accounts"),
+		openapi.Query("deleted", "boolean", "List deleted accounts instead"),
+		openapi.Query("sort", "string", "fullname, email or role"),
+		openapi.Query("order", "string", "asc or desc"),
+	}, pageQuery...)
+	auditLogQuery = append([]openapi.Parameter{
+		openapi.Query("actor", "string", "Id of the user who made the change"),
+		openapi.Query("entity", "string", "Entity type, e.g. gym or user"),
+		openapi.Query("entityId", "string", "Id of the changed entity"),
+		openapi.Query("action", "string", "Action, e.g. gym.update"),
+		openapi.Query("from", "string", "Start, as a date or an RFC 3339 time"),
+		openapi.Query("to", "string", "End, as a date (inclusive) or an RFC 3339 time"),
+	}, pageQuery...)
+)
+
+// EnvelopeV1 describes the utils.Response every version 1 response is
+// wrapped in.
+var EnvelopeV1 = openapi.Envelope{
+	Data: responseSchemaV1,
+	Page: func(items *openapi.Schema) *openapi.Schema {
+		return responseSchemaV1(&openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"items": items,
+				"page":  {Type: "integer"},
+				"limit": {Type: "integer"},
+				"total": {Type: "integer"},
+			},
+			Required: []string{"items", "page", "limit", "total"},
+		})
+	},
+	Error: utils.Response{},
+}
+
+func responseSchemaV1(data *openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"statusCode": {Type: "integer"},
+			"messages":   {Type: "string"},
+			"data":       data,
+		},
+		Required: []string{"statusCode", "messages", "data"},
+	}
+}
+
+// Operations documents every route of the API, keyed by method and path
+// relative to /api/v1. A route without an entry fails the routes tests.
+var Operations = map[string]openapi.Operation{
+	// Auth
+	"POST /login":    {Tag: tagAuth, Summary: "Log in", Public: true, Body: loginPayload{}, Response: formatter.TokenFormat{}},
+	"POST /register": {Tag: tagAuth, Summary: "Register a member with a gym code", Public: true, Body: registerPayload{}, Response: formatter.TokenFormat{}},
+	"POST /refresh":  {Tag: tagAuth, Summary: "Exchange a refresh token for new tokens", Public: true, Body: refreshPayload{}, Response: formatter.TokenFormat{}},
+	"POST /logout":   {Tag: tagAuth, Summary: "Log out and revoke the refresh token"},
+	"GET /user":      {Tag: tagAuth, Summary: "Get the logged in user", Response: formatter.LoggedInUserFormat{}},
+
+	// User
+	"PUT /edit-user":              {Tag: tagUser, Summary: "Edit the profile", Body: editUserPayload{}, Response: formatter.UserFormat{}},
+	"PUT /edit-password":          {Tag: tagUser, Summary: "Change the password", Body: editPasswordPayload{}, Response: formatter.UserFormat{}},
+	"PUT /edit-photo":             {Tag: tagUser, Summary: "Upload a profile photo", Upload: "file", Response: formatter.UserFormat{}},
+	"POST /user/history":          {Tag: tagUser, Summary: "Save today's meals", Body: createHistoryPayload{}, Response: models.History{}},
+	"GET /user/history":           {Tag: tagUser, Summary: "Get the meals of a day", Query: []openapi.Parameter{openapi.RequiredQuery("timestamp", "string", "Day, as 2006-01-02T15:04:05")}, Response: formatter.HistoryFormat{}},
+	"GET /user/membership":        {Tag: tagUser, Summary: "Get the current and past memberships", Response: formatter.MembershipsFormat{}},
+	"POST /user/membership/renew": {Tag: tagUser, Summary: "Renew the membership with a gym code", Body: renewMembershipPayload{}, Response: formatter.MembershipFormat{}},
+	"DELETE /user":                {Tag: tagUser, Summary: "Delete the account after a grace period", Body: deleteAccountPayload{}, Response: formatter.ErasureFormat{}},
+
+	// Questionnaire
+	"PUT /questionnaire": {Tag: tagQuestionnaire, Summary: "Fill in the profile questionnaire", Public: true, Body: fillQuestionnairePayload{}, Response: formatter.UserFormat{}},
+
+	// Makanan
+	"GET /makanan":                       {Tag: tagMakanan, Summary: "List makanan in the requested language", Response: []formatter.MakananFormat{}},
+	"GET /makanan/csv":                   {Tag: tagMakanan, Summary: "Export makanan as CSV in the requested language", Public: true, Produces: "text/csv"},
+	"GET /makanan/:makananId":            {Tag: tagMakanan, Summary: "Get a makanan in the requested language", Response: formatter.MakananFormat{}},
+	"GET /makanan/:makananId/franchises": {Tag: tagMakanan, Summary: "List the franchises that sell a makanan", Response: []formatter.FranchiseFormat{}},
+
+	// Gym
+	"GET /gym":           {Tag: tagGym, Summary: "List gyms", Response: []models.Gym{}},
+	"GET /gym/nearby":    {Tag: tagGym, Summary: "List gyms near a location, nearest first", Query: nearbyQuery, Response: openapi.Page{Item: formatter.NearbyGymFormat{}}},
+	"POST /gym/:id":      {Tag: tagGym, Summary: "Check that a gym code is valid", Public: true, Body: checkGymCodePayload{}},
+	"POST /gym/used/:id": {Tag: tagGym, Summary: "Check whether a gym code has been used", Public: true, Body: isUsedPayload{}},
+
+	// Gym owner
+	"GET /gym-owner/gyms":                          {Tag: tagGymOwner, Summary: "List the owner's gyms", Response: []models.Gym{}},
+	"PUT /gym-owner/gyms/:id":                      {Tag: tagGymOwner, Summary: "Update an owned gym", Body: gymOwnerUpdateGymPayload{}, Response: models.Gym{}},
+	"GET /gym-owner/gyms/:id/codes":                {Tag: tagGymOwner, Summary: "List the codes of an owned gym", Response: []formatter.KodeGymFormat{}},
+	"POST /gym-owner/gyms/:id/codes":               {Tag: tagGymOwner, Summary: "Generate a code for an owned gym", Body: generateKodeGymPayload{}, Response: models.KodeGym{}},
+	"PUT /gym-owner/gyms/:id/codes/:kodeId/revoke": {Tag: tagGymOwner, Summary: "Revoke a code of an owned gym", Response: models.KodeGym{}},
+	"GET /gym-owner/gyms/:id/members":              {Tag: tagGymOwner, Summary: "List the members of an owned gym", Response: []formatter.GymMemberFormat{}},
+	"GET /gym-owner/gyms/:id/adherence":            {Tag: tagGymOwner, Summary: "Report how members follow their calorie targets", Description: "Averages are left out when too few members log meals to stay anonymous.", Query: []openapi.Parameter{openapi.Query("days", "integer", "Days to look back, 1 to 365 (default 30)")}, Response: formatter.GymAdherenceFormat{}},
+
+	// Franchise
+	"GET /franchise":                                    {Tag: tagFranchise, Summary: "List franchises", Public: true, Response: []formatter.FranchiseFormat{}},
+	"POST /franchise/login":                             {Tag: tagFranchise, Summary: "Log in as a franchise operator", Public: true, Body: franchiseLoginPayload{}, Response: franchiseLoginResponse{}},
+	"GET /franchise/nearby":                             {Tag: tagFranchise, Summary: "List franchises near a location, nearest first", Public: true, Query: nearbyQuery, Response: openapi.Page{Item: formatter.NearbyFranchiseFormat{}}},
+	"GET /franchise/:id":                                {Tag: tagFranchise, Summary: "Get a franchise", Public: true, Response: formatter.FranchiseFormat{}},
+	"GET /franchise/:id/menu":                           {Tag: tagFranchise, Summary: "Get the menu of a franchise", Public: true, Response: []formatter.FranchiseMenuFormat{}},
+	"GET /franchise/me/menu":                            {Tag: tagFranchise, Summary: "Get the operator's menu", Response: []formatter.FranchiseMenuFormat{}},
+	"PUT /franchise/me/menu/:makananId":                 {Tag: tagFranchise, Summary: "Update the price or availability of a menu item", Body: updateMenuItemPayload{}, Response: models.FranchiseMakanan{}},
+	"PUT /franchise/me/menu/:makananId/out-of-stock":    {Tag: tagFranchise, Summary: "Mark a menu item out of stock", Body: setOutOfStockPayload{}, Response: models.FranchiseMakanan{}},
+	"DELETE /franchise/me/menu/:makananId/out-of-stock": {Tag: tagFranchise, Summary: "Mark a menu item back in stock", Response: models.FranchiseMakanan{}},
+
+	// Order
+	"GET /order/cart":                     {Tag: tagOrder, Summary: "Get the cart", Response: formatter.CartFormat{}},
+	"PUT /order/cart":                     {Tag: tagOrder, Summary: "Set how many of a makanan are in the cart", Body: setCartItemPayload{}, Response: formatter.CartFormat{}},
+	"DELETE /order/cart":                  {Tag: tagOrder, Summary: "Empty the cart", Response: formatter.CartFormat{}},
+	"POST /order":                         {Tag: tagOrder, Summary: "Order the cart", Body: placeOrderPayload{}, Response: formatter.OrderFormat{}},
+	"GET /order":                          {Tag: tagOrder, Summary: "List the user's orders", Response: []formatter.OrderFormat{}},
+	"GET /order/:id":                      {Tag: tagOrder, Summary: "Get an order", Response: formatter.OrderFormat{}},
+	"PUT /order/:id/cancel":               {Tag: tagOrder, Summary: "Cancel an order", Response: formatter.OrderFormat{}},
+	"GET /franchise/me/orders":            {Tag: tagOrder, Summary: "List the operator's orders", Query: []openapi.Parameter{openapi.Query("status", "string", "Comma separated order statuses")}, Response: []formatter.OrderFormat{}},
+	"PUT /franchise/me/orders/:id/status": {Tag: tagOrder, Summary: "Move an order to its next status", Body: updateFranchiseOrderStatusPayload{}, Response: formatter.OrderFormat{}},
+
+	// Data export
+	"POST /user/export":             {Tag: tagDataExport, Summary: "Request an export of the user's data", Status: 202, Response: models.DataExport{}},
+	"GET /user/export/:id":          {Tag: tagDataExport, Summary: "Get the status of an export", Response: models.DataExport{}},
+	"GET /user/export/:id/download": {Tag: tagDataExport, Summary: "Download a ready export", Produces: "application/zip"},
+
+	// Admin
+	"POST /admin/create-makanan":                                     {Tag: tagAdmin, Summary: "Create a makanan", Body: registerMakananPayload{}, Response: models.Makanan{}},
+	"PUT /admin/update-makanan-photo/:id":                            {Tag: tagAdmin, Summary: "Upload a makanan photo", Upload: "file", Response: models.Makanan{}},
+	"PUT /admin/update-makanan-translation/:id/:locale":              {Tag: tagAdmin, Summary: "Set the translation of a makanan", Body: translateMakananPayload{}, Response: models.MakananTranslation{}},
+	"POST /admin/attach-franchise-makanan":                           {Tag: tagAdmin, Summary: "Add a makanan to a franchise menu", Body: attachFranchiseMakananPayload{}, Response: models.FranchiseMakanan{}},
+	"DELETE /admin/detach-franchise-makanan/:franchiseId/:makananId": {Tag: tagAdmin, Summary: "Remove a makanan from a franchise menu"},
+	"POST /admin/create-gym":                                         {Tag: tagAdmin, Summary: "Create a gym", Body: registerGymPayload{}, Upload: "file", Response: models.Gym{}},
+	"GET /admin/get-all-gym":                                         {Tag: tagAdmin, Summary: "List gyms", Response: []models.Gym{}},
+	"GET /admin/get-gym/:id":                                         {Tag: tagAdmin, Summary: "Get a gym", Response: models.Gym{}},
+	"PUT /admin/update-gym/:id":                                      {Tag: tagAdmin, Summary: "Update a gym", Body: updateGymPayload{}, Response: models.Gym{}},
+	"PUT /admin/update-gym-photo/:id":                                {Tag: tagAdmin, Summary: "Upload a gym photo", Upload: "file", Response: models.Gym{}},
+	"PUT /admin/deactivate-gym/:id":                                  {Tag: tagAdmin, Summary: "Stop a gym from taking new members", Response: models.Gym{}},
+	"PUT /admin/activate-gym/:id":                                    {Tag: tagAdmin, Summary: "Let a gym take new members again", Response: models.Gym{}},
+	"DELETE /admin/delete-gym/:id":                                   {Tag: tagAdmin, Summary: "Delete a gym"},
+	"GET /admin/get-gym-members/:id":                                 {Tag: tagAdmin, Summary: "List the members of a gym", Response: []gymMemberResponse{}},
+	"POST /admin/create-franchise":                                   {Tag: tagAdmin, Summary: "Create a franchise", Body: registerFranchisePayload{}, Response: formatter.FranchiseFormat{}},
+	"GET /admin/get-all-franchise":                                   {Tag: tagAdmin, Summary: "List franchises", Response: []formatter.FranchiseFormat{}},
+	"GET /admin/get-franchise/:id":                                   {Tag: tagAdmin, Summary: "Get a franchise", Response: formatter.FranchiseFormat{}},
+	"PUT /admin/update-franchise/:id":                                {Tag: tagAdmin, Summary: "Update a franchise", Body: updateFranchisePayload{}, Response: formatter.FranchiseFormat{}},
+	"PUT /admin/update-franchise-photo/:id":                          {Tag: tagAdmin, Summary: "Upload a franchise photo", Upload: "file", Response: formatter.FranchiseFormat{}},
+	"DELETE /admin/delete-franchise/:id":                             {Tag: tagAdmin, Summary: "Delete a franchise"},
+	"POST /admin/create-gymcode":                                     {Tag: tagAdmin, Summary: "Generate a gym code", Body: generateGymTokenPayload{}, Response: models.KodeGym{}},
+	"GET /admin/get-all-gymcode":                                     {Tag: tagAdmin, Summary: "List gym codes", Query: []openapi.Parameter{openapi.Query("gym", "string", "Only the codes of this gym")}, Response: []formatter.KodeGymFormat{}},
+	"PUT /admin/revoke-gymcode/:id":                                  {Tag: tagAdmin, Summary: "Revoke a gym code", Response: models.KodeGym{}},
+	"POST /admin/assign-gym-owner":                                   {Tag: tagAdmin, Summary: "Make a user the owner of a gym", Body: assignGymOwnerPayload{}, Response: models.GymOwner{}},
+	"POST /admin/create-user":                                        {Tag: tagAdmin, Summary: "Create a user", Body: registerUserPayload{}, Upload: "file", Response: formatter.UserFormat{}},
+	"GET /admin/get-all-user":                                        {Tag: tagAdmin, Summary: "Search users", Query: userFilterQuery, Response: openapi.Page{Item: formatter.AdminUserFormat{}}},
+	"GET /admin/get-user/:id":                                        {Tag: tagAdmin, Summary: "Get a user", Response: formatter.UserFormat{}},
+	"PUT /admin/update-user/:id":                                     {Tag: tagAdmin, Summary: "Update a user", Body: updateUserPayload{}, Response: formatter.UserFormat{}},
+	"DELETE /admin/delete-user/:id":                                  {Tag: tagAdmin, Summary: "Delete a user, restorable until erased"},
+	"PUT /admin/restore-user/:id":                                    {Tag: tagAdmin, Summary: "Restore a deleted user", Response: formatter.UserFormat{}},
+	"DELETE /admin/erase-user/:id":                                   {Tag: tagAdmin, Summary: "Erase the personal data of a user now"},
+	"POST /admin/bulk-users":                                         {Tag: tagAdmin, Summary: "Apply an action to many users", Description: "extend_membership returns the extended memberships instead of a count.", Body: bulkUpdateUsersPayload{}, Response: bulkUpdateResponse{}},
+	"GET /admin/audit-logs":                                          {Tag: tagAdmin, Summary: "Search the audit log, newest first", Query: auditLogQuery, Response: openapi.Page{Item: models.AuditLog{}}},
+}
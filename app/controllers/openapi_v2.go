@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"kalorize-api/app/openapi"
+	v2 "kalorize-api/formatter/v2"
+)
+
+// EnvelopeV2 describes the bodies of version 2 responses.
+var EnvelopeV2 = openapi.Envelope{
+	Data: func(data *openapi.Schema) *openapi.Schema {
+		return &openapi.Schema{
+			Type:       "object",
+			Properties: map[string]*openapi.Schema{"data": data},
+			Required:   []string{"data"},
+		}
+	},
+	Page: func(items *openapi.Schema) *openapi.Schema {
+		return &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"data": items,
+				"pagination": {
+					Type: "object",
+					Properties: map[string]*openapi.Schema{
+						"page":       {Type: "integer"},
+						"limit":      {Type: "integer"},
+						"total":      {Type: "integer"},
+						"totalPages": {Type: "integer"},
+					},
+					Required: []string{"page", "limit", "total", "totalPages"},
+				},
+			},
+			Required: []string{"data", "pagination"},
+		}
+	},
+	Error: errorBodyV2{},
+}
+
+// OperationsV2 documents every route of version 2, keyed by method and path
+// relative to /api/v2.
+var OperationsV2 = map[string]openapi.Operation{
+	// Auth
+	"POST /auth/login":    {Tag: tagAuth, Summary: "Log in", Public: true, Body: loginPayload{}, Response: v2.Tokens{}},
+	"POST /auth/register": {Tag: tagAuth, Summary: "Register a member with a gym code", Public: true, Body: registerPayload{}, Status: 201, Response: v2.Tokens{}},
+	"POST /auth/refresh":  {Tag: tagAuth, Summary: "Exchange a refresh token for new tokens", Public: true, Body: refreshPayload{}, Response: v2.Tokens{}},
+	"POST /auth/logout":   {Tag: tagAuth, Summary: "Log out and revoke the refresh token", Status: 204},
+
+	// User
+	"GET /me":                      {Tag: tagUser, Summary: "Get the logged in user", Response: v2.Me{}},
+	"PUT /me":                      {Tag: tagUser, Summary: "Edit the profile", Body: editUserPayload{}, Response: v2.User{}},
+	"PUT /me/password":             {Tag: tagUser, Summary: "Change the password", Body: editPasswordPayload{}, Status: 204},
+	"PUT /me/photo":                {Tag: tagUser, Summary: "Upload a profile photo", Upload: "file", Response: v2.User{}},
+	"DELETE /me":                   {Tag: tagUser, Summary: "Delete the account after a grace period", Body: deleteAccountPayload{}, Status: 202, Response: v2.Erasure{}},
+	"POST /me/history":             {Tag: tagUser, Summary: "Save today's meals", Description: "Answers 201 for the first save of the day and 200 when it replaces an earlier one.", Body: createHistoryPayload{}, Status: 201, Response: v2.HistoryEntry{}},
+	"GET /me/history":              {Tag: tagUser, Summary: "Get the meals of a day", Query: []openapi.Parameter{openapi.RequiredQuery("date", "string", "Day, as 2006-01-02")}, Response: v2.History{}},
+	"GET /me/membership":           {Tag: tagUser, Summary: "Get the current and past memberships", Response: v2.Memberships{}},
+	"POST /me/membership/renewals": {Tag: tagUser, Summary: "Renew the membership with a gym code", Body: renewMembershipPayload{}, Status: 201, Response: v2.Membership{}},
+
+	// Data export
+	"POST /me/exports":             {Tag: tagDataExport, Summary: "Request an export of the user's data", Status: 202, Response: v2.DataExport{}},
+	"GET /me/exports/:id":          {Tag: tagDataExport, Summary: "Get the status of an export", Response: v2.DataExport{}},
+	"GET /me/exports/:id/download": {Tag: tagDataExport, Summary: "Download a ready export", Produces: "application/zip"},
+
+	// Makanan
+	"GET /makanan":                       {Tag: tagMakanan, Summary: "List makanan in the requested language", Query: pageQuery, Response: openapi.Page{Item: v2.Makanan{}}},
+	"GET /makanan/:makananId":            {Tag: tagMakanan, Summary: "Get a makanan in the requested language", Response: v2.Makanan{}},
+	"GET /makanan/:makananId/franchises": {Tag: tagMakanan, Summary: "List the franchises that sell a makanan", Query: pageQuery, Response: openapi.Page{Item: v2.Franchise{}}},
+
+	// Gym
+	"GET /gyms":             {Tag: tagGym, Summary: "List gyms", Query: pageQuery, Response: openapi.Page{Item: v2.Gym{}}},
+	"GET /gyms/nearby":      {Tag: tagGym, Summary: "List gyms near a location, nearest first", Query: nearbyQuery, Response: openapi.Page{Item: v2.NearbyGym{}}},
+	"POST /gym-codes/check": {Tag: tagGym, Summary: "Check that a gym code can be used to register", Public: true, Body: checkKodeGymPayload{}, Status: 204},
+
+	// Franchise
+	"GET /franchises":          {Tag: tagFranchise, Summary: "List franchises", Public: true, Query: pageQuery, Response: openapi.Page{Item: v2.Franchise{}}},
+	"GET /franchises/nearby":   {Tag: tagFranchise, Summary: "List franchises near a location, nearest first", Public: true, Query: nearbyQuery, Response: openapi.Page{Item: v2.NearbyFranchise{}}},
+	"GET /franchises/:id":      {Tag: tagFranchise, Summary: "Get a franchise", Public: true, Response: v2.Franchise{}},
+	"GET /franchises/:id/menu": {Tag: tagFranchise, Summary: "Get the menu of a franchise", Public: true, Query: pageQuery, Response: openapi.Page{Item: v2.MenuItem{}}},
+
+	// Order
+	"GET /cart":               {Tag: tagOrder, Summary: "Get the cart", Response: v2.Cart{}},
+	"PUT /cart/items":         {Tag: tagOrder, Summary: "Set how many of a makanan are in the cart", Body: setCartItemPayloadV2{}, Response: v2.Cart{}},
+	"DELETE /cart":            {Tag: tagOrder, Summary: "Empty the cart", Status: 204},
+	"POST /orders":            {Tag: tagOrder, Summary: "Order the cart", Body: placeOrderPayload{}, Status: 201, Response: v2.Order{}},
+	"GET /orders":             {Tag: tagOrder, Summary: "List the user's orders", Query: pageQuery, Response: openapi.Page{Item: v2.Order{}}},
+	"GET /orders/:id":         {Tag: tagOrder, Summary: "Get an order", Response: v2.Order{}},
+	"POST /orders/:id/cancel": {Tag: tagOrder, Summary: "Cancel an order", Response: v2.Order{}},
+}
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"kalorize-api/app/payment"
+	"kalorize-api/app/services"
+	"kalorize-api/utils"
+	"strings"
+
+	vl "github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type OrderController struct {
+	orderService services.OrderService
+	validate     vl.Validate
+}
+
+func NewOrderController(db *gorm.DB, paymentProvider payment.Provider) OrderController {
+	service := services.NewOrderService(db, paymentProvider)
+	controller := OrderController{
+		orderService: service,
+		validate:     *utils.NewValidator(),
+	}
+	return controller
+}
+
+func (controller *OrderController) GetCart(c echo.Context) error {
+	response, err := controller.getCart(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *OrderController) getCart(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	return controller.orderService.GetCart(token)
+}
+
+type setCartItemPayload struct {
+	IdFranchise uuid.UUID `json:"idFranchise" validate:"required"`
+	IdMakanan   string    `json:"idMakanan" validate:"required"`
+	Jumlah      int       `json:"jumlah" validate:"gte=0,lte=20"`
+}
+
+func (controller *OrderController) SetCartItem(c echo.Context) error {
+	response, err := controller.setCartItem(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *OrderController) setCartItem(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	payloadValidator := new(setCartItemPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+	cartItemRequest := utils.CartItemRequest{
+		IdFranchise: payloadValidator.IdFranchise,
+		IdMakanan:   payloadValidator.IdMakanan,
+		Jumlah:      payloadValidator.Jumlah,
+	}
+	return controller.orderService.SetCartItem(token, cartItemRequest)
+}
+
+func (controller *OrderController) ClearCart(c echo.Context) error {
+	response, err := controller.clearCart(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *OrderController) clearCart(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	return controller.orderService.ClearCart(token)
+}
+
+type placeOrderPayload struct {
+	WaktuMakan     string `json:"waktuMakan" validate:"omitempty,enum=waktu_makan"`
+	Catatan        string `json:"catatan" validate:"max=255"`
+	IdempotencyKey string `json:"idempotencyKey" validate:"max=255"`
+}
+
+func (controller *OrderController) PlaceOrder(c echo.Context) error {
+	response, err := controller.placeOrder(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *OrderController) placeOrder(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	payloadValidator := new(placeOrderPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+	orderRequest := utils.OrderRequest{
+		WaktuMakan:     payloadValidator.WaktuMakan,
+		Catatan:        payloadValidator.Catatan,
+		IdempotencyKey: payloadValidator.IdempotencyKey,
+	}
+	return controller.orderService.PlaceOrder(token, orderRequest)
+}
+
+func (controller *OrderController) GetOrders(c echo.Context) error {
+	response, err := controller.getOrders(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *OrderController) getOrders(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	return controller.orderService.GetOrders(token)
+}
+
+func (controller *OrderController) GetOrderById(c echo.Context) error {
+	response, err := controller.getOrderById(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *OrderController) getOrderById(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idOrder, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Response{}, utils.Invalid("invalid_id")
+	}
+	return controller.orderService.GetOrderById(token, idOrder)
+}
+
+func (controller *OrderController) CancelOrder(c echo.Context) error {
+	response, err := controller.cancelOrder(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *OrderController) cancelOrder(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idOrder, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Response{}, utils.Invalid("invalid_id")
+	}
+	return controller.orderService.CancelOrder(token, idOrder)
+}
+
+// GetFranchiseOrders lists the operator's order queue. ?status= takes a comma
+// separated list of statuses and defaults to the orders still in progress.
+func (controller *OrderController) GetFranchiseOrders(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	var statuses []string
+	if status := c.QueryParam("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			s = strings.TrimSpace(s)
+			if err := controller.validate.Var(s, "enum=order_status"); err != nil {
+				return utils.Invalid("validation_failed", utils.Field("status", "oneof", strings.Join(utils.EnumValues("order_status"), ", ")))
+			}
+			statuses = append(statuses, s)
+		}
+	}
+	response, err := controller.orderService.GetFranchiseOrders(token, statuses)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+type updateFranchiseOrderStatusPayload struct {
+	Status string `json:"status" validate:"required,oneof=accepted ready picked_up cancelled"`
+}
+
+func (controller *OrderController) UpdateFranchiseOrderStatus(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	idOrder, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return utils.Invalid("invalid_id")
+	}
+
+	payloadValidator := new(updateFranchiseOrderStatusPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	response, err := controller.orderService.UpdateFranchiseOrderStatus(token, idOrder, payloadValidator.Status)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"kalorize-api/formatter"
+	v2 "kalorize-api/formatter/v2"
+	"kalorize-api/utils"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func (controller *OrderController) GetCartV2(c echo.Context) error {
+	response, err := controller.getCart(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatCart(response.Data.(formatter.CartFormat)))
+}
+
+type setCartItemPayloadV2 struct {
+	FranchiseId uuid.UUID `json:"franchiseId" validate:"required"`
+	MakananId   string    `json:"makananId" validate:"required"`
+	Jumlah      int       `json:"jumlah" validate:"gte=0,lte=20"`
+}
+
+func (controller *OrderController) SetCartItemV2(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+
+	payloadValidator := new(setCartItemPayloadV2)
+	if err := c.Bind(payloadValidator); err != nil {
+		return err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return err
+	}
+	cartItemRequest := utils.CartItemRequest{
+		IdFranchise: payloadValidator.FranchiseId,
+		IdMakanan:   payloadValidator.MakananId,
+		Jumlah:      payloadValidator.Jumlah,
+	}
+	response, err := controller.orderService.SetCartItem(token, cartItemRequest)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatCart(response.Data.(formatter.CartFormat)))
+}
+
+func (controller *OrderController) ClearCartV2(c echo.Context) error {
+	if _, err := controller.clearCart(c); err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusNoContent, nil)
+}
+
+func (controller *OrderController) PlaceOrderV2(c echo.Context) error {
+	response, err := controller.placeOrder(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusCreated, v2.FormatOrder(response.Data.(formatter.OrderFormat)))
+}
+
+func (controller *OrderController) GetOrdersV2(c echo.Context) error {
+	page, limit, err := bindPage(c)
+	if err != nil {
+		return err
+	}
+	response, err := controller.getOrders(c)
+	if err != nil {
+		return err
+	}
+	orders := response.Data.([]formatter.OrderFormat)
+	start, end := utils.PageBounds(len(orders), page, limit)
+	return respondListV2(c, v2.FormatOrders(orders[start:end]), v2.FormatPagination(page, limit, len(orders)))
+}
+
+func (controller *OrderController) GetOrderByIdV2(c echo.Context) error {
+	response, err := controller.getOrderById(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatOrder(response.Data.(formatter.OrderFormat)))
+}
+
+func (controller *OrderController) CancelOrderV2(c echo.Context) error {
+	response, err := controller.cancelOrder(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatOrder(response.Data.(formatter.OrderFormat)))
+}
@@ -19,30 +19,30 @@ func NewQuestionnaireController(db *gorm.DB) QuestionnaireController {
 	service := services.NewQuestionnaireService(db)
 	controller := QuestionnaireController{
 		questionnaireService: service,
-		validate:             *vl.New(),
+		validate:             *utils.NewValidator(),
 	}
 	return controller
 }
 
-func (controller *QuestionnaireController) FillQuestionnaire(c echo.Context) error {
-	type payload struct {
-		IdUser       uuid.UUID `json:"idUser" validate:"required"`
-		Umur         int       `json:"umur"`
-		BeratBadan   int       `json:"beratBadan"`
-		TinggiBadan  int       `json:"tinggiBadan"`
-		JenisKelamin int       `json:"jenisKelamin"`
-		FrekuensiGym int       `json:"frekuensiGym"`
-		TargetKalori int       `json:"targetKalori"`
-	}
+type fillQuestionnairePayload struct {
+	IdUser       uuid.UUID `json:"idUser" validate:"required"`
+	Umur         int       `json:"umur" validate:"umur"`
+	BeratBadan   int       `json:"beratBadan" validate:"berat_badan"`
+	TinggiBadan  int       `json:"tinggiBadan" validate:"tinggi_badan"`
+	JenisKelamin int       `json:"jenisKelamin" validate:"jenis_kelamin"`
+	FrekuensiGym int       `json:"frekuensiGym" validate:"frekuensi_gym"`
+	TargetKalori int       `json:"targetKalori" validate:"target_kalori"`
+}
 
-	payloadValidator := new(payload)
+func (controller *QuestionnaireController) FillQuestionnaire(c echo.Context) error {
+	payloadValidator := new(fillQuestionnairePayload)
 
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return err
 	}
 
 	var questionnairePayload = utils.UserRequest{
@@ -55,6 +55,9 @@ func (controller *QuestionnaireController) FillQuestionnaire(c echo.Context) err
 		TargetKalori: payloadValidator.TargetKalori,
 	}
 
-	response := controller.questionnaireService.FillQuestionnaire(questionnairePayload)
-	return c.JSON(response.StatusCode, response)
+	response, err := controller.questionnaireService.FillQuestionnaire(questionnairePayload)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
 }
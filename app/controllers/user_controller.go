@@ -2,8 +2,8 @@ package controllers
 
 import (
 	"kalorize-api/app/services"
+	"kalorize-api/app/storage"
 	"kalorize-api/utils"
-	"net/http"
 	"strings"
 	"time"
 
@@ -17,34 +17,43 @@ type UserController struct {
 	validate    vl.Validate
 }
 
-func NewUserController(db *gorm.DB) UserController {
-	service := services.NewUserService(db)
+func NewUserController(db *gorm.DB, fileStorage storage.Storage, deletionGrace time.Duration) UserController {
+	service := services.NewUserService(db, fileStorage, deletionGrace)
 	controller := UserController{
 		userService: service,
-		validate:    *vl.New(),
+		validate:    *utils.NewValidator(),
 	}
 	return controller
 }
 
+type editUserPayload struct {
+	NamaUser  string `json:"namaUser" validate:"max=100"`
+	EmailUser string `json:"emailUser" validate:"omitempty,email"`
+	NoTelepon string `json:"noTelepon" validate:"omitempty,phone"`
+}
+
 func (controller *UserController) EditUser(c echo.Context) error {
+	response, err := controller.editUser(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *UserController) editUser(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	type payload struct {
-		NamaUser  string `json:"namaUser"`
-		EmailUser string `json:"emailUser"`
-		NoTelepon string `json:"noTelepon"`
-	}
 
-	payloadValidator := new(payload)
+	payloadValidator := new(editUserPayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 	var editUserPayload utils.UserRequest = utils.UserRequest{
 		Fullname:  payloadValidator.NamaUser,
@@ -52,86 +61,110 @@ func (controller *UserController) EditUser(c echo.Context) error {
 		NoTelepon: payloadValidator.NoTelepon,
 	}
 
-	response := controller.userService.EditUser(token, editUserPayload)
-	return c.JSON(response.StatusCode, response)
+	return controller.userService.EditUser(token, editUserPayload)
+}
+
+type editPasswordPayload struct {
+	OldPassword              string `json:"oldPassword" validate:"required"`
+	NewPassword              string `json:"newPassword" validate:"required"`
+	PasswordConfirmationUser string `json:"passwordConfirmation" validate:"required,eqfield=NewPassword"`
 }
 
 func (controller *UserController) EditPassword(c echo.Context) error {
+	response, err := controller.editPassword(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *UserController) editPassword(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	type payload struct {
-		OldPassword              string `json:"oldPassword" validate:"required"`
-		NewPassword              string `json:"newPassword" validate:"required"`
-		PasswordConfirmationUser string `json:"passwordConfirmation" validate:"required"`
-	}
-	payloadValidator := new(payload)
+	payloadValidator := new(editPasswordPayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 	var editPasswordPayload utils.UserRequest = utils.UserRequest{
 		Password:             payloadValidator.NewPassword,
 		PasswordConfirmation: payloadValidator.PasswordConfirmationUser,
 	}
 
-	response := controller.userService.EditPassword(token, editPasswordPayload, payloadValidator.OldPassword)
-	return c.JSON(response.StatusCode, response)
+	return controller.userService.EditPassword(token, editPasswordPayload, payloadValidator.OldPassword)
 }
 
 func (controller *UserController) EditPhoto(c echo.Context) error {
+	response, err := controller.editPhoto(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *UserController) editPhoto(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
 	// ParseMultipartForm with a maximum of 1024 bytes
 	if err := c.Request().ParseMultipartForm(1024); err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+		return utils.Response{}, utils.Invalid("invalid_form")
 	}
-	alias := c.Request().FormValue("alias")
 	uploadedFile, handler, err := c.Request().FormFile("file")
 	if err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, utils.Invalid("file_required")
+	}
+	if handler.Size > utils.MaxPhotoSize {
+		return utils.Response{}, utils.Invalid("photo_too_large")
 	}
 
 	photoRequest := utils.UploadedPhoto{
-		Alias:   alias,
 		File:    uploadedFile,
 		Handler: handler,
 	}
 
-	response := controller.userService.EditPhoto(token, photoRequest)
-	return c.JSON(response.StatusCode, response)
+	return controller.userService.EditPhoto(token, photoRequest)
+}
+
+type createHistoryPayload struct {
+	BreakfastId   string `json:"breakfastId" validate:"max=36"`
+	LunchId       string `json:"lunchId" validate:"max=36"`
+	DinnerId      string `json:"dinnerId" validate:"max=36"`
+	TotalCalories int    `json:"totalCalories" validate:"nutrisi"`
+	TotalProtein  int    `json:"totalProtein" validate:"nutrisi"`
 }
 
 func (controller *UserController) CreateHistory(c echo.Context) error {
+	response, err := controller.createHistory(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *UserController) createHistory(c echo.Context) (utils.Response, error) {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	type payload struct {
-		BreakfastId   string `json:"breakfastId"`
-		LunchId       string `json:"lunchId"`
-		DinnerId      string `json:"dinnerId"`
-		TotalCalories int    `json:"totalCalories"`
-		TotalProtein  int    `json:"totalProtein"`
-	}
 
-	payloadValidator := new(payload)
+	payloadValidator := new(createHistoryPayload)
 	if err := c.Bind(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 
 	if err := controller.validate.Struct(payloadValidator); err != nil {
-		return c.JSON(400, err.Error())
+		return utils.Response{}, err
 	}
 
 	var historyPayload utils.HistoryRequest = utils.HistoryRequest{
@@ -142,23 +175,98 @@ func (controller *UserController) CreateHistory(c echo.Context) error {
 		TotalProtein: payloadValidator.TotalProtein,
 	}
 
-	response := controller.userService.CreateHistory(token, historyPayload)
-	return c.JSON(response.StatusCode, response)
+	return controller.userService.CreateHistory(token, historyPayload)
 }
 
 func (controller *UserController) GetHistoryBaseDateTime(c echo.Context) error {
 	authorizationHeader := c.Request().Header.Get("Authorization")
 	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
-		return c.JSON(401, "Unauthorized")
+		return utils.Unauthorized("unauthorized")
 	}
 	timestampParam := c.QueryParam("timestamp")
 
 	// Parsing timestampParam menjadi time.Time
 	timestamp, err := time.Parse("2006-01-02T15:04:05", timestampParam)
 	if err != nil {
-		return c.JSON(400, err.Error())
+		return invalidQuery("timestamp", "datetime")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	response, err := controller.userService.GetHistory(token, timestamp)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *UserController) GetMembership(c echo.Context) error {
+	response, err := controller.getMembership(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *UserController) getMembership(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	return controller.userService.GetMembership(token)
+}
+
+type renewMembershipPayload struct {
+	GymKode string `json:"gymKode" validate:"required"`
+}
+
+func (controller *UserController) RenewMembership(c echo.Context) error {
+	response, err := controller.renewMembership(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *UserController) renewMembership(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
-	response := controller.userService.GetHistory(token, timestamp)
-	return c.JSON(response.StatusCode, response)
+	payloadValidator := new(renewMembershipPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+	return controller.userService.RenewMembership(token, payloadValidator.GymKode)
+}
+
+type deleteAccountPayload struct {
+	Password string `json:"password" validate:"required"`
+}
+
+func (controller *UserController) DeleteAccount(c echo.Context) error {
+	response, err := controller.deleteAccount(c)
+	if err != nil {
+		return err
+	}
+	return respond(c, response)
+}
+
+func (controller *UserController) deleteAccount(c echo.Context) (utils.Response, error) {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	payloadValidator := new(deleteAccountPayload)
+	if err := c.Bind(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+	if err := controller.validate.Struct(payloadValidator); err != nil {
+		return utils.Response{}, err
+	}
+	return controller.userService.DeleteAccount(token, payloadValidator.Password)
 }
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"kalorize-api/app/models"
+	"kalorize-api/formatter"
+	v2 "kalorize-api/formatter/v2"
+	"kalorize-api/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (controller *UserController) EditUserV2(c echo.Context) error {
+	response, err := controller.editUser(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatUser(response.Data.(formatter.UserFormat)))
+}
+
+func (controller *UserController) EditPasswordV2(c echo.Context) error {
+	if _, err := controller.editPassword(c); err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusNoContent, nil)
+}
+
+func (controller *UserController) EditPhotoV2(c echo.Context) error {
+	response, err := controller.editPhoto(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatUser(response.Data.(formatter.UserFormat)))
+}
+
+// CreateHistoryV2 answers 201 when the day had no history yet and 200 when
+// it replaced the one saved earlier.
+func (controller *UserController) CreateHistoryV2(c echo.Context) error {
+	response, err := controller.createHistory(c)
+	if err != nil {
+		return err
+	}
+	status := http.StatusOK
+	if response.Messages == "history_created" {
+		status = http.StatusCreated
+	}
+	return respondV2(c, status, v2.FormatHistoryEntry(response.Data.(models.History)))
+}
+
+// GetHistoryV2 returns the meals of the day in ?date=, as 2006-01-02.
+func (controller *UserController) GetHistoryV2(c echo.Context) error {
+	authorizationHeader := c.Request().Header.Get("Authorization")
+	if authorizationHeader == "" || !strings.HasPrefix(authorizationHeader, "Bearer ") {
+		return utils.Unauthorized("unauthorized")
+	}
+	date, err := time.ParseInLocation("2006-01-02", c.QueryParam("date"), time.Local)
+	if err != nil {
+		return invalidQuery("date", "datetime")
+	}
+	token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+	response, err := controller.userService.GetHistory(token, date)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatHistory(response.Data.(formatter.HistoryFormat)))
+}
+
+func (controller *UserController) GetMembershipV2(c echo.Context) error {
+	response, err := controller.getMembership(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusOK, v2.FormatMemberships(response.Data.(formatter.MembershipsFormat)))
+}
+
+func (controller *UserController) RenewMembershipV2(c echo.Context) error {
+	response, err := controller.renewMembership(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusCreated, v2.FormatMembership(response.Data.(formatter.MembershipFormat)))
+}
+
+func (controller *UserController) DeleteAccountV2(c echo.Context) error {
+	response, err := controller.deleteAccount(c)
+	if err != nil {
+		return err
+	}
+	return respondV2(c, http.StatusAccepted, v2.FormatErasure(response.Data.(formatter.ErasureFormat)))
+}
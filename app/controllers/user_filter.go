@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"kalorize-api/utils"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// bindUserFilter reads q, role, gym, status, deactivated, deleted, sort,
+// order, page and limit from the query string.
+func bindUserFilter(c echo.Context) (utils.UserFilter, error) {
+	filter := utils.UserFilter{
+		Search:           c.QueryParam("q"),
+		Role:             c.QueryParam("role"),
+		MembershipStatus: c.QueryParam("status"),
+		Sort:             c.QueryParam("sort"),
+		Order:            c.QueryParam("order"),
+		Page:             1,
+		Limit:            defaultPageLimit,
+	}
+	var err error
+	if gym := c.QueryParam("gym"); gym != "" {
+		idGym, err := uuid.Parse(gym)
+		if err != nil {
+			return filter, invalidQuery("gym", "uuid")
+		}
+		filter.IdGym = &idGym
+	}
+	switch filter.MembershipStatus {
+	case "", "active", "grace", "expired", "upcoming", "none":
+	default:
+		return filter, invalidQuery("status", "oneof", "active, grace, expired, upcoming, none")
+	}
+	if deactivated := c.QueryParam("deactivated"); deactivated != "" {
+		value, err := strconv.ParseBool(deactivated)
+		if err != nil {
+			return filter, invalidQuery("deactivated", "boolean")
+		}
+		filter.Deactivated = &value
+	}
+	if deleted := c.QueryParam("deleted"); deleted != "" {
+		if filter.Deleted, err = strconv.ParseBool(deleted); err != nil {
+			return filter, invalidQuery("deleted", "boolean")
+		}
+	}
+	switch filter.Sort {
+	case "", "fullname", "email", "role":
+	default:
+		return filter, invalidQuery("sort", "oneof", "fullname, email, role")
+	}
+	switch filter.Order {
+	case "", "asc", "desc":
+	default:
+		return filter, invalidQuery("order", "oneof", "asc, desc")
+	}
+	if page := c.QueryParam("page"); page != "" {
+		if filter.Page, err = strconv.Atoi(page); err != nil || filter.Page < 1 {
+			return filter, invalidQuery("page", "gt", 0)
+		}
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 1 || filter.Limit > maxPageLimit {
+			return filter, invalidQuery("limit", "between", 1, maxPageLimit)
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	v2 "kalorize-api/formatter/v2"
+	"kalorize-api/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Version 2 of the API reports the outcome with the status code alone, so
+// its bodies have no statusCode or messages: data is wrapped in
+// {"data": ...}, listings add {"pagination": ...} and errors are reported as
+// {"error": {...}}. Handlers share the request handling of version 1 and
+// format the data the services return with the formatter/v2 DTOs.
+
+const apiVersionKey = "apiVersion"
+
+type dataBodyV2 struct {
+	Data interface{} `json:"data"`
+}
+
+type listBodyV2 struct {
+	Data       interface{}   `json:"data"`
+	Pagination v2.Pagination `json:"pagination"`
+}
+
+type errorBodyV2 struct {
+	Error errorV2 `json:"error"`
+}
+
+type errorV2 struct {
+	Code    string             `json:"code"`
+	Message string             `json:"message"`
+	Fields  []utils.FieldError `json:"fields,omitempty"`
+}
+
+// V2 marks the requests of version 2 of the API, so that errors are written
+// in its format.
+func V2(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Set(apiVersionKey, 2)
+		return next(c)
+	}
+}
+
+func isV2(c echo.Context) bool {
+	version, _ := c.Get(apiVersionKey).(int)
+	return version == 2
+}
+
+// respondV2 writes data with status, or no body at all when data is nil.
+func respondV2(c echo.Context, status int, data interface{}) error {
+	c.Response().Header().Set("Content-Language", requestLocale(c))
+	if data == nil {
+		return c.NoContent(status)
+	}
+	return c.JSON(status, dataBodyV2{Data: data})
+}
+
+// respondListV2 writes one page of a listing.
+func respondListV2(c echo.Context, items interface{}, pagination v2.Pagination) error {
+	c.Response().Header().Set("Content-Language", requestLocale(c))
+	return c.JSON(http.StatusOK, listBodyV2{Data: items, Pagination: pagination})
+}
+
+// respondErrorV2 writes a service error in the language of the request.
+func respondErrorV2(c echo.Context, status int, appErr *utils.Error) error {
+	locale := requestLocale(c)
+	c.Response().Header().Set("Content-Language", locale)
+	return c.JSON(status, errorBodyV2{Error: errorV2{
+		Code:    appErr.Code,
+		Message: utils.T(locale, appErr.Code, appErr.Args...),
+		Fields:  utils.LocalizeFields(locale, appErr.Fields),
+	}})
+}
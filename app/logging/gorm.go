@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const slowQueryThreshold = 200 * time.Millisecond
+
+// Gorm logs the queries of gorm through logger. Failed and slow queries are
+// logged as errors and warnings, every other query at debug level. Records
+// show the statement with its placeholders rather than the values, which can
+// hold password hashes and tokens.
+func Gorm(logger *slog.Logger) gormlogger.Interface {
+	return gormLogger{logger: logger, level: gormlogger.Info}
+}
+
+type gormLogger struct {
+	logger *slog.Logger
+	level  gormlogger.LogLevel
+}
+
+func (l gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	l.level = level
+	return l
+}
+
+func (l gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		l.logger.ErrorContext(ctx, "query failed", "sql", sql, "rows", rows, "duration", elapsed, "error", err)
+	case elapsed > slowQueryThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.logger.WarnContext(ctx, "slow query", "sql", sql, "rows", rows, "duration", elapsed)
+	case l.level >= gormlogger.Info && l.logger.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		l.logger.DebugContext(ctx, "query", "sql", sql, "rows", rows, "duration", elapsed)
+	}
+}
+
+// ParamsFilter keeps the values of a statement out of the logs.
+func (l gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
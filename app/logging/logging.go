@@ -0,0 +1,104 @@
+// Package logging sets up the structured logger of the server. Records are
+// written as JSON (or text in development), carry the ID of the request they
+// belong to and have secrets such as passwords and tokens masked.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+const redactedValue = "******"
+
+// secretKeys are the parts of attribute and query parameter names whose
+// values are never logged.
+var secretKeys = []string{"password", "token", "secret", "authorization", "signature", "cookie"}
+
+type requestIDKey struct{}
+
+// New returns a logger writing to w at level (debug, info, warn or error) in
+// format (json or text).
+func New(w io.Writer, level string, format string) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, err
+	}
+	options := &slog.HandlerOptions{Level: minLevel, ReplaceAttr: redact}
+	var handler slog.Handler
+	if format == "text" {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// WithRequestID returns a copy of ctx whose log records are tagged with id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID of the request ctx belongs to, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RedactURI masks the values of secret query parameters, such as the
+// signature of a signed storage URL.
+func RedactURI(uri string) string {
+	path, rawQuery, ok := strings.Cut(uri, "?")
+	if !ok {
+		return uri
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return path
+	}
+	for key := range query {
+		if isSecret(key) {
+			query[key] = []string{redactedValue}
+		}
+	}
+	return path + "?" + query.Encode()
+}
+
+// contextHandler adds the request ID of the context to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if isSecret(attr.Key) && attr.Value.Kind() != slog.KindGroup {
+		return slog.String(attr.Key, redactedValue)
+	}
+	return attr
+}
+
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var output bytes.Buffer
+	logger, err := New(&output, "info", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.DebugContext(ctx, "hidden below info")
+	logger.InfoContext(ctx, "login",
+		"email", "budi@t.io",
+		"password", "hunter2",
+		"access_token", "eyJ",
+		"Authorization", "Bearer eyJ",
+		slog.Group("config", "jwt_secret", "s3cr3t", "port", 8080),
+	)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("logged %d records, want only the info one: %s", len(lines), output.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("record %s: %v", lines[0], err)
+	}
+	for key, want := range map[string]interface{}{
+		"msg":           "login",
+		"request_id":    "req-1",
+		"email":         "budi@t.io",
+		"password":      redactedValue,
+		"access_token":  redactedValue,
+		"Authorization": redactedValue,
+	} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %v", key, record[key], want)
+		}
+	}
+	config, _ := record["config"].(map[string]interface{})
+	if config["jwt_secret"] != redactedValue || config["port"] != float64(8080) {
+		t.Errorf("config = %v, want the secret masked inside the group", config)
+	}
+	for _, secret := range []string{"hunter2", "eyJ", "s3cr3t"} {
+		if strings.Contains(output.String(), secret) {
+			t.Errorf("log %s contains %q", output.String(), secret)
+		}
+	}
+}
+
+func TestNewText(t *testing.T) {
+	var output bytes.Buffer
+	logger, err := New(&output, "debug", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("signed url", "signature", "abc")
+	if got := output.String(); !strings.Contains(got, "signature="+redactedValue) || strings.Contains(got, "abc") {
+		t.Errorf("text record = %q, want the signature masked", got)
+	}
+	if _, err := New(&output, "loud", "json"); err == nil {
+		t.Error("New accepted the level loud")
+	}
+}
+
+func TestRedactURI(t *testing.T) {
+	// The query is re-encoded, so the mask is percent-encoded and the keys
+	// come back sorted.
+	const masked = "%2A%2A%2A%2A%2A%2A"
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"/api/v1/gym", "/api/v1/gym"},
+		{"/api/v1/gym?page=2", "/api/v1/gym?page=2"},
+		{"/api/v1/storage/images/a.png?signature=abc&expires=1", "/api/v1/storage/images/a.png?expires=1&signature=" + masked},
+		{"/reset?email=a%40b.c&Token=abc", "/reset?Token=" + masked + "&email=a%40b.c"},
+		{"/broken?token=abc;%zz", "/broken"},
+	}
+	for _, test := range tests {
+		t.Run(test.uri, func(t *testing.T) {
+			if got := RedactURI(test.uri); got != test.want {
+				t.Errorf("RedactURI(%q) = %q, want %q", test.uri, got, test.want)
+			}
+		})
+	}
+}
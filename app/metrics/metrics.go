@@ -0,0 +1,98 @@
+// Package metrics holds the Prometheus metrics of the server: request
+// latencies, database pool usage and a few business counters. They are
+// served by the /metrics route.
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "kalorize"
+
+// Accounts that can log in.
+const (
+	AccountUser      = "user"
+	AccountFranchise = "franchise"
+)
+
+// Registry collects every metric of the server, along with the Go runtime
+// and process metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to answer HTTP requests, by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts, by account type and result.",
+	}, []string{"account", "result"})
+
+	historyWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "history_writes_total",
+		Help:      "Saved meal histories, by whether they created or replaced the day's entry.",
+	}, []string{"result"})
+)
+
+var (
+	dbMu        sync.Mutex
+	dbCollector prometheus.Collector
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestDuration,
+		logins,
+		historyWrites,
+	)
+}
+
+// RegisterDB reports the connection pool statistics of db, replacing the
+// database registered before.
+func RegisterDB(db *sql.DB) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	if dbCollector != nil {
+		Registry.Unregister(dbCollector)
+	}
+	dbCollector = collectors.NewDBStatsCollector(db, namespace)
+	Registry.MustRegister(dbCollector)
+}
+
+// ObserveRequest records how long a request to route took to answer.
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	requestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// RecordLogin counts a login attempt of account, failed when err is not nil.
+func RecordLogin(account string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	logins.WithLabelValues(account, result).Inc()
+}
+
+// RecordHistoryWrite counts a saved meal history, created tells whether it
+// was the first of the day.
+func RecordHistoryWrite(created bool) {
+	result := "updated"
+	if created {
+		result = "created"
+	}
+	historyWrites.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrAuditLogAppendOnly is returned when something tries to change or remove
+// an audit log entry.
+var ErrAuditLogAppendOnly = errors.New("audit log is append-only")
+
+// AuditLog records one change made by an admin or a gym owner. Before and
+// After hold the changed fields as JSON: only After for a creation, only
+// Before for a deletion.
+//
+// Entries are append-only only as far as GORM is concerned: the BeforeUpdate
+// and BeforeDelete hooks refuse changes made through the model, but raw SQL
+// and other clients of the database bypass them.
+type AuditLog struct {
+	IdAudit    uuid.UUID `json:"id_audit" gorm:"column:id_audit;primary_key;size:36;"`
+	IdActor    uuid.UUID `json:"id_actor" gorm:"column:id_actor;size:36;index;"`
+	ActorRole  string    `json:"actor_role" gorm:"column:actor_role;type:varchar(20);"`
+	Action     string    `json:"action" gorm:"column:action;type:varchar(50);"`
+	EntityType string    `json:"entity_type" gorm:"column:entity_type;type:varchar(50);index:idx_audit_logs_entity;"`
+	EntityId   string    `json:"entity_id" gorm:"column:entity_id;size:100;index:idx_audit_logs_entity;"`
+	Before     string    `json:"before" gorm:"column:before_value;type:text;"`
+	After      string    `json:"after" gorm:"column:after_value;type:text;"`
+	IpAddress  string    `json:"ip_address" gorm:"column:ip_address;type:varchar(64);"`
+	UserAgent  string    `json:"user_agent" gorm:"column:user_agent;type:varchar(255);"`
+	Method     string    `json:"method" gorm:"column:method;type:varchar(10);"`
+	Path       string    `json:"path" gorm:"column:path;type:varchar(255);"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;index;"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
+
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
@@ -0,0 +1,16 @@
+package models
+
+import "github.com/google/uuid"
+
+// CartItem is a makanan a user is about to order. A cart only ever holds
+// makanan from one franchise.
+type CartItem struct {
+	IdUser      uuid.UUID `json:"id_user" gorm:"column:id_user;primary_key;size:36;"`
+	IdMakanan   string    `json:"id_makanan" gorm:"column:id_makanan;primary_key;size:36;"`
+	IdFranchise uuid.UUID `json:"id_franchise" gorm:"column:id_franchise;size:36;"`
+	Jumlah      int       `json:"jumlah" gorm:"column:jumlah;"`
+}
+
+func (CartItem) TableName() string {
+	return "cart_items"
+}
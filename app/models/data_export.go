@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// DataExport is a ZIP of everything stored about a user, generated in the
+// background and kept in storage under FileKey until ExpiresAt.
+type DataExport struct {
+	IdExport    uuid.UUID  `json:"id_export" gorm:"column:id_export;primary_key;size:36;"`
+	IdUser      uuid.UUID  `json:"id_user" gorm:"column:id_user;size:36;index;"`
+	Status      string     `json:"status" gorm:"column:status;type:varchar(20);"`
+	FileKey     string     `json:"-" gorm:"column:file_key;type:varchar(255);"`
+	Size        int64      `json:"size" gorm:"column:size;"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at;"`
+	CompletedAt *time.Time `json:"completed_at" gorm:"column:completed_at;"`
+	ExpiresAt   *time.Time `json:"expires_at" gorm:"column:expires_at;"`
+}
+
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+// Downloadable reports whether the export is ready and has not expired.
+func (dataExport DataExport) Downloadable(now time.Time) bool {
+	return dataExport.Status == DataExportReady && dataExport.ExpiresAt != nil && now.Before(*dataExport.ExpiresAt)
+}
@@ -7,20 +7,60 @@ import (
 )
 
 type Makanan struct {
-	IdMakanan     string `json:"id" gorm:"column:id;primary_key;type:char(36);"`
+	IdMakanan     string `json:"id" gorm:"column:id;primary_key;size:36;"`
 	Nama          string `json:"nama" gorm:"column:nama;type:varchar(255);"`
 	Foto          string `json:"foto" gorm:"column:foto;type:varchar(255);"`
+	FotoMedium    string `json:"foto_medium" gorm:"column:foto_medium;type:varchar(255);"`
+	FotoThumbnail string `json:"foto_thumbnail" gorm:"column:foto_thumbnail;type:varchar(255);"`
 	Kalori        int    `json:"kalori" gorm:"column:kalori;type:int;"`
 	Protein       int    `json:"protein" gorm:"column:protein;type:int;"`
 	Bahan         string `json:"bahan" gorm:"column:bahan;type:text;"`
 	CookingStep   string `json:"cooking_step" gorm:"column:cooking_step;type:text;"`
-	ListFranchise string `json:"franchise" gorm:"column:franchise;type:text;"`
+
+	Translations []MakananTranslation `json:"-" gorm:"foreignKey:IdMakanan;references:IdMakanan;"`
 }
 
 func (m *Makanan) TableName() string {
 	return "makanans"
 }
 
+// Localized returns the makanan with the name, ingredients and cooking steps
+// of its loaded translation in locale. Untranslated fields, and every field
+// when no translation is loaded, keep the Indonesian text.
+func (m Makanan) Localized(locale string) Makanan {
+	for _, translation := range m.Translations {
+		if translation.Locale != locale {
+			continue
+		}
+		if translation.Nama != "" {
+			m.Nama = translation.Nama
+		}
+		if translation.Bahan != "" {
+			m.Bahan = translation.Bahan
+		}
+		if translation.CookingStep != "" {
+			m.CookingStep = translation.CookingStep
+		}
+	}
+	return m
+}
+
+// MakananTranslation is a makanan in a language other than Indonesian, which
+// is stored on the makanan itself. Bahan and CookingStep are kept in the same
+// comma separated form as on Makanan.
+type MakananTranslation struct {
+	IdMakanan   string    `json:"id_makanan" gorm:"column:id_makanan;primary_key;size:36;"`
+	Locale      string    `json:"locale" gorm:"column:locale;primary_key;size:8;"`
+	Nama        string    `json:"nama" gorm:"column:nama;type:varchar(255);"`
+	Bahan       string    `json:"bahan" gorm:"column:bahan;type:text;"`
+	CookingStep string    `json:"cooking_step" gorm:"column:cooking_step;type:text;"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;"`
+}
+
+func (MakananTranslation) TableName() string {
+	return "makanan_translations"
+}
+
 type TimeWrapper struct {
 	time.Time
 }
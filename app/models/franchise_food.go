@@ -1,13 +1,29 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type FranchiseMakanan struct {
-	IdFranchiseMakanan uuid.UUID `json:"id_franchise_makanan" gorm:"column:id_franchise_makanan;primary_key;auto_increment; type:char(36);"`
-	IdFranchise        uuid.UUID `json:"id_franchise" gorm:"column:id_franchise;type:char(36);"`
-	IdMakanan          string    `json:"id_makanan" gorm:"column:id_makanan;type:char(36);"`
+	IdFranchiseMakanan uuid.UUID  `json:"id_franchise_makanan" gorm:"column:id_franchise_makanan;primary_key;size:36;"`
+	IdFranchise        uuid.UUID  `json:"id_franchise" gorm:"column:id_franchise;size:36;uniqueIndex:idx_franchise_makanan;"`
+	IdMakanan          string     `json:"id_makanan" gorm:"column:id_makanan;size:36;uniqueIndex:idx_franchise_makanan;index;"`
+	Harga              int        `json:"harga" gorm:"column:harga;not null;default:0;"`
+	Tersedia           bool       `json:"tersedia" gorm:"column:tersedia;not null;default:true;"`
+	HabisSampai        *time.Time `json:"habis_sampai" gorm:"column:habis_sampai;"`
 }
 
 func (FranchiseMakanan) TableName() string {
 	return "franchise_makanans"
 }
+
+// Available reports whether the item can be ordered at the given time: it is
+// on sale and not marked out of stock until a later time.
+func (franchiseMakanan FranchiseMakanan) Available(now time.Time) bool {
+	if !franchiseMakanan.Tersedia {
+		return false
+	}
+	return franchiseMakanan.HabisSampai == nil || !franchiseMakanan.HabisSampai.After(now)
+}
@@ -1,20 +1,33 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Gym struct {
-	IdGym      uuid.UUID `json:"id" gorm:"column:id;primary_key;type:char(36);"`
-	NamaGym    string    `json:"nama" gorm:"column:nama;type:varchar(255);"`
-	AlamatGym  string    `json:"alamat" gorm:"column:alamat;type:varchar(255);"`
-	Latitude   float64   `json:"latitude" gorm:"column:latitude;type:double;"`
-	Longitude  float64   `json:"longitude" gorm:"column:longitude;type:double;"`
-	LinkGoogle string    `json:"link_google" gorm:"column:link_google;type:varchar(255);"`
-	PhotoGym   string    `json:"photo_gym" gorm:"column:photo_gym;type:varchar(255);"`
-	PhotoUrl   string    `json:"photo_url" gorm:"column:photo_url;type:varchar(255);"`
+	IdGym             uuid.UUID      `json:"id" gorm:"column:id;primary_key;size:36;"`
+	NamaGym           string         `json:"nama" gorm:"column:nama;type:varchar(255);"`
+	AlamatGym         string         `json:"alamat" gorm:"column:alamat;type:varchar(255);"`
+	Latitude          float64        `json:"latitude" gorm:"column:latitude;index:idx_gyms_location;"`
+	Longitude         float64        `json:"longitude" gorm:"column:longitude;index:idx_gyms_location;"`
+	LinkGoogle        string         `json:"link_google" gorm:"column:link_google;type:varchar(255);"`
+	PhotoGym          string         `json:"photo_gym" gorm:"column:photo_gym;type:varchar(255);"`
+	PhotoUrl          string         `json:"photo_url" gorm:"column:photo_url;type:varchar(255);"`
+	PhotoMediumUrl    string         `json:"photo_medium_url" gorm:"column:photo_medium_url;type:varchar(255);"`
+	PhotoThumbnailUrl string         `json:"photo_thumbnail_url" gorm:"column:photo_thumbnail_url;type:varchar(255);"`
+	DeactivatedAt     *time.Time     `json:"deactivated_at" gorm:"column:deactivated_at;"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"column:deleted_at;index;"`
 }
 
 func (Gym) TableName() string {
 	return "gyms"
 }
+
+// Active reports whether the gym takes new members. The codes of a
+// deactivated gym cannot be redeemed until it is activated again.
+func (gym Gym) Active() bool {
+	return gym.DeactivatedAt == nil
+}
@@ -0,0 +1,14 @@
+package models
+
+import "github.com/google/uuid"
+
+// GymOwner links a user with the gym_owner role to a gym they manage. An
+// owner can manage several gyms and a gym can have several owners.
+type GymOwner struct {
+	IdUser uuid.UUID `json:"id_user" gorm:"column:id_user;primary_key;size:36;"`
+	IdGym  uuid.UUID `json:"id_gym" gorm:"column:id_gym;primary_key;size:36;"`
+}
+
+func (GymOwner) TableName() string {
+	return "gym_owners"
+}
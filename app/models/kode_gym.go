@@ -6,9 +6,46 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	KodeGymSingleUse = "single"
+	KodeGymMultiUse  = "multi"
+
+	KodeGymActive    = "active"
+	KodeGymExpired   = "expired"
+	KodeGymRevoked   = "revoked"
+	KodeGymExhausted = "exhausted"
+	// KodeGymGymInactive is reported for the codes of a gym that is
+	// deactivated or deleted. Status on its own does not know about the gym.
+	KodeGymGymInactive = "gym_inactive"
+)
+
 type KodeGym struct {
-	IdKodeGym   uuid.UUID `json:"id_kode" gorm:"column:id_kode;primary_key;type:int(12);"`
-	KodeGym     string    `json:"kode_gym" gorm:"column:kode_gym;type:varchar(255);"` //misal "bojong56"
-	IdGym       uuid.UUID `json:"id_gym" gorm:"column:id_gym;type:char(36);"`
-	ExpiredTime time.Time `json:"expired_date" gorm:"column:expired_date;type:timestamp;"`
+	IdKodeGym      uuid.UUID  `json:"id_kode" gorm:"column:id_kode;primary_key;size:36;"`
+	KodeGym        string     `json:"kode_gym" gorm:"column:kode_gym;type:varchar(255);uniqueIndex;"` //misal "bojong56"
+	IdGym          uuid.UUID  `json:"id_gym" gorm:"column:id_gym;size:36;index;"`
+	ExpiredTime    time.Time  `json:"expired_date" gorm:"column:expired_date;"`
+	Mode           string     `json:"mode" gorm:"column:mode;type:varchar(10);"`
+	Plan           string     `json:"plan" gorm:"column:plan;type:varchar(20);"`
+	MaxRedemptions int        `json:"max_redemptions" gorm:"column:max_redemptions;type:int;"` // 0 berarti tanpa batas
+	Redemptions    int        `json:"redemptions" gorm:"column:redemptions;type:int;"`
+	RevokedAt      *time.Time `json:"revoked_at" gorm:"column:revoked_at;"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;"`
+}
+
+func (KodeGym) TableName() string {
+	return "kode_gyms"
+}
+
+// Status reports whether the code can still be redeemed at the given time.
+func (kodeGym KodeGym) Status(now time.Time) string {
+	switch {
+	case kodeGym.RevokedAt != nil:
+		return KodeGymRevoked
+	case !kodeGym.ExpiredTime.After(now):
+		return KodeGymExpired
+	case kodeGym.MaxRedemptions > 0 && kodeGym.Redemptions >= kodeGym.MaxRedemptions:
+		return KodeGymExhausted
+	default:
+		return KodeGymActive
+	}
 }
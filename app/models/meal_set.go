@@ -7,9 +7,9 @@ import (
 )
 
 type MealSet struct {
-	IdUser         uuid.UUID `json:"id_user" gorm:"column:id_user;type:char(36);"`
-	IdMakanan      int       `json:"id_makanan" gorm:"column:id_makanan;type:int(36);"`
+	IdUser         uuid.UUID `json:"id_user" gorm:"column:id_user;size:36;"`
+	IdMakanan      int       `json:"id_makanan" gorm:"column:id_makanan;type:int;"`
 	JumlahKalori   int       `json:"jumlah_kalori" gorm:"column:jumlah_kalori;type:int;"`
 	JumlahProtein  int       `json:"jumlah_protein" gorm:"column:jumlah_protein;type:int;"`
-	TanggalMealSet time.Time `json:"tanggal_meal_set" gorm:"column:tanggal_meal_set;type:timestamp;"`
+	TanggalMealSet time.Time `json:"tanggal_meal_set" gorm:"column:tanggal_meal_set;"`
 }
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MembershipMonthly   = "monthly"
+	MembershipQuarterly = "quarterly"
+	MembershipYearly    = "yearly"
+
+	MembershipUpcoming = "upcoming"
+	MembershipActive   = "active"
+	MembershipGrace    = "grace"
+	MembershipExpired  = "expired"
+	// MembershipNone is the status of a user who never joined a gym.
+	MembershipNone = "none"
+
+	// MembershipGracePeriod keeps a lapsed member's access for a while so they
+	// have time to renew.
+	MembershipGracePeriod = 7 * 24 * time.Hour
+)
+
+type Membership struct {
+	IdMembership uuid.UUID `json:"id_membership" gorm:"column:id_membership;primary_key;size:36;"`
+	IdUser       uuid.UUID `json:"id_user" gorm:"column:id_user;size:36;index;"`
+	IdGym        uuid.UUID `json:"id_gym" gorm:"column:id_gym;size:36;"`
+	KodeGym      string    `json:"kode_gym" gorm:"column:kode_gym;type:varchar(255);"`
+	Plan         string    `json:"plan" gorm:"column:plan;type:varchar(20);"`
+	StartDate    time.Time `json:"start_date" gorm:"column:start_date;"`
+	EndDate      time.Time `json:"end_date" gorm:"column:end_date;"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;"`
+}
+
+func (Membership) TableName() string {
+	return "memberships"
+}
+
+func (membership Membership) GraceUntil() time.Time {
+	return membership.EndDate.Add(MembershipGracePeriod)
+}
+
+func (membership Membership) Status(now time.Time) string {
+	switch {
+	case now.Before(membership.StartDate):
+		return MembershipUpcoming
+	case now.Before(membership.EndDate):
+		return MembershipActive
+	case now.Before(membership.GraceUntil()):
+		return MembershipGrace
+	default:
+		return MembershipExpired
+	}
+}
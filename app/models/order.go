@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OrderPlaced    = "placed"
+	OrderAccepted  = "accepted"
+	OrderReady     = "ready"
+	OrderPickedUp  = "picked_up"
+	OrderCancelled = "cancelled"
+)
+
+// orderTransitions lists the statuses an order can move to from each status.
+var orderTransitions = map[string][]string{
+	OrderPlaced:   {OrderAccepted, OrderCancelled},
+	OrderAccepted: {OrderReady, OrderCancelled},
+	OrderReady:    {OrderPickedUp},
+}
+
+type Order struct {
+	IdOrder          uuid.UUID `json:"id_order" gorm:"column:id_order;primary_key;size:36;"`
+	IdUser           uuid.UUID `json:"id_user" gorm:"column:id_user;size:36;index;uniqueIndex:idx_orders_idempotency,priority:1;"`
+	IdFranchise      uuid.UUID `json:"id_franchise" gorm:"column:id_franchise;size:36;index:idx_orders_franchise_status,priority:1;"`
+	Status           string    `json:"status" gorm:"column:status;type:varchar(20);index:idx_orders_franchise_status,priority:2;"`
+	WaktuMakan       string    `json:"waktu_makan" gorm:"column:waktu_makan;type:varchar(20);"`
+	Catatan          string    `json:"catatan" gorm:"column:catatan;type:varchar(255);"`
+	TotalHarga       int       `json:"total_harga" gorm:"column:total_harga;"`
+	TotalKalori      int       `json:"total_kalori" gorm:"column:total_kalori;"`
+	TotalProtein     int       `json:"total_protein" gorm:"column:total_protein;"`
+	PaymentProvider  string    `json:"payment_provider" gorm:"column:payment_provider;type:varchar(20);"`
+	PaymentReference string    `json:"payment_reference" gorm:"column:payment_reference;type:varchar(255);"`
+	IdempotencyKey   *string   `json:"-" gorm:"column:idempotency_key;type:varchar(255);uniqueIndex:idx_orders_idempotency,priority:2;"`
+	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at;"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"column:updated_at;"`
+}
+
+func (Order) TableName() string {
+	return "orders"
+}
+
+// CanTransition reports whether the order may move to the given status.
+func (order Order) CanTransition(status string) bool {
+	for _, next := range orderTransitions[order.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderItem is a makanan on an order, with its name, price and nutrition
+// copied at the time the order was placed.
+type OrderItem struct {
+	IdOrderItem uuid.UUID `json:"id_order_item" gorm:"column:id_order_item;primary_key;size:36;"`
+	IdOrder     uuid.UUID `json:"id_order" gorm:"column:id_order;size:36;index;"`
+	IdMakanan   string    `json:"id_makanan" gorm:"column:id_makanan;size:36;"`
+	NamaMakanan string    `json:"nama_makanan" gorm:"column:nama_makanan;type:varchar(255);"`
+	Jumlah      int       `json:"jumlah" gorm:"column:jumlah;"`
+	Harga       int       `json:"harga" gorm:"column:harga;"`
+	Kalori      int       `json:"kalori" gorm:"column:kalori;"`
+	Protein     int       `json:"protein" gorm:"column:protein;"`
+}
+
+func (OrderItem) TableName() string {
+	return "order_items"
+}
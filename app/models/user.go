@@ -1,28 +1,67 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
 type TargetKalori struct {
 }
 
 type User struct {
-	IdUser       uuid.UUID `json:"id_user" gorm:"column:id_user;primary_key;type:char(36);"`
-	Fullname     string    `json:"fullname" gorm:"column:full_name;type:varchar(255);"`
-	Email        string    `json:"email" gorm:"column:email;type:varchar(255);"`
-	Password     string    `json:"password" gorm:"column:password;type:varchar(255);"`
-	Role         string    `json:"role" gorm:"column:role;type:varchar(20);"`
-	JenisKelamin int       `json:"jenis_kelamin" gorm:"column:jenis_kelamin;type:int(2);"`
-	Umur         int       `json:"umur" gorm:"column:umur;type:int;"`
-	BeratBadan   int       `json:"berat_badan" gorm:"column:berat_badan;type:int;"`
-	TinggiBadan  int       `json:"tinggi_badan" gorm:"column:tinggi_badan;type:int;"`
-	FrekuensiGym int       `json:"frekuensi_gym" gorm:"column:frekuensi_gym;type:int;"`
-	TargetKalori int       `json:"target_kalori" gorm:"column:target_kalori;type:int;"`
-	ReferalCode  string    `json:"referal_code" gorm:"column:referal_code;type:varchar(255);"`
-	Foto         string    `json:"foto" gorm:"column:foto;type:varchar(255);"`
-	FotoUrl      string    `json:"foto_url" gorm:"column:foto_url;type:varchar(255);"`
-	NoTelepon    string    `json:"no_telepon" gorm:"column:no_telepon;type:varchar(255);"`
+	IdUser           uuid.UUID  `json:"id_user" gorm:"column:id_user;primary_key;size:36;"`
+	Fullname         string     `json:"fullname" gorm:"column:full_name;type:varchar(255);"`
+	Email            string     `json:"email" gorm:"column:email;type:varchar(255);"`
+	Password         string     `json:"-" gorm:"column:password;type:varchar(255);"`
+	Role             string     `json:"role" gorm:"column:role;type:varchar(20);"`
+	JenisKelamin     int        `json:"jenis_kelamin" gorm:"column:jenis_kelamin;type:int;"`
+	Umur             int        `json:"umur" gorm:"column:umur;type:int;"`
+	BeratBadan       int        `json:"berat_badan" gorm:"column:berat_badan;type:int;"`
+	TinggiBadan      int        `json:"tinggi_badan" gorm:"column:tinggi_badan;type:int;"`
+	FrekuensiGym     int        `json:"frekuensi_gym" gorm:"column:frekuensi_gym;type:int;"`
+	TargetKalori     int        `json:"target_kalori" gorm:"column:target_kalori;type:int;"`
+	ReferalCode      string     `json:"referal_code" gorm:"column:referal_code;type:varchar(255);"`
+	Foto             string     `json:"foto" gorm:"column:foto;type:varchar(255);"`
+	FotoUrl          string     `json:"foto_url" gorm:"column:foto_url;type:varchar(255);"`
+	FotoMediumUrl    string     `json:"foto_medium_url" gorm:"column:foto_medium_url;type:varchar(255);"`
+	FotoThumbnailUrl string     `json:"foto_thumbnail_url" gorm:"column:foto_thumbnail_url;type:varchar(255);"`
+	NoTelepon        string     `json:"no_telepon" gorm:"column:no_telepon;type:varchar(255);"`
+	DeactivatedAt    *time.Time `json:"deactivated_at" gorm:"column:deactivated_at;"`
+	// ErasureScheduledAt is set when the user deletes their own account. Until
+	// then they can still restore it by logging in; afterwards the erasure
+	// job anonymises it.
+	ErasureScheduledAt *time.Time     `json:"erasure_scheduled_at" gorm:"column:erasure_scheduled_at;index;"`
+	ErasedAt           *time.Time     `json:"erased_at" gorm:"column:erased_at;"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"column:deleted_at;index;"`
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
+
+// Active reports whether the user may sign in. Deactivated users keep their
+// data but cannot log in or refresh their tokens.
+func (u User) Active() bool {
+	return u.DeactivatedAt == nil
+}
+
+// Restorable reports whether a deleted account can still be brought back,
+// which is the case until it has been erased.
+func (u User) Restorable() bool {
+	return u.DeletedAt.Valid && u.ErasedAt == nil
+}
+
+// Anonymised returns a copy of the user stripped of everything that
+// identifies them. The id is kept so gym and franchise records stay intact.
+func (u User) Anonymised(now time.Time) User {
+	return User{
+		IdUser:    u.IdUser,
+		Fullname:  "Deleted user",
+		Email:     "deleted-" + u.IdUser.String() + "@kalorize.invalid",
+		Role:      u.Role,
+		ErasedAt:  &now,
+		DeletedAt: gorm.DeletedAt{Time: now, Valid: true},
+	}
+}
@@ -0,0 +1,309 @@
+// Package openapi builds an OpenAPI 3 document from the routes registered on
+// echo and a description of each of them. Request and response schemas are
+// derived from the Go types of the payloads and DTOs.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Operation describes a route. It is keyed by "METHOD /path", with the path
+// relative to the API group, e.g. "GET /makanan/:makananId".
+type Operation struct {
+	Summary     string
+	Description string
+	Tag         string
+	// Public routes do not need a bearer token.
+	Public bool
+	Query  []Parameter
+	// Body is a value of the JSON request body type, or of the form type
+	// when Upload is set.
+	Body interface{}
+	// Upload names the multipart file field of an upload.
+	Upload string
+	// Status is the status of a successful response, 200 by default.
+	Status int
+	// Response is a value of the type of the data of a successful response,
+	// or nil for responses without data.
+	Response interface{}
+	// Produces is the content type of a successful response that is not
+	// JSON, such as a CSV or ZIP download.
+	Produces string
+	// Deprecated operations have a successor in a later version.
+	Deprecated bool
+}
+
+// Page describes the paginated data of a listing of Item.
+type Page struct {
+	Item interface{}
+}
+
+// API describes one version of the API: where it is mounted and how its
+// responses are wrapped.
+type API struct {
+	Info     Info
+	Prefix   string
+	Envelope Envelope
+}
+
+// Envelope describes the body of the responses of an API version.
+type Envelope struct {
+	// Data wraps the schema of the data of a successful JSON response.
+	Data func(data *Schema) *Schema
+	// Page wraps the schema of the items of a paginated listing.
+	Page func(items *Schema) *Schema
+	// Error is a value of the type of error responses.
+	Error interface{}
+}
+
+// Query describes an optional query parameter of type schemaType.
+func Query(name string, schemaType string, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: schemaType}}
+}
+
+// RequiredQuery describes a query parameter the client must send.
+func RequiredQuery(name string, schemaType string, description string) Parameter {
+	parameter := Query(name, schemaType, description)
+	parameter.Required = true
+	return parameter
+}
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type PathItem struct {
+	Get    *OperationObject `json:"get,omitempty"`
+	Put    *OperationObject `json:"put,omitempty"`
+	Post   *OperationObject `json:"post,omitempty"`
+	Delete *OperationObject `json:"delete,omitempty"`
+	Patch  *OperationObject `json:"patch,omitempty"`
+}
+
+type OperationObject struct {
+	OperationId string                     `json:"operationId,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []Parameter                `json:"parameters,omitempty"`
+	RequestBody *RequestBody               `json:"requestBody,omitempty"`
+	Responses   map[string]ResponseObject  `json:"responses"`
+	Security    []map[string][]interface{} `json:"security,omitempty"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+const bearerAuth = "bearerAuth"
+
+var (
+	pathParam   = regexp.MustCompile(`:(\w+)`)
+	handlerName = regexp.MustCompile(`\(\*(\w+)Controller\)\.(\w+)`)
+)
+
+// Build documents the routes of e under the prefix of api with operations.
+// It also returns the routes that have no operation and the operations that
+// match no route, as "METHOD /path".
+func Build(api API, e *echo.Echo, operations map[string]Operation) (Document, []string, []string) {
+	prefix := api.Prefix
+	document := Document{
+		OpenAPI: "3.0.3",
+		Info:    api.Info,
+		Servers: []Server{{URL: prefix}},
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	schemas := newGenerator(document.Components.Schemas, api.Envelope)
+	errorResponse := ResponseObject{
+		Description: "Error",
+		Content:     map[string]MediaType{echo.MIMEApplicationJSON: {Schema: schemas.schemaOf(api.Envelope.Error)}},
+	}
+
+	var undocumented []string
+	documented := map[string]bool{}
+	for _, route := range e.Routes() {
+		// Group middleware adds catch-all routes that only answer 404.
+		if !strings.HasPrefix(route.Path, prefix+"/") || route.Method == echo.RouteNotFound {
+			continue
+		}
+		path := strings.TrimPrefix(route.Path, prefix)
+		key := route.Method + " " + path
+		operation, ok := operations[key]
+		if !ok {
+			undocumented = append(undocumented, key)
+			continue
+		}
+		documented[key] = true
+
+		object := &OperationObject{
+			OperationId: operationId(route.Name),
+			Summary:     operation.Summary,
+			Description: operation.Description,
+			Responses:   map[string]ResponseObject{"default": errorResponse},
+			Deprecated:  operation.Deprecated,
+		}
+		if operation.Tag != "" {
+			object.Tags = []string{operation.Tag}
+		}
+		if !operation.Public {
+			object.Security = []map[string][]interface{}{{bearerAuth: {}}}
+		}
+		for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+			object.Parameters = append(object.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		if strings.HasSuffix(path, "*") {
+			object.Parameters = append(object.Parameters, Parameter{Name: "path", In: "path", Required: true, Description: "Rest of the path, slashes included", Schema: &Schema{Type: "string"}})
+		}
+		object.Parameters = append(object.Parameters, operation.Query...)
+		if operation.Body != nil || operation.Upload != "" {
+			object.RequestBody = schemas.requestBody(operation)
+		}
+		status := operation.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		object.Responses[strconv.Itoa(status)] = schemas.successResponse(operation)
+
+		openapiPath := pathParam.ReplaceAllString(path, "{$1}")
+		if strings.HasSuffix(openapiPath, "*") {
+			openapiPath = strings.TrimSuffix(openapiPath, "*") + "{path}"
+		}
+		item, ok := document.Paths[openapiPath]
+		if !ok {
+			item = &PathItem{}
+			document.Paths[openapiPath] = item
+		}
+		switch route.Method {
+		case http.MethodGet:
+			item.Get = object
+		case http.MethodPut:
+			item.Put = object
+		case http.MethodPost:
+			item.Post = object
+		case http.MethodDelete:
+			item.Delete = object
+		case http.MethodPatch:
+			item.Patch = object
+		}
+	}
+
+	var unused []string
+	for key := range operations {
+		if !documented[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(undocumented)
+	sort.Strings(unused)
+	return document, undocumented, unused
+}
+
+// operationId names an operation after its controller method, e.g.
+// "adminRegisterMakanan". Handlers that are not controller methods get none.
+func operationId(name string) string {
+	match := handlerName.FindStringSubmatch(name)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(match[1][:1]) + match[1][1:] + match[2]
+}
+
+func (g *generator) requestBody(operation Operation) *RequestBody {
+	if operation.Upload == "" {
+		return &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{echo.MIMEApplicationJSON: {Schema: g.schemaOf(operation.Body)}},
+		}
+	}
+	form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	if operation.Body != nil {
+		form = g.inline(operation.Body)
+	}
+	form.Properties[operation.Upload] = &Schema{Type: "string", Format: "binary"}
+	form.Required = append(form.Required, operation.Upload)
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{echo.MIMEMultipartForm: {Schema: form}},
+	}
+}
+
+func (g *generator) successResponse(operation Operation) ResponseObject {
+	if operation.Status == http.StatusNoContent {
+		return ResponseObject{Description: "No content"}
+	}
+	if operation.Produces != "" {
+		schema := &Schema{Type: "string"}
+		if operation.Produces != echo.MIMETextPlain && !strings.HasPrefix(operation.Produces, "text/") {
+			schema.Format = "binary"
+		}
+		return ResponseObject{Description: "Success", Content: map[string]MediaType{operation.Produces: {Schema: schema}}}
+	}
+	var envelope *Schema
+	if page, ok := operation.Response.(Page); ok {
+		envelope = g.envelope.Page(&Schema{Type: "array", Items: g.schemaOf(page.Item)})
+	} else {
+		data := &Schema{Nullable: true}
+		if operation.Response != nil {
+			data = g.schemaOf(operation.Response)
+		}
+		envelope = g.envelope.Data(data)
+	}
+	return ResponseObject{Description: "Success", Content: map[string]MediaType{echo.MIMEApplicationJSON: {Schema: envelope}}}
+}
@@ -0,0 +1,263 @@
+package openapi
+
+import (
+	"kalorize-api/utils"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Schema is an OpenAPI schema object.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	uuidType      = reflect.TypeOf(uuid.UUID{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+// generator derives schemas from Go types the way encoding/json encodes
+// them. Named structs become components, referenced by package and name.
+type generator struct {
+	components map[string]*Schema
+	envelope   Envelope
+}
+
+func newGenerator(components map[string]*Schema, envelope Envelope) *generator {
+	return &generator{components: components, envelope: envelope}
+}
+
+func (g *generator) schemaOf(value interface{}) *Schema {
+	return g.schema(reflect.TypeOf(value))
+}
+
+// inline returns the schema of a struct value without registering it as a
+// component, so it can be extended.
+func (g *generator) inline(value interface{}) *Schema {
+	return g.object(reflect.TypeOf(value))
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch {
+	case t == timeType || embedsTime(t):
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == deletedAtType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	case t == uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := g.schema(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := componentName(t)
+		if _, ok := g.components[name]; !ok {
+			// Reserve the name first, so recursive types terminate.
+			g.components[name] = &Schema{}
+			*g.components[name] = *g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// object lists the fields of struct t as encoding/json would, with the
+// constraints of their validate tags.
+func (g *generator) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, tagged := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		if field.Anonymous && !tagged {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct && fieldType != timeType {
+				g.addFields(schema, fieldType)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		property := g.schema(fieldType)
+		required := constrain(property, fieldType, field.Tag.Get("validate"))
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// fieldName is the name of field in JSON, or in a form for form payloads.
+// It reports whether the name came from a tag.
+func fieldName(field reflect.StructField) (string, bool) {
+	for _, tag := range []string{"json", "form", "query"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name != "" {
+			return name, true
+		}
+	}
+	return field.Name, false
+}
+
+// constrain adds the rules of a validate tag to schema and reports whether
+// the field is required. Rules after dive apply to the items of a list.
+func constrain(schema *Schema, t reflect.Type, tag string) bool {
+	required := false
+	target, targetType := schema, t
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if expanded, ok := utils.ValidationAlias(name); ok {
+			constrain(target, targetType, expanded)
+			continue
+		}
+		switch name {
+		case "required":
+			if target == schema {
+				required = true
+			}
+		case "dive":
+			if schema.Items == nil {
+				return required
+			}
+			if schema.Items.Ref != "" {
+				// Constraints cannot be added next to a reference.
+				return required
+			}
+			target, targetType = schema.Items, t.Elem()
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "uuid":
+			target.Format = "uuid"
+		case "phone":
+			target.Description = "Indonesian phone number starting with 0, 62 or +62"
+		case "lat":
+			target.Minimum, target.Maximum = float(-90), float(90)
+		case "lng":
+			target.Minimum, target.Maximum = float(-180), float(180)
+		case "enum":
+			target.Enum = utils.EnumValues(param)
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "eqfield":
+			target.Description = "Must match " + strings.ToLower(param[:1]) + param[1:]
+		case "min", "gte", "max", "lte":
+			bound(target, targetType, name == "min" || name == "gte", param)
+		}
+	}
+	return required
+}
+
+// bound sets the lower or upper bound of a number, or the bound of the
+// length of a string or list.
+func bound(schema *Schema, t reflect.Type, lower bool, param string) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			schema.MinLength = integer(value)
+		} else {
+			schema.MaxLength = integer(value)
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if lower {
+			schema.MinItems = integer(value)
+		} else {
+			schema.MaxItems = integer(value)
+		}
+	default:
+		if lower {
+			schema.Minimum = float(value)
+		} else {
+			schema.Maximum = float(value)
+		}
+	}
+}
+
+// embedsTime reports whether t wraps time.Time, such as models.TimeWrapper,
+// and so is encoded as one.
+func embedsTime(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 1 && t.Field(0).Anonymous && t.Field(0).Type == timeType
+}
+
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	return pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name()
+}
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func integer(value float64) *int {
+	n := int(value)
+	return &n
+}
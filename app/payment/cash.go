@@ -0,0 +1,19 @@
+package payment
+
+import "context"
+
+// CashProvider is used when orders are paid at the counter on pickup, so
+// nothing is charged up front and there is nothing to refund.
+type CashProvider struct{}
+
+func (CashProvider) Name() string {
+	return "cash"
+}
+
+func (CashProvider) Charge(ctx context.Context, charge Charge) (string, error) {
+	return "cash-" + charge.IdOrder.String(), nil
+}
+
+func (CashProvider) Refund(ctx context.Context, reference string) error {
+	return nil
+}
@@ -0,0 +1,60 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider keeps charges in memory, for tests and local development.
+// Setting Decline makes every following charge fail with ErrDeclined.
+type FakeProvider struct {
+	mu       sync.Mutex
+	Decline  bool
+	charges  map[string]int
+	refunded map[string]bool
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		charges:  map[string]int{},
+		refunded: map[string]bool{},
+	}
+}
+
+func (fake *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (fake *FakeProvider) Charge(ctx context.Context, charge Charge) (string, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.Decline {
+		return "", ErrDeclined
+	}
+	reference := "fake-" + uuid.NewString()
+	fake.charges[reference] = charge.Amount
+	return reference, nil
+}
+
+func (fake *FakeProvider) Refund(ctx context.Context, reference string) error {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if _, ok := fake.charges[reference]; !ok {
+		return fmt.Errorf("payment: unknown charge %q", reference)
+	}
+	if fake.refunded[reference] {
+		return fmt.Errorf("payment: charge %q already refunded", reference)
+	}
+	fake.refunded[reference] = true
+	return nil
+}
+
+// Refunded reports whether the charge with the given reference was refunded.
+func (fake *FakeProvider) Refunded(reference string) bool {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return fake.refunded[reference]
+}
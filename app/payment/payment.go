@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kalorize-api/config"
+
+	"github.com/google/uuid"
+)
+
+// ErrDeclined is returned by Charge when the provider refuses the payment.
+var ErrDeclined = errors.New("payment: charge declined")
+
+// Charge is a request to take payment for an order. Amount is in rupiah.
+type Charge struct {
+	IdOrder     uuid.UUID
+	IdUser      uuid.UUID
+	Amount      int
+	Description string
+}
+
+// Provider takes payment for orders and refunds them when they are
+// cancelled.
+type Provider interface {
+	// Name identifies the provider on stored orders.
+	Name() string
+	// Charge takes the payment and returns the provider's reference for it.
+	Charge(ctx context.Context, charge Charge) (string, error)
+	// Refund returns a charge made earlier in full.
+	Refund(ctx context.Context, reference string) error
+}
+
+// New builds the provider selected by payment.driver.
+func New(cfg config.PaymentConfig) (Provider, error) {
+	switch cfg.Driver {
+	case "cash":
+		return CashProvider{}, nil
+	case "fake":
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unsupported payment driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"kalorize-api/app/models"
+	"kalorize-api/utils"
+
+	"gorm.io/gorm"
+)
+
+// dbAuditLog only appends and reads; audit entries are never changed.
+type dbAuditLog struct {
+	Conn *gorm.DB
+}
+
+func (db *dbAuditLog) CreateAuditLog(auditLog models.AuditLog) error {
+	return db.Conn.Create(&auditLog).Error
+}
+
+// GetAuditLogsByFilter returns one page of the matching entries, newest
+// first, and the total number of matches.
+func (db *dbAuditLog) GetAuditLogsByFilter(filter utils.AuditLogFilter) ([]models.AuditLog, int64, error) {
+	var auditLogs []models.AuditLog
+	var total int64
+	query := db.Conn.Model(&models.AuditLog{})
+	if filter.IdActor != nil {
+		query = query.Where("id_actor = ?", *filter.IdActor)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityId != "" {
+		query = query.Where("entity_id = ?", filter.EntityId)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at desc").Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).Find(&auditLogs).Error
+	return auditLogs, total, err
+}
+
+type AuditLogRepository interface {
+	CreateAuditLog(auditLog models.AuditLog) error
+	GetAuditLogsByFilter(filter utils.AuditLogFilter) ([]models.AuditLog, int64, error)
+}
+
+func NewDBAuditLogRepository(conn *gorm.DB) *dbAuditLog {
+	return &dbAuditLog{Conn: conn}
+}
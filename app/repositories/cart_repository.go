@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"kalorize-api/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type dbCart struct {
+	Conn *gorm.DB
+}
+
+func (db *dbCart) GetCartByIdUser(idUser uuid.UUID) ([]models.CartItem, error) {
+	var cartItems []models.CartItem
+	err := db.Conn.Where("id_user = ?", idUser).Order("id_makanan").Find(&cartItems).Error
+	return cartItems, err
+}
+
+func (db *dbCart) SaveCartItem(cartItem models.CartItem) error {
+	return db.Conn.Save(&cartItem).Error
+}
+
+func (db *dbCart) DeleteCartItem(idUser uuid.UUID, idMakanan string) error {
+	return db.Conn.Where("id_user = ? AND id_makanan = ?", idUser, idMakanan).Delete(&models.CartItem{}).Error
+}
+
+func (db *dbCart) ClearCart(idUser uuid.UUID) error {
+	return db.Conn.Where("id_user = ?", idUser).Delete(&models.CartItem{}).Error
+}
+
+type CartRepository interface {
+	GetCartByIdUser(idUser uuid.UUID) ([]models.CartItem, error)
+	SaveCartItem(cartItem models.CartItem) error
+	DeleteCartItem(idUser uuid.UUID, idMakanan string) error
+	ClearCart(idUser uuid.UUID) error
+}
+
+func NewDBCartRepository(conn *gorm.DB) *dbCart {
+	return &dbCart{Conn: conn}
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"kalorize-api/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type dbDataExport struct {
+	Conn *gorm.DB
+}
+
+func (db *dbDataExport) CreateDataExport(dataExport models.DataExport) error {
+	return db.Conn.Create(&dataExport).Error
+}
+
+func (db *dbDataExport) UpdateDataExport(dataExport models.DataExport) error {
+	return db.Conn.Save(&dataExport).Error
+}
+
+func (db *dbDataExport) GetDataExportById(id uuid.UUID) (models.DataExport, error) {
+	var dataExport models.DataExport
+	err := db.Conn.Where("id_export = ?", id).First(&dataExport).Error
+	return dataExport, err
+}
+
+// GetDataExportsByIdUser returns the exports of a user, newest first.
+func (db *dbDataExport) GetDataExportsByIdUser(idUser uuid.UUID) ([]models.DataExport, error) {
+	var dataExports []models.DataExport
+	err := db.Conn.Where("id_user = ?", idUser).Order("created_at desc").Find(&dataExports).Error
+	return dataExports, err
+}
+
+// GetExpiredDataExports returns the exports whose download period ended by
+// now, and the failed exports created before failedBefore.
+func (db *dbDataExport) GetExpiredDataExports(now time.Time, failedBefore time.Time) ([]models.DataExport, error) {
+	var dataExports []models.DataExport
+	err := db.Conn.
+		Where("expires_at <= ?", now).
+		Or("status = ? AND created_at <= ?", models.DataExportFailed, failedBefore).
+		Find(&dataExports).Error
+	return dataExports, err
+}
+
+func (db *dbDataExport) DeleteDataExport(id uuid.UUID) error {
+	return db.Conn.Where("id_export = ?", id).Delete(&models.DataExport{}).Error
+}
+
+type DataExportRepository interface {
+	CreateDataExport(dataExport models.DataExport) error
+	UpdateDataExport(dataExport models.DataExport) error
+	GetDataExportById(id uuid.UUID) (models.DataExport, error)
+	GetDataExportsByIdUser(idUser uuid.UUID) ([]models.DataExport, error)
+	GetExpiredDataExports(now time.Time, failedBefore time.Time) ([]models.DataExport, error)
+	DeleteDataExport(id uuid.UUID) error
+}
+
+func NewDBDataExportRepository(conn *gorm.DB) *dbDataExport {
+	return &dbDataExport{Conn: conn}
+}
@@ -4,6 +4,7 @@ import (
 	"kalorize-api/app/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type dbMakanan struct {
@@ -22,14 +23,49 @@ func (db *dbMakanan) GetMakananById(id string) (models.Makanan, error) {
 	return makanan, err
 }
 
+// GetAllMakananWithTranslation loads every makanan with its translation in
+// locale, if there is one.
+func (db *dbMakanan) GetAllMakananWithTranslation(locale string) ([]models.Makanan, error) {
+	var makanans []models.Makanan
+	err := db.Conn.Preload("Translations", "locale = ?", locale).Find(&makanans).Error
+	return makanans, err
+}
+
+func (db *dbMakanan) GetMakananByIdWithTranslation(id string, locale string) (models.Makanan, error) {
+	var makanan models.Makanan
+	err := db.Conn.Preload("Translations", "locale = ?", locale).Where("id = ?", id).First(&makanan).Error
+	return makanan, err
+}
+
+func (db *dbMakanan) GetMakananTranslation(id string, locale string) (models.MakananTranslation, error) {
+	var translation models.MakananTranslation
+	err := db.Conn.Where("id_makanan = ? AND locale = ?", id, locale).First(&translation).Error
+	return translation, err
+}
+
+// SaveMakananTranslation creates the translation or replaces the existing one
+// for the same makanan and locale.
+func (db *dbMakanan) SaveMakananTranslation(translation models.MakananTranslation) error {
+	return db.Conn.Clauses(clause.OnConflict{UpdateAll: true}).Create(&translation).Error
+}
+
 func (db *dbMakanan) CreateMakanan(makanan models.Makanan) error {
 	return db.Conn.Create(&makanan).Error
 }
 
+func (db *dbMakanan) UpdateMakanan(makanan models.Makanan) error {
+	return db.Conn.Save(&makanan).Error
+}
+
 type MakananRepository interface {
 	GetAllMakanan() ([]models.Makanan, error)
 	GetMakananById(id string) (models.Makanan, error)
+	GetAllMakananWithTranslation(locale string) ([]models.Makanan, error)
+	GetMakananByIdWithTranslation(id string, locale string) (models.Makanan, error)
+	GetMakananTranslation(id string, locale string) (models.MakananTranslation, error)
+	SaveMakananTranslation(translation models.MakananTranslation) error
 	CreateMakanan(makanan models.Makanan) error
+	UpdateMakanan(makanan models.Makanan) error
 }
 
 func NewDBMakananRepository(conn *gorm.DB) *dbMakanan {
@@ -3,7 +3,9 @@ package repositories
 import (
 	"kalorize-api/app/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type dbFranchise struct {
@@ -22,28 +24,91 @@ func (db *dbFranchise) GetFranchiseById(id string) (models.Franchise, error) {
 	return franchise, err
 }
 
+func (db *dbFranchise) GetFranchiseByEmail(email string) (models.Franchise, error) {
+	var franchise models.Franchise
+	err := db.Conn.Where("email = ?", email).First(&franchise).Error
+	return franchise, err
+}
+
 func (db *dbFranchise) CreateFranchise(franchise models.Franchise) error {
 	return db.Conn.Create(&franchise).Error
 }
 
-func (db *dbFranchise) DeleteFranchise(franchise models.Franchise) error {
-	return db.Conn.Delete(&franchise).Error
+// DeleteFranchise soft deletes the franchise. Its menu and orders are kept,
+// but it no longer shows up, signs in or takes orders.
+func (db *dbFranchise) DeleteFranchise(idFranchise uuid.UUID) (bool, error) {
+	result := db.Conn.Where("id_franchise = ?", idFranchise).Delete(&models.Franchise{})
+	return result.RowsAffected > 0, result.Error
 }
 
+// AddFranchiseMakanan puts a makanan on a franchise menu. Adding one that is
+// already on the menu does nothing.
 func (db *dbFranchise) AddFranchiseMakanan(franchiseMakanan models.FranchiseMakanan) error {
-	return db.Conn.Create(&franchiseMakanan).Error
+	return db.Conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&franchiseMakanan).Error
+}
+
+func (db *dbFranchise) RemoveFranchiseMakanan(idFranchise uuid.UUID, idMakanan string) (bool, error) {
+	result := db.Conn.Where("id_franchise = ? AND id_makanan = ?", idFranchise, idMakanan).Delete(&models.FranchiseMakanan{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (db *dbFranchise) GetMakananByIdFranchise(idFranchise uuid.UUID) ([]models.Makanan, error) {
+	var makanans []models.Makanan
+	err := db.Conn.Joins("JOIN franchise_makanans ON franchise_makanans.id_makanan = makanans.id").
+		Where("franchise_makanans.id_franchise = ?", idFranchise).Order("makanans.nama").Find(&makanans).Error
+	return makanans, err
+}
+
+func (db *dbFranchise) GetFranchiseMakanan(idFranchise uuid.UUID, idMakanan string) (models.FranchiseMakanan, error) {
+	var franchiseMakanan models.FranchiseMakanan
+	err := db.Conn.Where("id_franchise = ? AND id_makanan = ?", idFranchise, idMakanan).First(&franchiseMakanan).Error
+	return franchiseMakanan, err
+}
+
+func (db *dbFranchise) GetFranchiseMakananByIdFranchise(idFranchise uuid.UUID) ([]models.FranchiseMakanan, error) {
+	var franchiseMakanans []models.FranchiseMakanan
+	err := db.Conn.Where("id_franchise = ?", idFranchise).Find(&franchiseMakanans).Error
+	return franchiseMakanans, err
+}
+
+func (db *dbFranchise) UpdateFranchiseMakanan(franchiseMakanan models.FranchiseMakanan) error {
+	return db.Conn.Save(&franchiseMakanan).Error
+}
+
+func (db *dbFranchise) GetFranchiseByIdMakanan(idMakanan string) ([]models.Franchise, error) {
+	var franchises []models.Franchise
+	err := db.Conn.Joins("JOIN franchise_makanans ON franchise_makanans.id_franchise = franchises.id_franchise").
+		Where("franchise_makanans.id_makanan = ?", idMakanan).Order("franchises.nama_franchise").Find(&franchises).Error
+	return franchises, err
 }
 
 func (db *dbFranchise) UpdateFranchise(franchise models.Franchise) error {
 	return db.Conn.Save(&franchise).Error
 }
 
+func (db *dbFranchise) GetFranchiseByArea(minLat, maxLat, minLon, maxLon float64) ([]models.Franchise, error) {
+	var franchises []models.Franchise
+	// Franchises saved without coordinates hold 0, 0 and are never nearby.
+	err := db.Conn.Where("latitude_franchise BETWEEN ? AND ? AND longitude_franchise BETWEEN ? AND ?", minLat, maxLat, minLon, maxLon).
+		Where("NOT (latitude_franchise = 0 AND longitude_franchise = 0)").Find(&franchises).Error
+	return franchises, err
+}
+
 type FranchiseRepository interface {
 	UpdateFranchise(franchise models.Franchise) error
 	AddFranchiseMakanan(franchiseMakanan models.FranchiseMakanan) error
+	RemoveFranchiseMakanan(idFranchise uuid.UUID, idMakanan string) (bool, error)
+	GetFranchiseMakanan(idFranchise uuid.UUID, idMakanan string) (models.FranchiseMakanan, error)
+	GetFranchiseMakananByIdFranchise(idFranchise uuid.UUID) ([]models.FranchiseMakanan, error)
+	UpdateFranchiseMakanan(franchiseMakanan models.FranchiseMakanan) error
+	GetMakananByIdFranchise(idFranchise uuid.UUID) ([]models.Makanan, error)
+	GetFranchiseByIdMakanan(idMakanan string) ([]models.Franchise, error)
 	GetAllFranchise() ([]models.Franchise, error)
 	GetFranchiseById(id string) (models.Franchise, error)
+	GetFranchiseByEmail(email string) (models.Franchise, error)
 	CreateFranchise(franchise models.Franchise) error
+	DeleteFranchise(idFranchise uuid.UUID) (bool, error)
+	GetFranchiseByArea(minLat, maxLat, minLon, maxLon float64) ([]models.Franchise, error)
 }
 
 func NewDBFranchiseRepository(conn *gorm.DB) *dbFranchise {
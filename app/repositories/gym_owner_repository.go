@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"kalorize-api/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type dbGymOwner struct {
+	Conn *gorm.DB
+}
+
+func (db *dbGymOwner) CreateGymOwner(gymOwner models.GymOwner) error {
+	return db.Conn.Save(&gymOwner).Error
+}
+
+func (db *dbGymOwner) GetGymsByIdUser(idUser uuid.UUID) ([]models.Gym, error) {
+	var gyms []models.Gym
+	err := db.Conn.Joins("JOIN gym_owners ON gym_owners.id_gym = gyms.id").
+		Where("gym_owners.id_user = ?", idUser).Find(&gyms).Error
+	return gyms, err
+}
+
+func (db *dbGymOwner) IsGymOwner(idUser uuid.UUID, idGym uuid.UUID) bool {
+	var gymOwner models.GymOwner
+	err := db.Conn.Where("id_user = ? AND id_gym = ?", idUser, idGym).First(&gymOwner).Error
+	return err == nil
+}
+
+type GymOwnerRepository interface {
+	CreateGymOwner(gymOwner models.GymOwner) error
+	GetGymsByIdUser(idUser uuid.UUID) ([]models.Gym, error)
+	IsGymOwner(idUser uuid.UUID, idGym uuid.UUID) bool
+}
+
+func NewDBGymOwnerRepository(conn *gorm.DB) *dbGymOwner {
+	return &dbGymOwner{Conn: conn}
+}
@@ -17,6 +17,12 @@ func (db *dbGym) GetGym() ([]models.Gym, error) {
 	return gym, err
 }
 
+func (db *dbGym) GetActiveGym() ([]models.Gym, error) {
+	var gym []models.Gym
+	err := db.Conn.Where("deactivated_at IS NULL").Find(&gym).Error
+	return gym, err
+}
+
 func (db *dbGym) GetGymByGymName(gymName string) (models.Gym, error) {
 	var gym models.Gym
 	err := db.Conn.Where("nama LIKE ?", "%"+gymName+"%").First(&gym).Error
@@ -31,8 +37,11 @@ func (db *dbGym) UpdateGym(gym models.Gym) error {
 	return db.Conn.Save(&gym).Error
 }
 
-func (db *dbGym) DeleteGym(idGym uuid.UUID) error {
-	return db.Conn.Delete(&models.Gym{}, idGym).Error
+// DeleteGym soft deletes the gym, so memberships and codes that refer to it
+// keep working as history.
+func (db *dbGym) DeleteGym(idGym uuid.UUID) (bool, error) {
+	result := db.Conn.Where("id = ?", idGym).Delete(&models.Gym{})
+	return result.RowsAffected > 0, result.Error
 }
 
 func (db *dbGym) GetGymById(idGym uuid.UUID) (models.Gym, error) {
@@ -41,13 +50,33 @@ func (db *dbGym) GetGymById(idGym uuid.UUID) (models.Gym, error) {
 	return gym, err
 }
 
+// GetGymByIdIncludingDeleted also finds deleted gyms, for showing the gym
+// of memberships that started before it was deleted.
+func (db *dbGym) GetGymByIdIncludingDeleted(idGym uuid.UUID) (models.Gym, error) {
+	var gym models.Gym
+	err := db.Conn.Unscoped().Where("id = ?", idGym).First(&gym).Error
+	return gym, err
+}
+
+func (db *dbGym) GetGymByArea(minLat, maxLat, minLon, maxLon float64) ([]models.Gym, error) {
+	var gym []models.Gym
+	// Gyms saved without coordinates hold 0, 0 and are never nearby.
+	err := db.Conn.Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", minLat, maxLat, minLon, maxLon).
+		Where("NOT (latitude = 0 AND longitude = 0)").
+		Where("deactivated_at IS NULL").Find(&gym).Error
+	return gym, err
+}
+
 type GymRepository interface {
 	GetGym() ([]models.Gym, error)
+	GetActiveGym() ([]models.Gym, error)
 	CreateNewGym(gym models.Gym) error
 	UpdateGym(gym models.Gym) error
-	DeleteGym(idGym uuid.UUID) error
+	DeleteGym(idGym uuid.UUID) (bool, error)
 	GetGymById(idGym uuid.UUID) (models.Gym, error)
+	GetGymByIdIncludingDeleted(idGym uuid.UUID) (models.Gym, error)
 	GetGymByGymName(gymName string) (models.Gym, error)
+	GetGymByArea(minLat, maxLat, minLon, maxLon float64) ([]models.Gym, error)
 }
 
 func NewDBGymRepository(conn *gorm.DB) *dbGym {
@@ -48,6 +48,19 @@ func (db *dbHistory) GetHistoryByIdUserAndDate(id uuid.UUID, date time.Time) (mo
 	return history, err
 }
 
+func (db *dbHistory) GetHistoryByIdUsersBetween(ids []uuid.UUID, from time.Time, to time.Time) ([]models.History, error) {
+	var histories []models.History
+	err := db.Conn.Where("id_user IN ? AND tanggal_dibuat >= ? AND tanggal_dibuat < ?", ids, from, to).Find(&histories).Error
+	return histories, err
+}
+
+// GetHistoriesByIdUser returns every history of a user, oldest first.
+func (db *dbHistory) GetHistoriesByIdUser(id uuid.UUID) ([]models.History, error) {
+	var histories []models.History
+	err := db.Conn.Where("id_user = ?", id).Order("tanggal_dibuat").Find(&histories).Error
+	return histories, err
+}
+
 type HistoryRepository interface {
 	GetAllHistory() ([]models.History, error)
 	GetHistoryById(id string) (models.History, error)
@@ -56,6 +69,8 @@ type HistoryRepository interface {
 	DeleteHistory(id string) error
 	GetHistoryByIdUser(id uuid.UUID) (models.History, error)
 	GetHistoryByIdUserAndDate(id uuid.UUID, date time.Time) (models.History, error)
+	GetHistoryByIdUsersBetween(ids []uuid.UUID, from time.Time, to time.Time) ([]models.History, error)
+	GetHistoriesByIdUser(id uuid.UUID) ([]models.History, error)
 }
 
 func NewDBHistoryRepository(conn *gorm.DB) *dbHistory {
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"kalorize-api/app/models"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -41,10 +42,34 @@ func (db *dbKodeGym) DeleteKodeGym(idKodeGym uuid.UUID) error {
 
 func (db *dbKodeGym) GetKodeGymById(idKodeGym uuid.UUID) (models.KodeGym, error) {
 	var kodeGym models.KodeGym
-	err := db.Conn.Where("id_kode_gym = ?", idKodeGym).First(&kodeGym).Error
+	err := db.Conn.Where("id_kode = ?", idKodeGym).First(&kodeGym).Error
 	return kodeGym, err
 }
 
+func (db *dbKodeGym) GetAllKodeGym() ([]models.KodeGym, error) {
+	var kodeGyms []models.KodeGym
+	err := db.Conn.Order("created_at desc").Find(&kodeGyms).Error
+	return kodeGyms, err
+}
+
+func (db *dbKodeGym) GetKodeGymByIdGym(idGym uuid.UUID) ([]models.KodeGym, error) {
+	var kodeGyms []models.KodeGym
+	err := db.Conn.Where("id_gym = ?", idGym).Order("created_at desc").Find(&kodeGyms).Error
+	return kodeGyms, err
+}
+
+// RedeemKodeGym counts one redemption of the code. The check and the
+// increment happen in a single UPDATE, so concurrent registrations cannot
+// exceed max_redemptions. It returns false when the code is revoked, expired
+// or used up.
+func (db *dbKodeGym) RedeemKodeGym(idKodeGym uuid.UUID) (bool, error) {
+	result := db.Conn.Model(&models.KodeGym{}).
+		Where("id_kode = ? AND revoked_at IS NULL AND expired_date > ?", idKodeGym, time.Now()).
+		Where("max_redemptions = 0 OR redemptions < max_redemptions").
+		UpdateColumn("redemptions", gorm.Expr("redemptions + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
 type KodeGymRepository interface {
 	GetKodeGymByKode(kode string) (models.KodeGym, error)
 	CreateNewKodeGym(kodeGym models.KodeGym) error
@@ -52,6 +77,9 @@ type KodeGymRepository interface {
 	DeleteKodeGym(idKodeGym uuid.UUID) error
 	GetKodeGymById(idKodeGym uuid.UUID) (models.KodeGym, error)
 	GetIDFromKode(kode string) (uuid.UUID, error)
+	GetAllKodeGym() ([]models.KodeGym, error)
+	GetKodeGymByIdGym(idGym uuid.UUID) ([]models.KodeGym, error)
+	RedeemKodeGym(idKodeGym uuid.UUID) (bool, error)
 }
 
 func NewDBKodeGymRepository(conn *gorm.DB) *dbKodeGym {
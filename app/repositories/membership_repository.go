@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"kalorize-api/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type dbMembership struct {
+	Conn *gorm.DB
+}
+
+func (db *dbMembership) CreateNewMembership(membership models.Membership) error {
+	return db.Conn.Create(&membership).Error
+}
+
+// GetMembershipsByIdUser returns every membership of a user, latest start
+// first.
+func (db *dbMembership) GetMembershipsByIdUser(idUser uuid.UUID) ([]models.Membership, error) {
+	var memberships []models.Membership
+	err := db.Conn.Where("id_user = ?", idUser).Order("start_date desc").Find(&memberships).Error
+	return memberships, err
+}
+
+// GetMembershipsByIdGym returns every membership at a gym, latest start
+// first.
+func (db *dbMembership) GetMembershipsByIdGym(idGym uuid.UUID) ([]models.Membership, error) {
+	var memberships []models.Membership
+	err := db.Conn.Where("id_gym = ?", idGym).Order("start_date desc").Find(&memberships).Error
+	return memberships, err
+}
+
+// GetMembershipsByIdUsers returns the memberships of several users, latest
+// start first.
+func (db *dbMembership) GetMembershipsByIdUsers(idUsers []uuid.UUID) ([]models.Membership, error) {
+	var memberships []models.Membership
+	if len(idUsers) == 0 {
+		return memberships, nil
+	}
+	err := db.Conn.Where("id_user IN ?", idUsers).Order("start_date desc").Find(&memberships).Error
+	return memberships, err
+}
+
+// SaveMemberships updates several memberships in one transaction.
+func (db *dbMembership) SaveMemberships(memberships []models.Membership) error {
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		for i := range memberships {
+			if err := tx.Save(&memberships[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type MembershipRepository interface {
+	CreateNewMembership(membership models.Membership) error
+	GetMembershipsByIdUser(idUser uuid.UUID) ([]models.Membership, error)
+	GetMembershipsByIdGym(idGym uuid.UUID) ([]models.Membership, error)
+	GetMembershipsByIdUsers(idUsers []uuid.UUID) ([]models.Membership, error)
+	SaveMemberships(memberships []models.Membership) error
+}
+
+func NewDBMembershipRepository(conn *gorm.DB) *dbMembership {
+	return &dbMembership{Conn: conn}
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"kalorize-api/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type dbOrder struct {
+	Conn *gorm.DB
+}
+
+// PlaceOrder stores the order with its items and empties the user's cart in
+// one transaction.
+func (db *dbOrder) PlaceOrder(order models.Order, orderItems []models.OrderItem) error {
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&orderItems).Error; err != nil {
+			return err
+		}
+		return tx.Where("id_user = ?", order.IdUser).Delete(&models.CartItem{}).Error
+	})
+}
+
+func (db *dbOrder) GetOrderById(id uuid.UUID) (models.Order, error) {
+	var order models.Order
+	err := db.Conn.Where("id_order = ?", id).First(&order).Error
+	return order, err
+}
+
+// GetOrderByIdempotencyKey returns the order the user placed with key.
+func (db *dbOrder) GetOrderByIdempotencyKey(idUser uuid.UUID, key string) (models.Order, error) {
+	var order models.Order
+	err := db.Conn.Where("id_user = ? AND idempotency_key = ?", idUser, key).First(&order).Error
+	return order, err
+}
+
+func (db *dbOrder) GetOrdersByIdUser(idUser uuid.UUID) ([]models.Order, error) {
+	var orders []models.Order
+	err := db.Conn.Where("id_user = ?", idUser).Order("created_at desc").Find(&orders).Error
+	return orders, err
+}
+
+// GetOrdersByIdFranchise returns the franchise's orders in one of the given
+// statuses, oldest first so they can be worked through as a queue.
+func (db *dbOrder) GetOrdersByIdFranchise(idFranchise uuid.UUID, statuses []string) ([]models.Order, error) {
+	var orders []models.Order
+	err := db.Conn.Where("id_franchise = ? AND status IN ?", idFranchise, statuses).Order("created_at").Find(&orders).Error
+	return orders, err
+}
+
+func (db *dbOrder) GetOrderItemsByIdOrders(idOrders []uuid.UUID) ([]models.OrderItem, error) {
+	var orderItems []models.OrderItem
+	if len(idOrders) == 0 {
+		return orderItems, nil
+	}
+	err := db.Conn.Where("id_order IN ?", idOrders).Order("nama_makanan").Find(&orderItems).Error
+	return orderItems, err
+}
+
+// UpdateOrderStatus moves the order to a new status only if it is still in
+// the expected one, so two concurrent updates cannot both succeed.
+func (db *dbOrder) UpdateOrderStatus(id uuid.UUID, from string, to string) (bool, error) {
+	result := db.Conn.Model(&models.Order{}).Where("id_order = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+type OrderRepository interface {
+	PlaceOrder(order models.Order, orderItems []models.OrderItem) error
+	GetOrderById(id uuid.UUID) (models.Order, error)
+	GetOrderByIdempotencyKey(idUser uuid.UUID, key string) (models.Order, error)
+	GetOrdersByIdUser(idUser uuid.UUID) ([]models.Order, error)
+	GetOrdersByIdFranchise(idFranchise uuid.UUID, statuses []string) ([]models.Order, error)
+	GetOrderItemsByIdOrders(idOrders []uuid.UUID) ([]models.OrderItem, error)
+	UpdateOrderStatus(id uuid.UUID, from string, to string) (bool, error)
+}
+
+func NewDBOrderRepository(conn *gorm.DB) *dbOrder {
+	return &dbOrder{Conn: conn}
+}
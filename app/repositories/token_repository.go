@@ -33,11 +33,18 @@ func (db *DbToken) DeleteToken(idToken string) error {
 	return db.Conn.Delete(&models.Token{}, tokenUUID).Error
 }
 
+func (db *DbToken) GetTokensByUserId(userId uuid.UUID) ([]models.Token, error) {
+	var tokens []models.Token
+	err := db.Conn.Where("user_id = ?", userId).Find(&tokens).Error
+	return tokens, err
+}
+
 type TokenRepository interface {
 	GetToken() ([]models.Token, error)
 	CreateNewToken(token models.Token) error
 	UpdateToken(models.Token) error
 	DeleteToken(idToken string) error
+	GetTokensByUserId(userId uuid.UUID) ([]models.Token, error)
 }
 
 func NewDBTokenRepository(conn *gorm.DB) *DbToken {
@@ -43,10 +43,16 @@ func (db *UsedCode) GetusedCodeByIdUser(idUser uuid.UUID) (models.UsedCode, erro
 
 func (db *UsedCode) GetUsedCodeByGymCode(gymCode string) (models.UsedCode, error) {
 	var usedCode models.UsedCode
-	err := db.Conn.Where(" gym_code = ?", gymCode).First(&usedCode).Error
+	err := db.Conn.Where(" id_kode = ?", gymCode).First(&usedCode).Error
 	return usedCode, err
 }
 
+func (db *UsedCode) GetUsedCodesByIdGym(idGym uuid.UUID) ([]models.UsedCode, error) {
+	var usedCodes []models.UsedCode
+	err := db.Conn.Where("id_gym = ?", idGym).Order("expired_at desc").Find(&usedCodes).Error
+	return usedCodes, err
+}
+
 type UsedCodeRepository interface {
 	GetUsedCode() ([]models.UsedCode, error)
 	CreateNewUsedCode(useCode models.UsedCode) error
@@ -55,6 +61,7 @@ type UsedCodeRepository interface {
 	GetUsedCodeByIdCode(idUsedCode uuid.UUID) (models.UsedCode, error)
 	GetusedCodeByIdUser(idUser uuid.UUID) (models.UsedCode, error)
 	GetUsedCodeByGymCode(gymCode string) (models.UsedCode, error)
+	GetUsedCodesByIdGym(idGym uuid.UUID) ([]models.UsedCode, error)
 }
 
 func NewDBUsedCodeRepository(conn *gorm.DB) *UsedCode {
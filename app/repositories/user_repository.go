@@ -2,6 +2,9 @@ package repositories
 
 import (
 	"kalorize-api/app/models"
+	"kalorize-api/utils"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -57,22 +60,248 @@ func (db *dbUser) GetAllUser() ([]models.User, error) {
 	return users, err
 }
 
-func (db *dbUser) DeleteUser(id uuid.UUID) error {
+// DeleteUser soft deletes a user, which blocks their login, and signs them out
+// everywhere. It reports whether the user existed.
+func (db *dbUser) DeleteUser(id uuid.UUID) (bool, error) {
+	return db.deleteUser(id, nil)
+}
+
+// ScheduleErasure soft deletes a user who asked for their account to be
+// removed. The account can be restored until erasureAt, after which the
+// erasure job anonymises it.
+func (db *dbUser) ScheduleErasure(id uuid.UUID, erasureAt time.Time) (bool, error) {
+	return db.deleteUser(id, &erasureAt)
+}
+
+func (db *dbUser) deleteUser(id uuid.UUID, erasureAt *time.Time) (bool, error) {
+	var deleted bool
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id_user = ?", id).Update("erasure_scheduled_at", erasureAt).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id_user = ?", id).Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected > 0
+		return tx.Where("user_id = ?", id).Delete(&models.Token{}).Error
+	})
+	return deleted, err
+}
+
+// RestoreUser brings back a soft deleted user and cancels any scheduled
+// erasure.
+func (db *dbUser) RestoreUser(id uuid.UUID) error {
+	return db.Conn.Unscoped().Model(&models.User{}).Where("id_user = ? AND erased_at IS NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "erasure_scheduled_at": nil}).Error
+}
+
+// GetUserByIdIncludingDeleted also finds soft deleted users.
+func (db *dbUser) GetUserByIdIncludingDeleted(id uuid.UUID) (models.User, error) {
+	var user models.User
+	err := db.Conn.Unscoped().Where("id_user = ?", id).First(&user).Error
+	return user, err
+}
+
+// GetUserByEmailIncludingDeleted also finds soft deleted users, whose email
+// stays taken until they are erased.
+func (db *dbUser) GetUserByEmailIncludingDeleted(email string) (models.User, error) {
 	var user models.User
-	err := db.Conn.Where("id_user = ?", id).Delete(&user).Error
-	return err
+	err := db.Conn.Unscoped().Where("email = ?", email).First(&user).Error
+	return user, err
+}
+
+// GetUsersDueForErasure returns the deleted users whose grace period ended
+// before now and who have not been erased yet.
+func (db *dbUser) GetUsersDueForErasure(now time.Time) ([]models.User, error) {
+	var users []models.User
+	err := db.Conn.Unscoped().Where("erasure_scheduled_at <= ? AND erased_at IS NULL", now).Find(&users).Error
+	return users, err
+}
+
+// IsFotoShared reports whether anything other than the user idUser uses the
+// photo. Photos are stored under their content hash, so the same image
+// uploaded for another user, a makanan or a franchise shares the files.
+// Deleted rows count, as they may still be restored.
+func (db *dbUser) IsFotoShared(foto string, idUser uuid.UUID) bool {
+	hash, _, _ := strings.Cut(foto, "_")
+	prefix := likeEscaper.Replace(hash + "_")
+	var count int64
+	db.Conn.Unscoped().Model(&models.User{}).Where("foto LIKE ? ESCAPE '!' AND id_user <> ?", prefix+"%", idUser).Count(&count)
+	if count > 0 {
+		return true
+	}
+	// Makanan and franchise photos are stored as the URLs of their keys.
+	url := "%images/" + prefix + "%"
+	db.Conn.Model(&models.Makanan{}).Where("foto LIKE ? ESCAPE '!'", url).Count(&count)
+	if count > 0 {
+		return true
+	}
+	db.Conn.Unscoped().Model(&models.Franchise{}).Where("foto LIKE ? ESCAPE '!'", url).Count(&count)
+	return count > 0
+}
+
+// EraseUser replaces a user with its anonymised copy and removes everything
+// personal that refers to them: tokens, history, meal sets, cart, gym
+// ownership, data exports and order notes. Memberships, used codes and orders are kept for
+// the gyms and franchises and now point at the anonymous user.
+func (db *dbUser) EraseUser(anonymised models.User) error {
+	id := anonymised.IdUser
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.History{}, &models.MealSet{}, &models.CartItem{}, &models.GymOwner{}, &models.DataExport{}} {
+			if err := tx.Where("id_user = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.Token{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Order{}).Where("id_user = ?", id).Update("catatan", "").Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.User{}).Where("id_user = ?", id).Select("*").Updates(&anonymised).Error
+	})
+}
+
+func (db *dbUser) GetUsersByIds(ids []uuid.UUID) ([]models.User, error) {
+	var users []models.User
+	err := db.Conn.Where("id_user IN ?", ids).Order("full_name").Find(&users).Error
+	return users, err
+}
+
+// userSortColumns maps the sort keys accepted by the admin listing to columns.
+var userSortColumns = map[string]string{
+	"fullname": "full_name",
+	"email":    "email",
+	"role":     "role",
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern with '!', which
+// unlike a backslash means the same on every supported database.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// startedMembership selects the membership that decides the status of a user
+// who has one that started: the latest to start by the time bound to both ?.
+const startedMembership = `SELECT 1 FROM memberships started WHERE started.id_user = users.id_user AND started.start_date <= ?
+	AND NOT EXISTS (SELECT 1 FROM memberships later WHERE later.id_user = started.id_user
+		AND later.start_date <= ? AND later.start_date > started.start_date)`
+
+// membershipStatusCondition returns the condition selecting the users whose
+// membership status is status at now, as models.Membership.Status decides it.
+func membershipStatusCondition(status string, now time.Time) (string, []interface{}) {
+	graceStart := now.Add(-models.MembershipGracePeriod)
+	switch status {
+	case models.MembershipActive:
+		return "EXISTS (" + startedMembership + " AND started.end_date > ?)", []interface{}{now, now, now}
+	case models.MembershipGrace:
+		return "EXISTS (" + startedMembership + " AND started.end_date <= ? AND started.end_date > ?)", []interface{}{now, now, now, graceStart}
+	case models.MembershipExpired:
+		return "EXISTS (" + startedMembership + " AND started.end_date <= ?)", []interface{}{now, now, graceStart}
+	case models.MembershipUpcoming:
+		return `EXISTS (SELECT 1 FROM memberships WHERE memberships.id_user = users.id_user)
+			AND NOT EXISTS (SELECT 1 FROM memberships WHERE memberships.id_user = users.id_user AND memberships.start_date <= ?)`, []interface{}{now}
+	default:
+		return "NOT EXISTS (SELECT 1 FROM memberships WHERE memberships.id_user = users.id_user)", nil
+	}
+}
+
+// GetUsersByFilter returns a page of the users matching the filter, sorted,
+// and the total number of matches. Deleted users are only listed, on their
+// own, when filter.Deleted is set. Membership statuses are worked out as of
+// now.
+func (db *dbUser) GetUsersByFilter(filter utils.UserFilter, now time.Time) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+	query := db.Conn.Model(&models.User{})
+	if filter.Deleted {
+		query = db.Conn.Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL")
+	}
+	if filter.Search != "" {
+		search := "%" + likeEscaper.Replace(strings.ToLower(filter.Search)) + "%"
+		query = query.Where("LOWER(full_name) LIKE ? ESCAPE '!' OR LOWER(email) LIKE ? ESCAPE '!'", search, search)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.IdGym != nil {
+		query = query.Where("id_user IN (?) OR id_user IN (?)",
+			db.Conn.Model(&models.Membership{}).Select("id_user").Where("id_gym = ?", *filter.IdGym),
+			db.Conn.Model(&models.UsedCode{}).Select("id_user").Where("id_gym = ?", *filter.IdGym))
+	}
+	if filter.Deactivated != nil {
+		if *filter.Deactivated {
+			query = query.Where("deactivated_at IS NOT NULL")
+		} else {
+			query = query.Where("deactivated_at IS NULL")
+		}
+	}
+	if filter.MembershipStatus != "" {
+		condition, args := membershipStatusCondition(filter.MembershipStatus, now)
+		query = query.Where(condition, args...)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	column, ok := userSortColumns[filter.Sort]
+	if !ok {
+		column = "full_name"
+	}
+	if filter.Order == "desc" {
+		column += " desc"
+	}
+	err := query.Order(column).Order("id_user").Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).Find(&users).Error
+	return users, total, err
+}
+
+// DeactivateUsers deactivates every user in ids and deletes their tokens
+// inside one transaction. If any of them does not exist nothing is changed
+// and gorm.ErrRecordNotFound is returned.
+func (db *dbUser) DeactivateUsers(ids []uuid.UUID, deactivatedAt time.Time) error {
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := NewDBUserRepository(tx).UpdateUsers(ids, map[string]interface{}{"deactivated_at": deactivatedAt}); err != nil {
+			return err
+		}
+		return tx.Where("user_id IN ?", ids).Delete(&models.Token{}).Error
+	})
+}
+
+// UpdateUsers applies the same changes to every user in ids inside one
+// transaction. If any of them does not exist nothing is changed and
+// gorm.ErrRecordNotFound is returned.
+func (db *dbUser) UpdateUsers(ids []uuid.UUID, changes map[string]interface{}) error {
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("id_user IN ?", ids).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(ids)) {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.User{}).Where("id_user IN ?", ids).Updates(changes).Error
+	})
 }
 
 type UserRepository interface {
 	GetToken() string
 	GetAllUser() ([]models.User, error)
 	CreateNewUser(user models.User) error
-	DeleteUser(id uuid.UUID) error
+	DeleteUser(id uuid.UUID) (bool, error)
+	ScheduleErasure(id uuid.UUID, erasureAt time.Time) (bool, error)
+	RestoreUser(id uuid.UUID) error
+	GetUserByIdIncludingDeleted(id uuid.UUID) (models.User, error)
+	GetUserByEmailIncludingDeleted(email string) (models.User, error)
+	GetUsersDueForErasure(now time.Time) ([]models.User, error)
+	IsFotoShared(foto string, idUser uuid.UUID) bool
+	EraseUser(anonymised models.User) error
 	GetUserByUsername(username string) (models.User, error)
 	GetUserByEmail(email string) (models.User, error)
 	FindReferalCodeIfExist(code string) bool
 	UpdateUser(user models.User) error
 	GetUserById(id uuid.UUID) (models.User, error)
+	GetUsersByIds(ids []uuid.UUID) ([]models.User, error)
+	GetUsersByFilter(filter utils.UserFilter, now time.Time) ([]models.User, int64, error)
+	UpdateUsers(ids []uuid.UUID, changes map[string]interface{}) error
+	DeactivateUsers(ids []uuid.UUID, deactivatedAt time.Time) error
 }
 
 func NewDBUserRepository(conn *gorm.DB) *dbUser {
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"kalorize-api/app/models"
+	"kalorize-api/config"
+	"kalorize-api/utils"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestGetUsersByFilter(t *testing.T) {
+	db := newTestDB(t)
+	userRepo := NewDBUserRepository(db)
+	membershipRepo := NewDBMembershipRepository(db)
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, n) }
+
+	// Each user is named after the membership status they have at now.
+	users := []struct {
+		name        string
+		email       string
+		memberships [][2]time.Time
+	}{
+		{"active", "active@t.io", [][2]time.Time{{days(-10), days(20)}}},
+		{"renewed", "renewed_user@t.io", [][2]time.Time{{days(-40), days(-10)}, {days(-10), days(20)}, {days(20), days(50)}}},
+		{"grace", "grace@t.io", [][2]time.Time{{days(-33), days(-3)}}},
+		{"expired", "expired%@t.io", [][2]time.Time{{days(-60), days(-30)}}},
+		{"lapsed before renewal", "lapsed@t.io", [][2]time.Time{{days(-60), days(-30)}, {days(5), days(35)}}},
+		{"upcoming", "upcoming@t.io", [][2]time.Time{{days(5), days(35)}}},
+		{"none", "none@t.io", nil},
+	}
+	for i, user := range users {
+		idUser := uuid.New()
+		err := userRepo.CreateNewUser(models.User{IdUser: idUser, Fullname: string(rune('a'+i)) + " " + user.name, Email: user.email, Role: "user"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, dates := range user.memberships {
+			err := membershipRepo.CreateNewMembership(models.Membership{IdMembership: uuid.New(), IdUser: idUser, StartDate: dates[0], EndDate: dates[1]})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name      string
+		filter    utils.UserFilter
+		want      []string
+		wantTotal int64
+	}{
+		{"active", utils.UserFilter{MembershipStatus: models.MembershipActive}, []string{"active", "renewed"}, 2},
+		{"grace", utils.UserFilter{MembershipStatus: models.MembershipGrace}, []string{"grace"}, 1},
+		{"expired", utils.UserFilter{MembershipStatus: models.MembershipExpired}, []string{"expired", "lapsed before renewal"}, 2},
+		{"upcoming", utils.UserFilter{MembershipStatus: models.MembershipUpcoming}, []string{"upcoming"}, 1},
+		{"none", utils.UserFilter{MembershipStatus: models.MembershipNone}, []string{"none"}, 1},
+		{"first page", utils.UserFilter{Limit: 3}, []string{"active", "renewed", "grace"}, 7},
+		{"last page", utils.UserFilter{Page: 3, Limit: 3}, []string{"none"}, 7},
+		{"past the last page", utils.UserFilter{Page: 4, Limit: 3}, nil, 7},
+		{"sorted descending", utils.UserFilter{Order: "desc", Limit: 2}, []string{"none", "upcoming"}, 7},
+		{"page of a status", utils.UserFilter{MembershipStatus: models.MembershipActive, Page: 2, Limit: 1}, []string{"renewed"}, 2},
+		{"search", utils.UserFilter{Search: "GRACE"}, []string{"grace"}, 1},
+		{"search for a percent sign", utils.UserFilter{Search: "%"}, []string{"expired"}, 1},
+		{"search for an underscore", utils.UserFilter{Search: "_"}, []string{"renewed"}, 1},
+		{"search for an escape character", utils.UserFilter{Search: "!"}, nil, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.filter.Page == 0 {
+				test.filter.Page = 1
+			}
+			if test.filter.Limit == 0 {
+				test.filter.Limit = 20
+			}
+			found, total, err := userRepo.GetUsersByFilter(test.filter, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, user := range found {
+				got = append(got, user.Fullname[2:])
+			}
+			if !reflect.DeepEqual(got, test.want) || total != test.wantTotal {
+				t.Errorf("GetUsersByFilter = %q of %d, want %q of %d", got, total, test.want, test.wantTotal)
+			}
+		})
+	}
+}
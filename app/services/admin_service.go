@@ -1,59 +1,58 @@
 package services
 
 import (
-	"context"
-	"fmt"
-	"io"
+	"errors"
 	"kalorize-api/app/models"
 	"kalorize-api/app/repositories"
+	"kalorize-api/app/storage"
+	"kalorize-api/formatter"
 	"kalorize-api/utils"
-	"path/filepath"
 	"strings"
 	"time"
 
-	"cloud.google.com/go/storage"
-	firebase "firebase.google.com/go"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
-	"google.golang.org/api/option"
 	"gorm.io/gorm"
 )
 
 type adminService struct {
-	gymRepo       repositories.GymRepository
-	userRepo      repositories.UserRepository
-	gymKode       repositories.KodeGymRepository
-	gymUsedCode   repositories.UsedCodeRepository
-	makananRepo   repositories.MakananRepository
-	franchiseRepo repositories.FranchiseRepository
+	gymRepo        repositories.GymRepository
+	userRepo       repositories.UserRepository
+	gymKode        repositories.KodeGymRepository
+	gymUsedCode    repositories.UsedCodeRepository
+	makananRepo    repositories.MakananRepository
+	franchiseRepo  repositories.FranchiseRepository
+	gymOwnerRepo   repositories.GymOwnerRepository
+	membershipRepo repositories.MembershipRepository
+	dataExportRepo repositories.DataExportRepository
+	auditRepo      repositories.AuditLogRepository
+	fileStorage    storage.Storage
 }
 
-func NewAdminService(db *gorm.DB) AdminService {
+func NewAdminService(db *gorm.DB, fileStorage storage.Storage) AdminService {
 	return &adminService{
-		userRepo:      repositories.NewDBUserRepository(db),
-		gymRepo:       repositories.NewDBGymRepository(db),
-		gymKode:       repositories.NewDBKodeGymRepository(db),
-		gymUsedCode:   repositories.NewDBUsedCodeRepository(db),
-		makananRepo:   repositories.NewDBMakananRepository(db),
-		franchiseRepo: repositories.NewDBFranchiseRepository(db),
+		userRepo:       repositories.NewDBUserRepository(db),
+		gymRepo:        repositories.NewDBGymRepository(db),
+		gymKode:        repositories.NewDBKodeGymRepository(db),
+		gymUsedCode:    repositories.NewDBUsedCodeRepository(db),
+		makananRepo:    repositories.NewDBMakananRepository(db),
+		franchiseRepo:  repositories.NewDBFranchiseRepository(db),
+		gymOwnerRepo:   repositories.NewDBGymOwnerRepository(db),
+		membershipRepo: repositories.NewDBMembershipRepository(db),
+		dataExportRepo: repositories.NewDBDataExportRepository(db),
+		auditRepo:      repositories.NewDBAuditLogRepository(db),
+		fileStorage:    fileStorage,
 	}
 }
 
-func (service *adminService) RegisterGym(token string, registGymRequest utils.GymRequest, photoRequest utils.UploadedPhoto) utils.Response {
-	var response utils.Response
-	adminEmail, err := utils.ParseDataEmail(token)
-	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+func (service *adminService) RegisterGym(token string, requestMeta utils.RequestMeta, registGymRequest utils.GymRequest, photoRequest utils.UploadedPhoto) (utils.Response, error) {
+	if err := utils.Validate(registGymRequest); err != nil {
+		return utils.Response{}, err
 	}
-	admin, err := service.userRepo.GetUserByEmail(adminEmail)
-	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+	var response utils.Response
+	admin, err := service.admin(token)
+	if err != nil {
+		return utils.Response{}, err
 	}
 
 	gym := models.Gym{
@@ -65,98 +64,42 @@ func (service *adminService) RegisterGym(token string, registGymRequest utils.Gy
 		LinkGoogle: registGymRequest.LinkGoogle,
 	}
 
-	// Initialize Firebase app
-	opt := option.WithCredentialsFile("config/credentials.json")
-	app, err := firebase.NewApp(context.Background(), nil, opt)
+	filename, photoUrls, err := uploadPhoto(service.fileStorage, photoRequest)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to initialize Firebase app"
-		response.Data = nil
-		return response
-	}
-
-	// Initialize Firebase Storage client
-	client, err := app.Storage(context.Background())
-	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to initialize Firebase Storage client"
-		response.Data = nil
-		return response
-	}
-
-	// Specify the path within the bucket where the file should be stored
-	storagePath := fmt.Sprintf("images/%s", photoRequest.Handler.Filename)
-
-	// Open a new reader for the file
-	reader := photoRequest.File
-
-	// Get the bucket handle from the client
-	bucket, err := client.Bucket("kalorize-71324.appspot.com")
-	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to get bucket handle from the client"
-		response.Data = nil
-		return response
-	}
-
-	// Initialize the writer for the file
-	wc := bucket.Object(storagePath).NewWriter(context.Background())
-	wc.ACL = []storage.ACLRule{{Entity: storage.AllUsers, Role: storage.RoleReader}}
-
-	// Upload the file to Firebase Storage
-	if _, err := io.Copy(wc, reader); err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to upload file to Firebase Storage"
-		response.Data = nil
-		return response
-	}
-
-	// Close the writer after copying
-	if err := wc.Close(); err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to close Firebase Storage writer"
-		response.Data = nil
-		return response
+		return utils.Response{}, photoUploadFailed(err)
 	}
 
 	// Set gym properties
-	gym.PhotoGym = photoRequest.Alias + filepath.Ext(photoRequest.Handler.Filename)
-	gym.PhotoUrl = fmt.Sprintf("https://storage.googleapis.com/kalorize-71324.appspot.com/%s", storagePath)
+	gym.PhotoGym = filename
+	gym.PhotoUrl = photoUrls.Original
+	gym.PhotoMediumUrl = photoUrls.Medium
+	gym.PhotoThumbnailUrl = photoUrls.Thumbnail
 	err = service.gymRepo.CreateNewGym(gym)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to create gym"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Failed to create gym", err)
 	}
+	recordAudit(service.auditRepo, admin, requestMeta, "gym.create", auditEntityGym, gym.IdGym.String(), nil, gym)
 	response.StatusCode = 200
-	response.Messages = "Success"
+	response.Messages = "success"
 	response.Data = gym
-	return response
+	return response, nil
 }
 
-func (service *adminService) RegisterFranchise(bearerToken string, registerFranchiseRequest utils.FranchiseRequest) utils.Response {
+func (service *adminService) RegisterFranchise(bearerToken string, requestMeta utils.RequestMeta, registerFranchiseRequest utils.FranchiseRequest) (utils.Response, error) {
+	if err := utils.Validate(registerFranchiseRequest); err != nil {
+		return utils.Response{}, err
+	}
 	var response utils.Response
-	adminEmail, err := utils.ParseDataEmail(bearerToken)
-	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
 	}
-	admin, err := service.userRepo.GetUserByEmail(adminEmail)
-	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+	if _, err := service.franchiseRepo.GetFranchiseByEmail(registerFranchiseRequest.EmailFranchise); err == nil {
+		return utils.Response{}, utils.Conflict("franchise_email_taken")
 	}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerFranchiseRequest.PasswordFranchise), bcrypt.DefaultCost)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Password hashing failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Password hashing failed", err)
 	}
 
 	franchise := models.Franchise{
@@ -172,117 +115,301 @@ func (service *adminService) RegisterFranchise(bearerToken string, registerFranc
 	}
 	err = service.franchiseRepo.CreateFranchise(franchise)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to create franchise"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Failed to create franchise", err)
 	}
+	recordAudit(service.auditRepo, admin, requestMeta, "franchise.create", auditEntityFranchise, franchise.IdFranchise.String(), nil, auditFranchise(franchise))
 	response.StatusCode = 200
-	response.Messages = "Success"
-	response.Data = franchise
-	return response
+	response.Messages = "success"
+	response.Data = formatter.FormatterFranchise(franchise)
+	return response, nil
 }
 
-func (service *adminService) RegisterMakanan(bearerToken string, registMakananRequest utils.MakananRequest) utils.Response {
-	var response utils.Response
-	adminEmail, err := utils.ParseDataEmail(bearerToken)
-	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+func (service *adminService) RegisterMakanan(bearerToken string, requestMeta utils.RequestMeta, registMakananRequest utils.MakananRequest) (utils.Response, error) {
+	if err := utils.Validate(registMakananRequest); err != nil {
+		return utils.Response{}, err
 	}
-	admin, err := service.userRepo.GetUserByEmail(adminEmail)
-	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+	var response utils.Response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
 	}
 
 	id := utils.GenerateIdMakanan(registMakananRequest.Nama)
 	makanan := models.Makanan{
-		IdMakanan:     id,
-		Nama:          registMakananRequest.Nama,
-		Kalori:        registMakananRequest.Kalori,
-		Protein:       registMakananRequest.Protein,
-		ListFranchise: strings.Join(registMakananRequest.ListFranchise, ", "),
-		Bahan:         strings.Join(registMakananRequest.Bahan, ", "),
-		CookingStep:   strings.Join(registMakananRequest.CookingStep, "., "),
+		IdMakanan:   id,
+		Nama:        registMakananRequest.Nama,
+		Kalori:      registMakananRequest.Kalori,
+		Protein:     registMakananRequest.Protein,
+		Bahan:       strings.Join(registMakananRequest.Bahan, ", "),
+		CookingStep: strings.Join(registMakananRequest.CookingStep, "., "),
+	}
+	for _, idFranchise := range registMakananRequest.ListFranchise {
+		if _, err := service.franchiseRepo.GetFranchiseById(idFranchise.String()); err != nil {
+			return utils.Response{}, utils.NotFound("franchise_not_found")
+		}
 	}
 	err = service.makananRepo.CreateMakanan(makanan)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to create makanan"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Failed to create makanan", err)
+	}
+	for _, idFranchise := range registMakananRequest.ListFranchise {
+		err = service.franchiseRepo.AddFranchiseMakanan(models.FranchiseMakanan{
+			IdFranchiseMakanan: uuid.New(),
+			IdFranchise:        idFranchise,
+			IdMakanan:          makanan.IdMakanan,
+		})
+		if err != nil {
+			return utils.Response{}, utils.Internal("Failed to add makanan to franchise", err)
+		}
 	}
+	recordAudit(service.auditRepo, admin, requestMeta, "makanan.create", auditEntityMakanan, makanan.IdMakanan, nil, struct {
+		models.Makanan
+		ListFranchise []uuid.UUID `json:"list_franchise"`
+	}{makanan, registMakananRequest.ListFranchise})
 	response.StatusCode = 200
-	response.Messages = "Success"
+	response.Messages = "success"
 	response.Data = makanan
-	return response
+	return response, nil
 }
 
-func (service *adminService) GenerateGymToken(bearerToken string, idGym uuid.UUID) utils.Response {
+func (service *adminService) AttachFranchiseMakanan(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID, idMakanan string) (utils.Response, error) {
 	var response utils.Response
-	adminEmail, err := utils.ParseDataEmail(bearerToken)
-	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
 	}
-	admin, err := service.userRepo.GetUserByEmail(adminEmail)
-	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+
+	if _, err := service.franchiseRepo.GetFranchiseById(idFranchise.String()); err != nil {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+	if _, err := service.makananRepo.GetMakananById(idMakanan); err != nil {
+		return utils.Response{}, utils.NotFound("makanan_not_found")
 	}
+	franchiseMakanan := models.FranchiseMakanan{
+		IdFranchiseMakanan: uuid.New(),
+		IdFranchise:        idFranchise,
+		IdMakanan:          idMakanan,
+	}
+	err = service.franchiseRepo.AddFranchiseMakanan(franchiseMakanan)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to add makanan to franchise", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "franchise_makanan.attach", auditEntityFranchiseMakanan, franchiseMakanan.IdFranchiseMakanan.String(), nil, franchiseMakanan)
+	response.StatusCode = 200
+	response.Messages = "success"
+	response.Data = franchiseMakanan
+	return response, nil
+}
 
-	gym, err := service.gymRepo.GetGymById(idGym)
+func (service *adminService) DetachFranchiseMakanan(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID, idMakanan string) (utils.Response, error) {
+	var response utils.Response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+
+	removed, err := service.franchiseRepo.RemoveFranchiseMakanan(idFranchise, idMakanan)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to remove makanan from franchise", err)
+	}
+	if !removed {
+		return utils.Response{}, utils.NotFound("menu_item_not_found")
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "franchise_makanan.detach", auditEntityFranchiseMakanan, idFranchise.String()+"/"+idMakanan, map[string]interface{}{"id_franchise": idFranchise, "id_makanan": idMakanan}, nil)
+	response.StatusCode = 200
+	response.Messages = "success"
+	response.Data = nil
+	return response, nil
+}
+
+func (service *adminService) UpdateMakananPhoto(bearerToken string, requestMeta utils.RequestMeta, idMakanan string, photoRequest utils.UploadedPhoto) (utils.Response, error) {
+	var response utils.Response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+
+	makanan, err := service.makananRepo.GetMakananById(idMakanan)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("makanan_not_found")
+	}
+
+	_, photoUrls, err := uploadPhoto(service.fileStorage, photoRequest)
+	if err != nil {
+		return utils.Response{}, photoUploadFailed(err)
+	}
+	before := makanan
+	makanan.Foto = photoUrls.Original
+	makanan.FotoMedium = photoUrls.Medium
+	makanan.FotoThumbnail = photoUrls.Thumbnail
+
+	err = service.makananRepo.UpdateMakanan(makanan)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to update makanan", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "makanan.update_photo", auditEntityMakanan, makanan.IdMakanan, before, makanan)
+	response.StatusCode = 200
+	response.Messages = "success"
+	response.Data = makanan
+	return response, nil
+}
+
+// TranslateMakanan sets the name, ingredients and cooking steps of a makanan
+// in a language other than Indonesian, replacing an earlier translation.
+func (service *adminService) TranslateMakanan(bearerToken string, requestMeta utils.RequestMeta, translationRequest utils.MakananTranslationRequest) (utils.Response, error) {
+	if err := utils.Validate(translationRequest); err != nil {
+		return utils.Response{}, err
+	}
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	if _, err := service.makananRepo.GetMakananById(translationRequest.IdMakanan); err != nil {
+		return utils.Response{}, utils.NotFound("makanan_not_found")
+	}
+
+	var before interface{}
+	if translation, err := service.makananRepo.GetMakananTranslation(translationRequest.IdMakanan, translationRequest.Locale); err == nil {
+		before = translation
+	}
+	translation := models.MakananTranslation{
+		IdMakanan:   translationRequest.IdMakanan,
+		Locale:      translationRequest.Locale,
+		Nama:        translationRequest.Nama,
+		Bahan:       strings.Join(translationRequest.Bahan, ", "),
+		CookingStep: strings.Join(translationRequest.CookingStep, "., "),
+		UpdatedAt:   time.Now(),
+	}
+	if err := service.makananRepo.SaveMakananTranslation(translation); err != nil {
+		return utils.Response{}, utils.Internal("Failed to save makanan translation", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "makanan.translate", auditEntityMakanan, translation.IdMakanan, before, translation)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: translation}, nil
+}
+
+func (service *adminService) GenerateGymToken(bearerToken string, requestMeta utils.RequestMeta, kodeGymRequest utils.KodeGymRequest) (utils.Response, error) {
+	if err := utils.Validate(kodeGymRequest); err != nil {
+		return utils.Response{}, err
+	}
+	var response utils.Response
+	admin, err := service.admin(bearerToken)
 	if err != nil {
-		response.StatusCode = 404
-		response.Messages = "Gym not found"
-		response.Data = nil
-		return response
+		return utils.Response{}, err
 	}
 
-	kodeGym := models.KodeGym{
-		IdKodeGym:   uuid.New(),
-		KodeGym:     utils.GenerateKodeGym(gym.NamaGym),
-		IdGym:       gym.IdGym,
-		ExpiredTime: time.Now().AddDate(0, 0, 7),
+	gym, err := service.gymRepo.GetGymById(kodeGymRequest.IdGym)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("gym_not_found")
 	}
 
+	kodeGym, err := newKodeGym(service.gymKode, gym, kodeGymRequest, time.Now().AddDate(0, 0, 7))
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to generate kode gym", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "kode_gym.create", auditEntityKodeGym, kodeGym.IdKodeGym.String(), nil, kodeGym)
+
 	response.StatusCode = 200
-	response.Messages = "Success"
+	response.Messages = "success"
 	response.Data = kodeGym
-	return response
+	return response, nil
 }
 
-func (service *adminService) RegisterUser(bearerToken string, registerUserRequest utils.UserRequest, photoRequest utils.UploadedPhoto) utils.Response {
+func (service *adminService) GetAllKodeGym(bearerToken string, idGym uuid.UUID) (utils.Response, error) {
 	var response utils.Response
-	adminEmail, err := utils.ParseDataEmail(bearerToken)
-	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
 	}
-	admin, err := service.userRepo.GetUserByEmail(adminEmail)
-	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+
+	var kodeGyms []models.KodeGym
+	var err error
+	if idGym == uuid.Nil {
+		kodeGyms, err = service.gymKode.GetAllKodeGym()
+	} else {
+		kodeGyms, err = service.gymKode.GetKodeGymByIdGym(idGym)
+	}
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get kode gym", err)
+	}
+
+	response.StatusCode = 200
+	response.Messages = "success"
+	response.Data = formatter.FormatterKodeGym(kodeGyms)
+	return response, nil
+}
+
+func (service *adminService) RevokeKodeGym(bearerToken string, requestMeta utils.RequestMeta, idKodeGym uuid.UUID) (utils.Response, error) {
+	var response utils.Response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+
+	kodeGym, err := service.gymKode.GetKodeGymById(idKodeGym)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("kode_gym_not_found")
+	}
+	before := kodeGym
+	kodeGym, err = revokeKodeGym(service.gymKode, kodeGym)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to revoke kode gym", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "kode_gym.revoke", auditEntityKodeGym, kodeGym.IdKodeGym.String(), before, kodeGym)
+
+	response.StatusCode = 200
+	response.Messages = "success"
+	response.Data = kodeGym
+	return response, nil
+}
+
+func (service *adminService) AssignGymOwner(bearerToken string, requestMeta utils.RequestMeta, idUser uuid.UUID, idGym uuid.UUID) (utils.Response, error) {
+	var response utils.Response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+
+	user, err := service.userRepo.GetUserById(idUser)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("user_not_found")
+	}
+	if _, err := service.gymRepo.GetGymById(idGym); err != nil {
+		return utils.Response{}, utils.NotFound("gym_not_found")
+	}
+
+	before := user
+	if user.Role != "admin" && user.Role != "gym_owner" {
+		user.Role = "gym_owner"
+		if err := service.userRepo.UpdateUser(user); err != nil {
+			return utils.Response{}, utils.Internal("Failed to update user", err)
+		}
+	}
+	gymOwner := models.GymOwner{IdUser: idUser, IdGym: idGym}
+	if err := service.gymOwnerRepo.CreateGymOwner(gymOwner); err != nil {
+		return utils.Response{}, utils.Internal("Failed to assign gym owner", err)
+	}
+	if user.Role != before.Role {
+		recordAudit(service.auditRepo, admin, requestMeta, "user.change_role", auditEntityUser, user.IdUser.String(), auditUser(before), auditUser(user))
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "gym_owner.assign", auditEntityGymOwner, idUser.String()+"/"+idGym.String(), nil, gymOwner)
+
+	response.StatusCode = 200
+	response.Messages = "success"
+	response.Data = gymOwner
+	return response, nil
+}
+
+func (service *adminService) RegisterUser(bearerToken string, requestMeta utils.RequestMeta, registerUserRequest utils.UserRequest, photoRequest utils.UploadedPhoto) (utils.Response, error) {
+	if err := utils.Validate(registerUserRequest); err != nil {
+		return utils.Response{}, err
+	}
+	var response utils.Response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
 	}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerUserRequest.Password), bcrypt.DefaultCost)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Password hashing failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Password hashing failed", err)
 	}
 
 	user := models.User{
@@ -300,157 +427,217 @@ func (service *adminService) RegisterUser(bearerToken string, registerUserReques
 		Role:         registerUserRequest.Role,
 	}
 
-	opt := option.WithCredentialsFile("config/credentials.json")
-	app, err := firebase.NewApp(context.Background(), nil, opt)
+	filename, photoUrls, err := uploadPhoto(service.fileStorage, photoRequest)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to initialize Firebase app"
-		response.Data = nil
-		return response
+		return utils.Response{}, photoUploadFailed(err)
 	}
 
-	client, err := app.Storage(context.Background())
+	user.Foto = filename
+	user.FotoUrl = photoUrls.Original
+	user.FotoMediumUrl = photoUrls.Medium
+	user.FotoThumbnailUrl = photoUrls.Thumbnail
+
+	err = service.userRepo.CreateNewUser(user)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to initialize Firebase Storage client"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Failed to create user", err)
 	}
+	recordAudit(service.auditRepo, admin, requestMeta, "user.create", auditEntityUser, user.IdUser.String(), nil, auditUser(user))
+	response.StatusCode = 200
+	response.Messages = "success"
+	response.Data = formatter.FormatterUser(user)
+	return response, nil
+}
 
-	storagePath := fmt.Sprintf("images/%s", registerUserRequest.Foto)
-	reader := strings.NewReader(registerUserRequest.Foto)
-	bucket, err := client.Bucket("kalorize-71324.appspot.com")
-	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to get bucket handle from the client"
-		response.Data = nil
-		return response
+// GetAllUser lists the users matching the filter, a page at a time. Each user
+// comes with the membership that decides their membership status: the current
+// one, or the next upcoming one for users whose membership has not started.
+func (service *adminService) GetAllUser(bearerToken string, filter utils.UserFilter) (utils.Response, error) {
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
 	}
 
-	wc := bucket.Object(storagePath).NewWriter(context.Background())
-	wc.ACL = []storage.ACLRule{{Entity: storage.AllUsers, Role: storage.RoleReader}}
-	if _, err := io.Copy(wc, reader); err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to upload file to Firebase Storage"
-		response.Data = nil
-		return response
+	now := time.Now()
+	users, total, err := service.userRepo.GetUsersByFilter(filter, now)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get all user", err)
+	}
+	memberships, err := service.statusMemberships(users, now)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get all user", err)
 	}
 
-	if err := wc.Close(); err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to close Firebase Storage writer"
-		response.Data = nil
-		return response
+	usersFormatted := []formatter.AdminUserFormat{}
+	for _, user := range users {
+		userFormatted := formatter.AdminUserFormat{UserFormat: formatter.FormatterUser(user)}
+		if membership, ok := memberships[user.IdUser]; ok {
+			membershipFormatted := formatter.FormatterMembership(membership, now)
+			userFormatted.Membership = &membershipFormatted
+		}
+		usersFormatted = append(usersFormatted, userFormatted)
 	}
 
-	user.Foto = registerUserRequest.Foto
-	user.FotoUrl = fmt.Sprintf("https://storage.googleapis.com/kalorize-71324.appspot.com/%s", storagePath)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: utils.Page{
+		Items: usersFormatted,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Total: int(total),
+	}}, nil
+}
 
-	err = service.userRepo.CreateNewUser(user)
+// statusMemberships returns, per user, the membership that decides their
+// membership status at now. Users who never joined a gym are left out.
+func (service *adminService) statusMemberships(users []models.User, now time.Time) (map[uuid.UUID]models.Membership, error) {
+	idUsers := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		idUsers = append(idUsers, user.IdUser)
+	}
+	memberships, err := service.membershipRepo.GetMembershipsByIdUsers(idUsers)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to create user"
-		response.Data = nil
-		return response
+		return nil, err
 	}
-	response.StatusCode = 200
-	response.Messages = "Success"
-	response.Data = user
-	return response
+	membershipsByUser := map[uuid.UUID][]models.Membership{}
+	for _, membership := range memberships {
+		membershipsByUser[membership.IdUser] = append(membershipsByUser[membership.IdUser], membership)
+	}
+
+	statusMemberships := map[uuid.UUID]models.Membership{}
+	for idUser, userMembershipList := range membershipsByUser {
+		if current := currentMembership(userMembershipList, now); current != nil {
+			statusMemberships[idUser] = *current
+		} else {
+			statusMemberships[idUser] = userMembershipList[len(userMembershipList)-1]
+		}
+	}
+	return statusMemberships, nil
 }
 
-func (service *adminService) GetAllUser(bearerToken string) utils.Response {
-	var response utils.Response
-	adminEmail, err := utils.ParseDataEmail(bearerToken)
-	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+// BulkUpdateUsers applies one action to several users. Either every user is
+// changed or, if any of them cannot be, none is.
+func (service *adminService) BulkUpdateUsers(bearerToken string, requestMeta utils.RequestMeta, bulkRequest utils.BulkUserRequest) (utils.Response, error) {
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
 	}
-	admin, err := service.userRepo.GetUserByEmail(adminEmail)
-	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+
+	idUsers := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, idUser := range bulkRequest.IdUsers {
+		if !seen[idUser] {
+			seen[idUser] = true
+			idUsers = append(idUsers, idUser)
+		}
 	}
 
-	users, err := service.userRepo.GetAllUser()
+	now := time.Now()
+	if bulkRequest.Action == utils.BulkUserExtendMembership {
+		return service.extendMemberships(admin, requestMeta, idUsers, bulkRequest.Days, now)
+	}
+	before, err := service.userRepo.GetUsersByIds(idUsers)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to get all user"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Failed to update user", err)
 	}
-	response.StatusCode = 200
-	response.Messages = "Success"
-	response.Data = users
-	return response
+	switch bulkRequest.Action {
+	case utils.BulkUserDeactivate:
+		err = service.userRepo.DeactivateUsers(idUsers, now)
+	case utils.BulkUserActivate:
+		err = service.userRepo.UpdateUsers(idUsers, map[string]interface{}{"deactivated_at": nil})
+	case utils.BulkUserChangeRole:
+		err = service.userRepo.UpdateUsers(idUsers, map[string]interface{}{"role": bulkRequest.Role})
+	default:
+		return utils.Response{}, utils.Invalid("unknown_action")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.Response{}, utils.NotFound("user_not_found")
+	}
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to update user", err)
+	}
+	for _, user := range before {
+		after := user
+		switch bulkRequest.Action {
+		case utils.BulkUserDeactivate:
+			after.DeactivatedAt = &now
+		case utils.BulkUserActivate:
+			after.DeactivatedAt = nil
+		case utils.BulkUserChangeRole:
+			after.Role = bulkRequest.Role
+		}
+		recordAudit(service.auditRepo, admin, requestMeta, "user.bulk_"+bulkRequest.Action, auditEntityUser, user.IdUser.String(), auditUser(user), auditUser(after))
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: map[string]interface{}{"updated": len(idUsers)}}, nil
 }
 
-func (service *adminService) GetUserById(bearerToken string, id uuid.UUID) utils.Response {
-	var response utils.Response
-	adminEmail, err := utils.ParseDataEmail(bearerToken)
-	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+// extendMemberships adds days to the membership that decides each user's
+// status. A lapsed membership is extended from now rather than from its end.
+func (service *adminService) extendMemberships(admin models.User, requestMeta utils.RequestMeta, idUsers []uuid.UUID, days int, now time.Time) (utils.Response, error) {
+	users, err := service.userRepo.GetUsersByIds(idUsers)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to update user", err)
 	}
-	admin, err := service.userRepo.GetUserByEmail(adminEmail)
-	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+	if len(users) != len(idUsers) {
+		return utils.Response{}, utils.NotFound("user_not_found")
+	}
+	memberships, err := service.statusMemberships(users, now)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to update user", err)
+	}
+
+	before := make([]models.Membership, 0, len(users))
+	extended := make([]models.Membership, 0, len(users))
+	for _, user := range users {
+		membership, ok := memberships[user.IdUser]
+		if !ok {
+			return utils.Response{}, &utils.Error{Kind: utils.KindValidation, Code: "membership_missing", Args: []interface{}{user.Email}}
+		}
+		before = append(before, membership)
+		from := membership.EndDate
+		if from.Before(now) {
+			from = now
+		}
+		membership.EndDate = from.AddDate(0, 0, days)
+		extended = append(extended, membership)
+	}
+	if err := service.membershipRepo.SaveMemberships(extended); err != nil {
+		return utils.Response{}, utils.Internal("Failed to update user", err)
+	}
+	for i := range extended {
+		recordAudit(service.auditRepo, admin, requestMeta, "membership.extend", auditEntityMembership, extended[i].IdMembership.String(), before[i], extended[i])
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formatter.FormatterMemberships(extended, now)}, nil
+}
+
+func (service *adminService) GetUserById(bearerToken string, id uuid.UUID) (utils.Response, error) {
+	var response utils.Response
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
 	}
 
 	user, err := service.userRepo.GetUserById(id)
 	if err != nil {
-		response.StatusCode = 404
-		response.Messages = "User not found"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.NotFound("user_not_found")
 	}
 	response.StatusCode = 200
-	response.Messages = "Success"
-	response.Data = user
-	return response
+	response.Messages = "success"
+	response.Data = formatter.FormatterUser(user)
+	return response, nil
 }
 
-func (service *adminService) UpdateUser(bearerToken string, id uuid.UUID, updateUserRequest utils.UserRequest) utils.Response {
-	var response utils.Response
-	adminEmail, err := utils.ParseDataEmail(bearerToken)
-	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+func (service *adminService) UpdateUser(bearerToken string, requestMeta utils.RequestMeta, id uuid.UUID, updateUserRequest utils.UserRequest) (utils.Response, error) {
+	if err := utils.Validate(updateUserRequest); err != nil {
+		return utils.Response{}, err
 	}
-	admin, err := service.userRepo.GetUserByEmail(adminEmail)
-	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+	var response utils.Response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
 	}
 
 	user, err := service.userRepo.GetUserById(id)
 	if err != nil {
-		response.StatusCode = 404
-		response.Messages = "User not found"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.NotFound("user_not_found")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updateUserRequest.Password), bcrypt.DefaultCost)
-	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Password hashing failed"
-		response.Data = nil
-		return response
-	}
+	before := user
 
 	if updateUserRequest.Email != "" {
 		user.Email = updateUserRequest.Email
@@ -489,59 +676,453 @@ func (service *adminService) UpdateUser(bearerToken string, id uuid.UUID, update
 	}
 
 	if updateUserRequest.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updateUserRequest.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return utils.Response{}, utils.Internal("Password hashing failed", err)
+		}
 		user.Password = string(hashedPassword)
 	}
 
 	err = service.userRepo.UpdateUser(user)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to update user"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Failed to update user", err)
 	}
+	recordAudit(service.auditRepo, admin, requestMeta, "user.update", auditEntityUser, user.IdUser.String(), auditUser(before), auditUser(user))
 	response.StatusCode = 200
-	response.Messages = "Success"
-	response.Data = user
-	return response
+	response.Messages = "success"
+	response.Data = formatter.FormatterUser(user)
+	return response, nil
 }
 
-func (service *adminService) DeleteUser(bearerToken string, id uuid.UUID) utils.Response {
+func (service *adminService) DeleteUser(bearerToken string, requestMeta utils.RequestMeta, id uuid.UUID) (utils.Response, error) {
 	var response utils.Response
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+
+	user, err := service.userRepo.GetUserById(id)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("user_not_found")
+	}
+	deleted, err := service.userRepo.DeleteUser(id)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to delete user", err)
+	}
+	if !deleted {
+		return utils.Response{}, utils.NotFound("user_not_found")
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "user.delete", auditEntityUser, id.String(), auditUser(user), nil)
+	response.StatusCode = 200
+	response.Messages = "success"
+	return response, nil
+}
+
+// RestoreUser brings back a deleted user who has not been erased yet,
+// cancelling a self-service deletion too.
+func (service *adminService) RestoreUser(bearerToken string, requestMeta utils.RequestMeta, id uuid.UUID) (utils.Response, error) {
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	user, err := service.userRepo.GetUserByIdIncludingDeleted(id)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("user_not_found")
+	}
+	if !user.Restorable() {
+		return utils.Response{}, utils.Conflict("user_not_restorable")
+	}
+	if err := service.userRepo.RestoreUser(id); err != nil {
+		return utils.Response{}, utils.Internal("Failed to restore user", err)
+	}
+	before := user
+	user.DeletedAt = gorm.DeletedAt{}
+	user.ErasureScheduledAt = nil
+	recordAudit(service.auditRepo, admin, requestMeta, "user.restore", auditEntityUser, id.String(), auditUser(before), auditUser(user))
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formatter.FormatterUser(user)}, nil
+}
+
+// EraseUser anonymises a user right away instead of waiting for the erasure
+// job, for example to answer an erasure request made outside the app.
+func (service *adminService) EraseUser(bearerToken string, requestMeta utils.RequestMeta, id uuid.UUID) (utils.Response, error) {
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	if admin.IdUser == id {
+		return utils.Response{}, utils.Invalid("cannot_erase_self")
+	}
+	user, err := service.userRepo.GetUserByIdIncludingDeleted(id)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("user_not_found")
+	}
+	if user.ErasedAt != nil {
+		return utils.Response{}, utils.Conflict("user_erased")
+	}
+	if err := eraseUser(service.userRepo, service.dataExportRepo, service.fileStorage, user, time.Now()); err != nil {
+		return utils.Response{}, utils.Internal("Failed to erase user", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "user.erase", auditEntityUser, id.String(), nil, nil)
+	return utils.Response{StatusCode: 200, Messages: "success"}, nil
+}
+
+// admin returns the user the token belongs to. It returns an unauthorized
+// error, to be sent as is, when the token is invalid or its user is not an
+// admin.
+func (service *adminService) admin(bearerToken string) (models.User, error) {
 	adminEmail, err := utils.ParseDataEmail(bearerToken)
 	if adminEmail == "" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+		return models.User{}, utils.Unauthorized("unauthorized")
 	}
 	admin, err := service.userRepo.GetUserByEmail(adminEmail)
 	if admin.Role != "admin" || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Unauthorized"
-		response.Data = nil
-		return response
+		return models.User{}, utils.Unauthorized("unauthorized")
 	}
+	return admin, nil
+}
 
-	err = service.userRepo.DeleteUser(id)
+func (service *adminService) GetAllGym(bearerToken string) (utils.Response, error) {
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
+	}
+	gyms, err := service.gymRepo.GetGym()
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to delete user"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Failed to get gym", err)
 	}
-	response.StatusCode = 200
-	response.Messages = "Success"
-	return response
+	return utils.Response{StatusCode: 200, Messages: "success", Data: gyms}, nil
+}
+
+func (service *adminService) GetGymById(bearerToken string, idGym uuid.UUID) (utils.Response, error) {
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
+	}
+	gym, err := service.gymRepo.GetGymById(idGym)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("gym_not_found")
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: gym}, nil
+}
+
+func (service *adminService) UpdateGym(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, gymRequest utils.GymRequest) (utils.Response, error) {
+	if err := utils.Validate(gymRequest); err != nil {
+		return utils.Response{}, err
+	}
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	gym, err := service.gymRepo.GetGymById(idGym)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("gym_not_found")
+	}
+	before := gym
+	if gymRequest.NamaGym != "" {
+		gym.NamaGym = gymRequest.NamaGym
+	}
+	if gymRequest.AlamatGym != "" {
+		gym.AlamatGym = gymRequest.AlamatGym
+	}
+	if gymRequest.Latitude != 0 {
+		gym.Latitude = gymRequest.Latitude
+	}
+	if gymRequest.Longitude != 0 {
+		gym.Longitude = gymRequest.Longitude
+	}
+	if gymRequest.LinkGoogle != "" {
+		gym.LinkGoogle = gymRequest.LinkGoogle
+	}
+	if err := service.gymRepo.UpdateGym(gym); err != nil {
+		return utils.Response{}, utils.Internal("Failed to update gym", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "gym.update", auditEntityGym, gym.IdGym.String(), before, gym)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: gym}, nil
+}
+
+func (service *adminService) UpdateGymPhoto(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, photoRequest utils.UploadedPhoto) (utils.Response, error) {
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	gym, err := service.gymRepo.GetGymById(idGym)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("gym_not_found")
+	}
+	before := gym
+	filename, photoUrls, err := uploadPhoto(service.fileStorage, photoRequest)
+	if err != nil {
+		return utils.Response{}, photoUploadFailed(err)
+	}
+	gym.PhotoGym = filename
+	gym.PhotoUrl = photoUrls.Original
+	gym.PhotoMediumUrl = photoUrls.Medium
+	gym.PhotoThumbnailUrl = photoUrls.Thumbnail
+	if err := service.gymRepo.UpdateGym(gym); err != nil {
+		return utils.Response{}, utils.Internal("Failed to update gym", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "gym.update_photo", auditEntityGym, gym.IdGym.String(), before, gym)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: gym}, nil
+}
+
+// SetGymActive deactivates a gym, which stops new members from joining with
+// its codes, or activates it again. Existing memberships are not affected.
+func (service *adminService) SetGymActive(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, active bool) (utils.Response, error) {
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	gym, err := service.gymRepo.GetGymById(idGym)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("gym_not_found")
+	}
+	before := gym
+	if active {
+		gym.DeactivatedAt = nil
+	} else if gym.DeactivatedAt == nil {
+		now := time.Now()
+		gym.DeactivatedAt = &now
+	}
+	if err := service.gymRepo.UpdateGym(gym); err != nil {
+		return utils.Response{}, utils.Internal("Failed to update gym", err)
+	}
+	action := "gym.deactivate"
+	if active {
+		action = "gym.activate"
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, action, auditEntityGym, gym.IdGym.String(), before, gym)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: gym}, nil
+}
+
+func (service *adminService) DeleteGym(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID) (utils.Response, error) {
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	gym, err := service.gymRepo.GetGymById(idGym)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("gym_not_found")
+	}
+	deleted, err := service.gymRepo.DeleteGym(idGym)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to delete gym", err)
+	}
+	if !deleted {
+		return utils.Response{}, utils.NotFound("gym_not_found")
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "gym.delete", auditEntityGym, idGym.String(), gym, nil)
+	return utils.Response{StatusCode: 200, Messages: "success"}, nil
+}
+
+// GetGymMembers lists everyone who joined the gym with one of its codes,
+// most recent expiry first.
+func (service *adminService) GetGymMembers(bearerToken string, idGym uuid.UUID) (utils.Response, error) {
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
+	}
+	if _, err := service.gymRepo.GetGymById(idGym); err != nil {
+		return utils.Response{}, utils.NotFound("gym_not_found")
+	}
+	usedCodes, err := service.gymUsedCode.GetUsedCodesByIdGym(idGym)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get members", err)
+	}
+	ids := make([]uuid.UUID, 0, len(usedCodes))
+	for _, usedCode := range usedCodes {
+		ids = append(ids, usedCode.IdUser)
+	}
+	users, err := service.userRepo.GetUsersByIds(ids)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get members", err)
+	}
+	byId := make(map[uuid.UUID]models.User, len(users))
+	for _, user := range users {
+		byId[user.IdUser] = user
+	}
+	result := make([]map[string]interface{}, 0, len(usedCodes))
+	for _, usedCode := range usedCodes {
+		user, ok := byId[usedCode.IdUser]
+		if !ok {
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"idUser":    user.IdUser,
+			"fullname":  user.Fullname,
+			"email":     user.Email,
+			"foto":      user.FotoThumbnailUrl,
+			"kodeGym":   usedCode.KodeGym,
+			"expiredAt": usedCode.ExpiredAt,
+		})
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: result}, nil
+}
+
+func (service *adminService) GetAllFranchise(bearerToken string) (utils.Response, error) {
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
+	}
+	franchises, err := service.franchiseRepo.GetAllFranchise()
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get franchise", err)
+	}
+	formattedFranchise := make([]formatter.FranchiseFormat, 0, len(franchises))
+	for _, franchise := range franchises {
+		formattedFranchise = append(formattedFranchise, formatter.FormatterFranchise(franchise))
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formattedFranchise}, nil
+}
+
+func (service *adminService) GetFranchiseById(bearerToken string, idFranchise uuid.UUID) (utils.Response, error) {
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
+	}
+	franchise, err := service.franchiseRepo.GetFranchiseById(idFranchise.String())
+	if err != nil {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formatter.FormatterFranchise(franchise)}, nil
+}
+
+// UpdateFranchise changes the fields that are set in the request. A new
+// password is hashed like the one given on registration.
+func (service *adminService) UpdateFranchise(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID, franchiseRequest utils.FranchiseRequest) (utils.Response, error) {
+	if err := utils.Validate(franchiseRequest); err != nil {
+		return utils.Response{}, err
+	}
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	franchise, err := service.franchiseRepo.GetFranchiseById(idFranchise.String())
+	if err != nil {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+	before := franchise
+	if franchiseRequest.EmailFranchise != "" && franchiseRequest.EmailFranchise != franchise.EmailFranchise {
+		if _, err := service.franchiseRepo.GetFranchiseByEmail(franchiseRequest.EmailFranchise); err == nil {
+			return utils.Response{}, utils.Conflict("franchise_email_taken")
+		}
+		franchise.EmailFranchise = franchiseRequest.EmailFranchise
+	}
+	if franchiseRequest.PasswordFranchise != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(franchiseRequest.PasswordFranchise), bcrypt.DefaultCost)
+		if err != nil {
+			return utils.Response{}, utils.Internal("Password hashing failed", err)
+		}
+		franchise.PasswordFranchise = string(hashedPassword)
+	}
+	if franchiseRequest.NamaFranchise != "" {
+		franchise.NamaFranchise = franchiseRequest.NamaFranchise
+	}
+	if franchiseRequest.LatitudeFranchise != 0 {
+		franchise.LatitudeFranchise = franchiseRequest.LatitudeFranchise
+	}
+	if franchiseRequest.LongitudeFranchise != 0 {
+		franchise.LongitudeFranchise = franchiseRequest.LongitudeFranchise
+	}
+	if franchiseRequest.NoTeleponFranchise != "" {
+		franchise.NoTeleponFranchise = franchiseRequest.NoTeleponFranchise
+	}
+	if franchiseRequest.LokasiFranchise != "" {
+		franchise.LokasiFranchise = franchiseRequest.LokasiFranchise
+	}
+	if err := service.franchiseRepo.UpdateFranchise(franchise); err != nil {
+		return utils.Response{}, utils.Internal("Failed to update franchise", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "franchise.update", auditEntityFranchise, franchise.IdFranchise.String(), auditFranchise(before), auditFranchise(franchise))
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formatter.FormatterFranchise(franchise)}, nil
+}
+
+func (service *adminService) UpdateFranchisePhoto(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID, photoRequest utils.UploadedPhoto) (utils.Response, error) {
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	franchise, err := service.franchiseRepo.GetFranchiseById(idFranchise.String())
+	if err != nil {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+	before := franchise
+	_, photoUrls, err := uploadPhoto(service.fileStorage, photoRequest)
+	if err != nil {
+		return utils.Response{}, photoUploadFailed(err)
+	}
+	franchise.FotoFranchise = photoUrls.Original
+	franchise.FotoMedium = photoUrls.Medium
+	franchise.FotoThumbnail = photoUrls.Thumbnail
+	if err := service.franchiseRepo.UpdateFranchise(franchise); err != nil {
+		return utils.Response{}, utils.Internal("Failed to update franchise", err)
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "franchise.update_photo", auditEntityFranchise, franchise.IdFranchise.String(), auditFranchise(before), auditFranchise(franchise))
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formatter.FormatterFranchise(franchise)}, nil
+}
+
+func (service *adminService) DeleteFranchise(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID) (utils.Response, error) {
+	admin, err := service.admin(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	franchise, err := service.franchiseRepo.GetFranchiseById(idFranchise.String())
+	if err != nil {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+	deleted, err := service.franchiseRepo.DeleteFranchise(idFranchise)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to delete franchise", err)
+	}
+	if !deleted {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+	recordAudit(service.auditRepo, admin, requestMeta, "franchise.delete", auditEntityFranchise, idFranchise.String(), auditFranchise(franchise), nil)
+	return utils.Response{StatusCode: 200, Messages: "success"}, nil
+}
+
+// GetAuditLogs lists the audit log entries matching the filter, newest first.
+func (service *adminService) GetAuditLogs(bearerToken string, filter utils.AuditLogFilter) (utils.Response, error) {
+	if _, err := service.admin(bearerToken); err != nil {
+		return utils.Response{}, err
+	}
+	auditLogs, total, err := service.auditRepo.GetAuditLogsByFilter(filter)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get audit logs", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: utils.Page{
+		Items: auditLogs,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Total: int(total),
+	}}, nil
 }
 
 type AdminService interface {
-	RegisterGym(bearerToken string, registGymRequest utils.GymRequest, photoRequest utils.UploadedPhoto) utils.Response
-	RegisterFranchise(bearerToken string, registFranchiseRequest utils.FranchiseRequest) utils.Response
-	RegisterMakanan(bearerToken string, registMakananRequest utils.MakananRequest) utils.Response
-	RegisterUser(bearerToken string, registerUserRequest utils.UserRequest, photoRequest utils.UploadedPhoto) utils.Response
-	GenerateGymToken(bearerToken string, idGym uuid.UUID) utils.Response
-	GetAllUser(bearerToken string) utils.Response
-	GetUserById(bearerToken string, id uuid.UUID) utils.Response
-	UpdateUser(bearerToken string, id uuid.UUID, updateUserRequest utils.UserRequest) utils.Response
-	DeleteUser(bearerToken string, id uuid.UUID) utils.Response
+	RegisterGym(bearerToken string, requestMeta utils.RequestMeta, registGymRequest utils.GymRequest, photoRequest utils.UploadedPhoto) (utils.Response, error)
+	RegisterFranchise(bearerToken string, requestMeta utils.RequestMeta, registFranchiseRequest utils.FranchiseRequest) (utils.Response, error)
+	RegisterMakanan(bearerToken string, requestMeta utils.RequestMeta, registMakananRequest utils.MakananRequest) (utils.Response, error)
+	UpdateMakananPhoto(bearerToken string, requestMeta utils.RequestMeta, idMakanan string, photoRequest utils.UploadedPhoto) (utils.Response, error)
+	TranslateMakanan(bearerToken string, requestMeta utils.RequestMeta, translationRequest utils.MakananTranslationRequest) (utils.Response, error)
+	AttachFranchiseMakanan(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID, idMakanan string) (utils.Response, error)
+	DetachFranchiseMakanan(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID, idMakanan string) (utils.Response, error)
+	RegisterUser(bearerToken string, requestMeta utils.RequestMeta, registerUserRequest utils.UserRequest, photoRequest utils.UploadedPhoto) (utils.Response, error)
+	GenerateGymToken(bearerToken string, requestMeta utils.RequestMeta, kodeGymRequest utils.KodeGymRequest) (utils.Response, error)
+	GetAllKodeGym(bearerToken string, idGym uuid.UUID) (utils.Response, error)
+	RevokeKodeGym(bearerToken string, requestMeta utils.RequestMeta, idKodeGym uuid.UUID) (utils.Response, error)
+	AssignGymOwner(bearerToken string, requestMeta utils.RequestMeta, idUser uuid.UUID, idGym uuid.UUID) (utils.Response, error)
+	GetAllUser(bearerToken string, filter utils.UserFilter) (utils.Response, error)
+	BulkUpdateUsers(bearerToken string, requestMeta utils.RequestMeta, bulkRequest utils.BulkUserRequest) (utils.Response, error)
+	GetUserById(bearerToken string, id uuid.UUID) (utils.Response, error)
+	UpdateUser(bearerToken string, requestMeta utils.RequestMeta, id uuid.UUID, updateUserRequest utils.UserRequest) (utils.Response, error)
+	DeleteUser(bearerToken string, requestMeta utils.RequestMeta, id uuid.UUID) (utils.Response, error)
+	RestoreUser(bearerToken string, requestMeta utils.RequestMeta, id uuid.UUID) (utils.Response, error)
+	EraseUser(bearerToken string, requestMeta utils.RequestMeta, id uuid.UUID) (utils.Response, error)
+	GetAllGym(bearerToken string) (utils.Response, error)
+	GetGymById(bearerToken string, idGym uuid.UUID) (utils.Response, error)
+	UpdateGym(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, gymRequest utils.GymRequest) (utils.Response, error)
+	UpdateGymPhoto(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, photoRequest utils.UploadedPhoto) (utils.Response, error)
+	SetGymActive(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, active bool) (utils.Response, error)
+	DeleteGym(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID) (utils.Response, error)
+	GetGymMembers(bearerToken string, idGym uuid.UUID) (utils.Response, error)
+	GetAllFranchise(bearerToken string) (utils.Response, error)
+	GetFranchiseById(bearerToken string, idFranchise uuid.UUID) (utils.Response, error)
+	UpdateFranchise(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID, franchiseRequest utils.FranchiseRequest) (utils.Response, error)
+	UpdateFranchisePhoto(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID, photoRequest utils.UploadedPhoto) (utils.Response, error)
+	DeleteFranchise(bearerToken string, requestMeta utils.RequestMeta, idFranchise uuid.UUID) (utils.Response, error)
+	GetAuditLogs(bearerToken string, filter utils.AuditLogFilter) (utils.Response, error)
 }
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"kalorize-api/app/models"
+	"kalorize-api/app/repositories"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audited entity types.
+const (
+	auditEntityUser             = "user"
+	auditEntityGym              = "gym"
+	auditEntityKodeGym          = "kode_gym"
+	auditEntityGymOwner         = "gym_owner"
+	auditEntityFranchise        = "franchise"
+	auditEntityFranchiseMakanan = "franchise_makanan"
+	auditEntityMakanan          = "makanan"
+	auditEntityMembership       = "membership"
+)
+
+// recordAudit appends an entry for a change to the audit log. before and
+// after are the entity as returned by the API, so secrets such as password
+// hashes never reach the log; pass nil for the side that does not exist.
+// The change has already happened, so a failure to record it is logged
+// instead of being reported to the caller.
+func recordAudit(auditRepo repositories.AuditLogRepository, actor models.User, requestMeta utils.RequestMeta, action string, entityType string, entityId string, before interface{}, after interface{}) {
+	beforeFields, afterFields, err := auditDiff(before, after)
+	if err == nil {
+		err = auditRepo.CreateAuditLog(models.AuditLog{
+			IdAudit:    uuid.New(),
+			IdActor:    actor.IdUser,
+			ActorRole:  actor.Role,
+			Action:     action,
+			EntityType: entityType,
+			EntityId:   entityId,
+			Before:     beforeFields,
+			After:      afterFields,
+			IpAddress:  requestMeta.IpAddress,
+			UserAgent:  requestMeta.UserAgent,
+			Method:     requestMeta.Method,
+			Path:       requestMeta.Path,
+			CreatedAt:  time.Now(),
+		})
+	}
+	if err != nil {
+		slog.Error("recording audit log failed", "action", action, "entity", entityType, "entity_id", entityId, "actor", actor.IdUser, "error", err)
+	}
+}
+
+// auditDiff returns the JSON of the fields that differ between before and
+// after. When one side is nil the other is returned whole.
+func auditDiff(before interface{}, after interface{}) (string, string, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return "", "", err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return "", "", err
+	}
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if reflect.DeepEqual(value, afterFields[key]) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+	}
+	beforeJSON, err := auditJSON(beforeFields)
+	if err != nil {
+		return "", "", err
+	}
+	afterJSON, err := auditJSON(afterFields)
+	return beforeJSON, afterJSON, err
+}
+
+func auditFields(entity interface{}) (map[string]interface{}, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+func auditJSON(fields map[string]interface{}) (string, error) {
+	if fields == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(fields)
+	return string(data), err
+}
+
+var auditFingerprintKey []byte
+
+// SetAuditFingerprintKey replaces the key of the fingerprints kept in the
+// audit log. It is called once on startup with the configured key.
+func SetAuditFingerprintKey(key string) {
+	auditFingerprintKey = []byte(key)
+}
+
+// auditFingerprint stands in for a value the audit log must not keep. Equal
+// values give equal fingerprints, so changes still show up in the diff. It is
+// keyed so that, without the key, short values such as names and phone
+// numbers cannot be recovered by hashing guesses.
+func auditFingerprint(value string) string {
+	mac := hmac.New(sha256.New, auditFingerprintKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// auditUser is the view of a user kept in the audit log. The log outlives
+// account erasure, so personal fields are only recorded as fingerprints.
+func auditUser(user models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id_user":              user.IdUser,
+		"role":                 user.Role,
+		"deactivated_at":       user.DeactivatedAt,
+		"erasure_scheduled_at": user.ErasureScheduledAt,
+		"fullname":             auditFingerprint(user.Fullname),
+		"email":                auditFingerprint(user.Email),
+		"no_telepon":           auditFingerprint(user.NoTelepon),
+		"foto":                 auditFingerprint(user.Foto),
+		"password":             auditFingerprint(user.Password),
+		"profile": auditFingerprint(strconv.Itoa(user.JenisKelamin) + "/" + strconv.Itoa(user.Umur) + "/" +
+			strconv.Itoa(user.BeratBadan) + "/" + strconv.Itoa(user.TinggiBadan) + "/" +
+			strconv.Itoa(user.FrekuensiGym) + "/" + strconv.Itoa(user.TargetKalori)),
+	}
+}
+
+// auditFranchise is the view of a franchise kept in the audit log, with a
+// fingerprint of the password hash so password changes are recorded.
+func auditFranchise(franchise models.Franchise) interface{} {
+	return struct {
+		formatter.FranchiseFormat
+		Password string `json:"password"`
+	}{formatter.FormatterFranchise(franchise), auditFingerprint(franchise.PasswordFranchise)}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"kalorize-api/app/models"
+	"kalorize-api/app/storage"
+	"kalorize-api/config"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testPhotoFile is an uploaded file held in memory.
+type testPhotoFile struct {
+	*bytes.Reader
+}
+
+func (testPhotoFile) Close() error {
+	return nil
+}
+
+func newTestPhoto(t *testing.T) utils.UploadedPhoto {
+	t.Helper()
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return utils.UploadedPhoto{File: testPhotoFile{bytes.NewReader(photo.Bytes())}}
+}
+
+// TestAdminActionsAreAudited runs the admin actions one after the other and
+// checks that each leaves an entry naming the admin, the entity and the
+// request. SQLite does not enforce column lengths, so the entity id is
+// checked against the declared size, which MySQL and PostgreSQL enforce.
+func TestAdminActionsAreAudited(t *testing.T) {
+	db := newTestDB(t)
+	fileStorage, err := storage.NewLocalStorage(config.StorageConfig{Driver: "local", LocalPath: t.TempDir(), SigningKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewAdminService(db, fileStorage)
+	admin, token := newTestUser(t, db, "admin")
+	member, _ := newTestUser(t, db, "user")
+	requestMeta := utils.RequestMeta{IpAddress: "192.0.2.1", UserAgent: "test", Method: "POST", Path: "/api/v1/admin"}
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(&models.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+	entityIdSize := statement.Schema.LookUpField("EntityId").Size
+
+	var gym models.Gym
+	var kodeGym models.KodeGym
+	var franchise formatter.FranchiseFormat
+	var makanan models.Makanan
+	var user formatter.UserFormat
+	steps := []struct {
+		action string
+		run    func() (string, error)
+	}{
+		{"gym.create", func() (string, error) {
+			response, err := service.RegisterGym(token, requestMeta, utils.GymRequest{NamaGym: "Gym", Latitude: -6.2, Longitude: 106.8}, newTestPhoto(t))
+			if err == nil {
+				gym = response.Data.(models.Gym)
+			}
+			return gym.IdGym.String(), err
+		}},
+		{"gym.update", func() (string, error) {
+			_, err := service.UpdateGym(token, requestMeta, gym.IdGym, utils.GymRequest{NamaGym: "Gym Baru", Latitude: -6.2, Longitude: 106.8})
+			return gym.IdGym.String(), err
+		}},
+		{"gym.update_photo", func() (string, error) {
+			_, err := service.UpdateGymPhoto(token, requestMeta, gym.IdGym, newTestPhoto(t))
+			return gym.IdGym.String(), err
+		}},
+		{"gym.deactivate", func() (string, error) {
+			_, err := service.SetGymActive(token, requestMeta, gym.IdGym, false)
+			return gym.IdGym.String(), err
+		}},
+		{"gym.activate", func() (string, error) {
+			_, err := service.SetGymActive(token, requestMeta, gym.IdGym, true)
+			return gym.IdGym.String(), err
+		}},
+		{"kode_gym.create", func() (string, error) {
+			response, err := service.GenerateGymToken(token, requestMeta, utils.KodeGymRequest{IdGym: gym.IdGym})
+			if err == nil {
+				kodeGym = response.Data.(models.KodeGym)
+			}
+			return kodeGym.IdKodeGym.String(), err
+		}},
+		{"kode_gym.revoke", func() (string, error) {
+			_, err := service.RevokeKodeGym(token, requestMeta, kodeGym.IdKodeGym)
+			return kodeGym.IdKodeGym.String(), err
+		}},
+		{"gym_owner.assign", func() (string, error) {
+			_, err := service.AssignGymOwner(token, requestMeta, member.IdUser, gym.IdGym)
+			return member.IdUser.String() + "/" + gym.IdGym.String(), err
+		}},
+		{"franchise.create", func() (string, error) {
+			response, err := service.RegisterFranchise(token, requestMeta, utils.FranchiseRequest{NamaFranchise: "Warung", EmailFranchise: "warung@t.io", PasswordFranchise: "secret123", LatitudeFranchise: -6.2, LongitudeFranchise: 106.8})
+			if err == nil {
+				franchise = response.Data.(formatter.FranchiseFormat)
+			}
+			return franchise.IdFranchise.String(), err
+		}},
+		{"franchise.update", func() (string, error) {
+			_, err := service.UpdateFranchise(token, requestMeta, franchise.IdFranchise, utils.FranchiseRequest{NamaFranchise: "Warung Baru", LatitudeFranchise: -6.2, LongitudeFranchise: 106.8})
+			return franchise.IdFranchise.String(), err
+		}},
+		{"franchise.update_photo", func() (string, error) {
+			_, err := service.UpdateFranchisePhoto(token, requestMeta, franchise.IdFranchise, newTestPhoto(t))
+			return franchise.IdFranchise.String(), err
+		}},
+		{"makanan.create", func() (string, error) {
+			response, err := service.RegisterMakanan(token, requestMeta, utils.MakananRequest{Nama: "Nasi Goreng", Kalori: 500, Protein: 20})
+			if err == nil {
+				makanan = response.Data.(models.Makanan)
+			}
+			return makanan.IdMakanan, err
+		}},
+		{"makanan.update_photo", func() (string, error) {
+			_, err := service.UpdateMakananPhoto(token, requestMeta, makanan.IdMakanan, newTestPhoto(t))
+			return makanan.IdMakanan, err
+		}},
+		{"makanan.translate", func() (string, error) {
+			_, err := service.TranslateMakanan(token, requestMeta, utils.MakananTranslationRequest{IdMakanan: makanan.IdMakanan, Locale: "en", Nama: "Fried rice", Bahan: []string{"rice"}, CookingStep: []string{"fry"}})
+			return makanan.IdMakanan, err
+		}},
+		{"franchise_makanan.attach", func() (string, error) {
+			response, err := service.AttachFranchiseMakanan(token, requestMeta, franchise.IdFranchise, makanan.IdMakanan)
+			if err != nil {
+				return "", err
+			}
+			return response.Data.(models.FranchiseMakanan).IdFranchiseMakanan.String(), nil
+		}},
+		{"franchise_makanan.detach", func() (string, error) {
+			_, err := service.DetachFranchiseMakanan(token, requestMeta, franchise.IdFranchise, makanan.IdMakanan)
+			return franchise.IdFranchise.String() + "/" + makanan.IdMakanan, err
+		}},
+		{"user.create", func() (string, error) {
+			response, err := service.RegisterUser(token, requestMeta, utils.UserRequest{Fullname: "Siti", Email: "siti@t.io", Password: "secret123", PasswordConfirmation: "secret123"}, newTestPhoto(t))
+			if err == nil {
+				user = response.Data.(formatter.UserFormat)
+			}
+			return user.IdUser.String(), err
+		}},
+		{"user.update", func() (string, error) {
+			_, err := service.UpdateUser(token, requestMeta, user.IdUser, utils.UserRequest{Fullname: "Siti Aminah"})
+			return user.IdUser.String(), err
+		}},
+		{"user.bulk_deactivate", func() (string, error) {
+			_, err := service.BulkUpdateUsers(token, requestMeta, utils.BulkUserRequest{IdUsers: []uuid.UUID{user.IdUser}, Action: utils.BulkUserDeactivate})
+			return user.IdUser.String(), err
+		}},
+		{"user.delete", func() (string, error) {
+			_, err := service.DeleteUser(token, requestMeta, user.IdUser)
+			return user.IdUser.String(), err
+		}},
+		{"user.restore", func() (string, error) {
+			_, err := service.RestoreUser(token, requestMeta, user.IdUser)
+			return user.IdUser.String(), err
+		}},
+		{"user.erase", func() (string, error) {
+			_, err := service.EraseUser(token, requestMeta, user.IdUser)
+			return user.IdUser.String(), err
+		}},
+		{"franchise.delete", func() (string, error) {
+			_, err := service.DeleteFranchise(token, requestMeta, franchise.IdFranchise)
+			return franchise.IdFranchise.String(), err
+		}},
+		{"gym.delete", func() (string, error) {
+			_, err := service.DeleteGym(token, requestMeta, gym.IdGym)
+			return gym.IdGym.String(), err
+		}},
+	}
+	for _, step := range steps {
+		entityId, err := step.run()
+		if err != nil {
+			t.Fatalf("%s: %v", step.action, err)
+		}
+		var auditLogs []models.AuditLog
+		if err := db.Where("action = ?", step.action).Find(&auditLogs).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(auditLogs) != 1 {
+			t.Errorf("%s: %d audit entries, want 1", step.action, len(auditLogs))
+			continue
+		}
+		auditLog := auditLogs[0]
+		if auditLog.EntityId != entityId || auditLog.IdActor != admin.IdUser || auditLog.ActorRole != "admin" || auditLog.IpAddress != requestMeta.IpAddress || auditLog.Path != requestMeta.Path {
+			t.Errorf("%s: entry %+v, want entity %s recorded for the admin and the request", step.action, auditLog, entityId)
+		}
+		if len(auditLog.EntityId) > entityIdSize {
+			t.Errorf("%s: entity id %q is longer than the %d characters of the column", step.action, auditLog.EntityId, entityIdSize)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"kalorize-api/app/metrics"
 	"kalorize-api/app/models"
 	"kalorize-api/app/repositories"
+	"kalorize-api/formatter"
 	"kalorize-api/utils"
 	"strings"
 	"time"
@@ -13,54 +15,58 @@ import (
 )
 
 type authService struct {
-	authRepo     repositories.UserRepository
-	usedCodeRepo repositories.UsedCodeRepository
-	tokenRepo    repositories.TokenRepository
-	gymRepo      repositories.GymRepository
+	db             *gorm.DB
+	authRepo       repositories.UserRepository
+	usedCodeRepo   repositories.UsedCodeRepository
+	kodeGymRepo    repositories.KodeGymRepository
+	membershipRepo repositories.MembershipRepository
+	tokenRepo      repositories.TokenRepository
+	gymRepo        repositories.GymRepository
 }
 
-func (service *authService) Login(email, password string) utils.Response {
+func (service *authService) Login(email, password string) (utils.Response, error) {
+	response, err := service.login(email, password)
+	metrics.RecordLogin(metrics.AccountUser, err)
+	return response, err
+}
+
+func (service *authService) login(email, password string) (utils.Response, error) {
 	var response utils.Response
 	if email == "" || password == "" {
-		response.StatusCode = 400
-		response.Messages = "email dan password tidak boleh kosong"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Invalid("credentials_required")
 	}
 
 	if !utils.IsEmailValid(email) {
-		response.StatusCode = 400
-		response.Messages = "Email kamu tidak valid"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Invalid("email_invalid")
 	}
 
-	user, err := service.authRepo.GetUserByEmail(email)
+	user, err := service.authRepo.GetUserByEmailIncludingDeleted(email)
 	if err != nil {
-		response.StatusCode = 401
-		response.Messages = "Email kamu belum terdaftar"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Unauthorized("email_not_registered")
 	}
 	if !utils.CheckPasswordHash(password, user.Password) {
-		response.StatusCode = 401
-		response.Messages = "Password kamu salah"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Unauthorized("wrong_password")
+	}
+	if user.DeletedAt.Valid {
+		// Users who deleted their own account get it back by logging in
+		// before it is erased; accounts deleted by an admin stay deleted.
+		if user.ErasureScheduledAt == nil || !user.Restorable() {
+			return utils.Response{}, utils.Forbidden("account_deleted")
+		}
+		if err := service.authRepo.RestoreUser(user.IdUser); err != nil {
+			return utils.Response{}, utils.Internal("Failed to restore account", err)
+		}
+	}
+	if !user.Active() {
+		return utils.Response{}, utils.Forbidden("account_deactivated")
 	}
-	AccessToken, err := utils.GenerateJWTAccessToken(user.IdUser, user.Fullname, user.Email, "kalorize")
+	AccessToken, err := utils.GenerateJWTAccessToken(user.IdUser, user.Fullname, user.Email, utils.JWTSecret())
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token generation failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token generation failed", err)
 	}
-	refreshToken, err := utils.GenerateJWTRefreshToken(user.IdUser, user.Fullname, user.Email, "kalorize")
+	refreshToken, err := utils.GenerateJWTRefreshToken(user.IdUser, user.Fullname, user.Email, utils.JWTSecret())
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token generation failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token generation failed", err)
 	}
 	token := models.Token{
 		IdToken:      uuid.New(),
@@ -70,75 +76,54 @@ func (service *authService) Login(email, password string) utils.Response {
 	}
 	err = service.tokenRepo.CreateNewToken(token)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token creation failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token creation failed", err)
 	}
 
 	response.StatusCode = 200
 	response.Messages = "success"
-	response.Data = map[string]interface{}{
-		"accessToken":  AccessToken,
-		"refreshToken": refreshToken,
-		"role":         user.Role,
-		"userId":       user.IdUser,
+	response.Data = formatter.TokenFormat{
+		AccessToken:  AccessToken,
+		RefreshToken: refreshToken,
+		Role:         user.Role,
+		UserId:       user.IdUser,
 	}
-	return response
+	return response, nil
 }
 
-func (service *authService) Register(registerRequest utils.UserRequest, gymKode string) utils.Response {
+func (service *authService) Register(registerRequest utils.UserRequest, gymKode string) (utils.Response, error) {
+	if err := utils.Validate(registerRequest); err != nil {
+		return utils.Response{}, err
+	}
 	var response utils.Response
 	if registerRequest.Fullname == "" || registerRequest.Email == "" || registerRequest.Password == "" || registerRequest.PasswordConfirmation == "" {
-		response.StatusCode = 400
-		response.Messages = "Semua field harus diisi"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Invalid("fields_required")
 	}
 	if !utils.IsEmailValid(registerRequest.Email) {
-		response.StatusCode = 400
-		response.Messages = "Email kamu tidak valid"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Invalid("email_invalid")
 	}
 
-	user, err := service.authRepo.GetUserByEmail(registerRequest.Email)
+	user, err := service.authRepo.GetUserByEmailIncludingDeleted(registerRequest.Email)
 	if err == nil {
-		response.StatusCode = 400
-		response.Messages = "Email sudah terdaftar"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Conflict("email_taken")
 	}
 
 	if registerRequest.Password != registerRequest.PasswordConfirmation {
-		response.StatusCode = 400
-		response.Messages = "Password dan konfirmasi password tidak sama"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Invalid("password_mismatch")
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerRequest.Password), bcrypt.DefaultCost)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Password hashing failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Password hashing failed", err)
 	}
 	userId := uuid.New()
-	accessToken, err := utils.GenerateJWTAccessToken(userId, registerRequest.Fullname, registerRequest.Email, "kalorize")
+	accessToken, err := utils.GenerateJWTAccessToken(userId, registerRequest.Fullname, registerRequest.Email, utils.JWTSecret())
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token generation failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token generation failed", err)
 	}
 
-	refreshtoken, err := utils.GenerateJWTRefreshToken(userId, registerRequest.Fullname, registerRequest.Email, "kalorize")
+	refreshtoken, err := utils.GenerateJWTRefreshToken(userId, registerRequest.Fullname, registerRequest.Email, utils.JWTSecret())
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token generation failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token generation failed", err)
 	}
 
 	user = models.User{
@@ -148,84 +133,48 @@ func (service *authService) Register(registerRequest utils.UserRequest, gymKode
 		Umur:        registerRequest.Umur,
 		ReferalCode: utils.GenerateReferalCode(registerRequest.Fullname),
 		Password:    string(hashedPassword),
-		Role:        registerRequest.Role,
+		Role:        "user", // roles other than user are granted by an admin
 	}
 
-	gyms, err := service.gymRepo.GetGym()
+	kodeGym, err := service.kodeGymRepo.GetKodeGymByKode(gymKode)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Gym tidak ditemukan"
-		response.Data = nil
-		return response
-	}
-
-	gymExist := false
-
-	for _, gym := range gyms {
-		if utils.CheckGymLikeness(gym.NamaGym, gymKode) {
-			gymExist = true
-		}
+		return utils.Response{}, utils.Invalid("kode_gym_invalid")
 	}
-
-	if !gymExist {
-		response.StatusCode = 500
-		response.Messages = "Code Gym tidak sesuai"
-		response.Data = nil
-		return response
+	if status := kodeGymStatus(service.gymRepo, kodeGym, time.Now()); status != models.KodeGymActive {
+		return utils.Response{}, kodeGymUnavailable(status)
 	}
 
-	if registerRequest.Role != "admin" {
-		gym, err := service.gymRepo.GetGymByGymName(utils.GetAlphabetFromCode(gymKode))
-		if err != nil {
-			response.StatusCode = 500
-			response.Messages = "Gym tidak ditemukan"
-			response.Data = nil
-			return response
-		}
-
-		usedCode := models.UsedCode{
-			IdGym:     gym.IdGym,
-			KodeGym:   gymKode,
-			IdUser:    user.IdUser,
-			ExpiredAt: utils.GetExpiredTimeGym(),
+	err = service.db.Transaction(func(tx *gorm.DB) error {
+		if err := repositories.NewDBUserRepository(tx).CreateNewUser(user); err != nil {
+			return utils.Internal("User creation failed", err)
 		}
-		err = service.usedCodeRepo.CreateNewUsedCode(usedCode)
-		if err != nil {
-			response.StatusCode = 500
-			response.Messages = "Used code creation failed"
-			response.Data = user.IdUser
-			return response
+		if _, err := startMembership(tx, kodeGym, user.IdUser, nil); err != nil {
+			return membershipFailed(err)
 		}
-	}
-	err = service.authRepo.CreateNewUser(user)
+		return nil
+	})
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "User creation failed"
-		response.Data = user.IdUser
-		return response
+		return utils.Response{}, err
 	}
 	response.StatusCode = 200
 	response.Messages = "success"
-	response.Data = map[string]interface{}{
-		"accessToken":  accessToken,
-		"refreshToken": refreshtoken,
-		"role":         user.Role,
-		"userId":       user.IdUser,
+	response.Data = formatter.TokenFormat{
+		AccessToken:  accessToken,
+		RefreshToken: refreshtoken,
+		Role:         user.Role,
+		UserId:       user.IdUser,
 	}
-	return response
+	return response, nil
 }
 
-func (service *authService) GetLoggedInUser(bearerToken string) utils.Response {
+func (service *authService) GetLoggedInUser(bearerToken string) (utils.Response, error) {
 	var response utils.Response
 	var firstname, lastname string
 	id, err := utils.ParseDataId(bearerToken)
 	if id != uuid.Nil && err == nil {
 		user, err := service.authRepo.GetUserById(id)
 		if err != nil {
-			response.StatusCode = 500
-			response.Messages = "User tidak ditemukan"
-			response.Data = nil
-			return response
+			return utils.Response{}, utils.NotFound("user_not_found")
 		}
 		names := strings.Split(user.Fullname, " ")
 		if len(names) == 1 {
@@ -235,123 +184,93 @@ func (service *authService) GetLoggedInUser(bearerToken string) utils.Response {
 			firstname = names[0]
 			lastname = names[len(names)-1]
 		}
+		loggedInUser := formatter.LoggedInUserFormat{
+			IdUser:        user.IdUser,
+			FirstName:     firstname,
+			LastName:      lastname,
+			Email:         user.Email,
+			JenisKelamin:  user.JenisKelamin,
+			Umur:          user.Umur,
+			BeratBadan:    user.BeratBadan,
+			Role:          user.Role,
+			Foto:          user.FotoUrl,
+			FotoMedium:    user.FotoMediumUrl,
+			FotoThumbnail: user.FotoThumbnailUrl,
+			NoTelepon:     user.NoTelepon,
+		}
 		if user.Role != "admin" {
-			KodeGym, err := service.usedCodeRepo.GetusedCodeByIdUser(user.IdUser)
+			memberships, err := service.membershipRepo.GetMembershipsByIdUser(user.IdUser)
 			if err != nil {
-				response.StatusCode = 500
-				response.Messages = "Kode gym tidak ditemukan"
-				response.Data = nil
-				return response
-			}
-
-			if KodeGym.ExpiredAt.Before(time.Now()) {
-				response.StatusCode = 500
-				response.Messages = "Kode gym sudah expired"
-				response.Data = nil
-				return response
+				return utils.Response{}, utils.Internal("Failed to get membership", err)
 			}
 
-			Gym, err := service.gymRepo.GetGymById(KodeGym.IdGym)
-			if err != nil {
-				response.StatusCode = 500
-				response.Messages = "Gym tidak ditemukan"
-				response.Data = nil
-				return response
-			}
-			response.Data = map[string]interface{}{
-				"idUser":       user.IdUser,
-				"firstName":    firstname,
-				"lastName":     lastname,
-				"email":        user.Email,
-				"jenisKelamin": user.JenisKelamin,
-				"frekuensiGym": user.FrekuensiGym,
-				"targetKalori": user.TargetKalori,
-				"tinggiBadan":  user.TinggiBadan,
-				"umur":         user.Umur,
-				"beratBadan":   user.BeratBadan,
-				"role":         user.Role,
-				"foto":         user.FotoUrl,
-				"noTelepon":    user.NoTelepon,
-				"KodeGym":      KodeGym.KodeGym,
-				"Gym":          Gym.NamaGym,
-			}
-		} else {
-			response.Data = map[string]interface{}{
-				"idUser":       user.IdUser,
-				"firstName":    firstname,
-				"lastName":     lastname,
-				"email":        user.Email,
-				"jenisKelamin": user.JenisKelamin,
-				"umur":         user.Umur,
-				"beratBadan":   user.BeratBadan,
-				"role":         user.Role,
-				"foto":         user.FotoUrl,
-				"noTelepon":    user.NoTelepon,
+			// A lapsed membership no longer locks members out of their
+			// profile; its status is reported instead.
+			var kodeGym, namaGym string
+			var membership *formatter.MembershipFormat
+			if current := currentMembership(memberships, time.Now()); current != nil {
+				Gym, err := service.gymRepo.GetGymByIdIncludingDeleted(current.IdGym)
+				if err != nil {
+					return utils.Response{}, utils.NotFound("gym_not_found")
+				}
+				kodeGym = current.KodeGym
+				namaGym = Gym.NamaGym
+				membershipFormatted := formatter.FormatterMembership(*current, time.Now())
+				membership = &membershipFormatted
 			}
+			loggedInUser.FrekuensiGym = &user.FrekuensiGym
+			loggedInUser.TargetKalori = &user.TargetKalori
+			loggedInUser.TinggiBadan = &user.TinggiBadan
+			loggedInUser.KodeGym = &kodeGym
+			loggedInUser.Gym = &namaGym
+			loggedInUser.Membership = membership
 		}
+		response.Data = loggedInUser
 		response.StatusCode = 200
 		response.Messages = "success"
-		return response
+		return response, nil
 	} else {
-		response.StatusCode = 401
-		response.Messages = "Invalid token"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Unauthorized("invalid_token")
 	}
 }
 
-func (service *authService) Logout(bearerToken string) utils.Response {
+func (service *authService) Logout(bearerToken string) (utils.Response, error) {
 	var response utils.Response
 	_, err := utils.ParseDataEmail(bearerToken)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token parsing failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token parsing failed", err)
 	}
 
 	err = service.tokenRepo.DeleteToken(bearerToken)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token deletion failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token deletion failed", err)
 	}
 	response.StatusCode = 200
 	response.Messages = "success"
 	response.Data = nil
-	return response
+	return response, nil
 }
 
-func (service *authService) Refresh(refreshToken string) utils.Response {
+func (service *authService) Refresh(refreshToken string) (utils.Response, error) {
 	var response utils.Response
 	userId, err := utils.ParseDataId(refreshToken)
 	if userId == uuid.Nil || err != nil {
-		response.StatusCode = 401
-		response.Messages = "Invalid token"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Unauthorized("invalid_token")
 	}
 	user, err := service.authRepo.GetUserById(userId)
 	if err != nil {
-		response.StatusCode = 401
-		response.Messages = "User tidak ditemukan"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Unauthorized("user_not_found")
 	}
-	AccessToken, err := utils.GenerateJWTAccessToken(user.IdUser, user.Fullname, user.Email, "kalorize")
+	if !user.Active() {
+		return utils.Response{}, utils.Forbidden("account_deactivated")
+	}
+	AccessToken, err := utils.GenerateJWTAccessToken(user.IdUser, user.Fullname, user.Email, utils.JWTSecret())
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token generation failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token generation failed", err)
 	}
-	refreshToken, err = utils.GenerateJWTRefreshToken(user.IdUser, user.Fullname, user.Email, "kalorize")
+	refreshToken, err = utils.GenerateJWTRefreshToken(user.IdUser, user.Fullname, user.Email, utils.JWTSecret())
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token generation failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token generation failed", err)
 	}
 	token := models.Token{
 		IdToken:      uuid.New(),
@@ -361,35 +280,35 @@ func (service *authService) Refresh(refreshToken string) utils.Response {
 	}
 	err = service.tokenRepo.CreateNewToken(token)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Token creation failed"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Token creation failed", err)
 	}
 	response.StatusCode = 200
 	response.Messages = "success"
-	response.Data = map[string]interface{}{
-		"accessToken":  AccessToken,
-		"refreshToken": refreshToken,
-		"role":         user.Role,
-		"userId":       user.IdUser,
+	response.Data = formatter.TokenFormat{
+		AccessToken:  AccessToken,
+		RefreshToken: refreshToken,
+		Role:         user.Role,
+		UserId:       user.IdUser,
 	}
-	return response
+	return response, nil
 }
 
 type AuthService interface {
-	Login(username, password string) utils.Response
-	Register(requestRegister utils.UserRequest, gymKode string) utils.Response
-	GetLoggedInUser(bearerToken string) utils.Response
-	Logout(bearerToken string) utils.Response
-	Refresh(refreshToken string) utils.Response
+	Login(username, password string) (utils.Response, error)
+	Register(requestRegister utils.UserRequest, gymKode string) (utils.Response, error)
+	GetLoggedInUser(bearerToken string) (utils.Response, error)
+	Logout(bearerToken string) (utils.Response, error)
+	Refresh(refreshToken string) (utils.Response, error)
 }
 
 func NewAuthService(db *gorm.DB) AuthService {
 	return &authService{
-		authRepo:     repositories.NewDBUserRepository(db),
-		usedCodeRepo: repositories.NewDBUsedCodeRepository(db),
-		tokenRepo:    repositories.NewDBTokenRepository(db),
-		gymRepo:      repositories.NewDBGymRepository(db),
+		db:             db,
+		authRepo:       repositories.NewDBUserRepository(db),
+		usedCodeRepo:   repositories.NewDBUsedCodeRepository(db),
+		kodeGymRepo:    repositories.NewDBKodeGymRepository(db),
+		membershipRepo: repositories.NewDBMembershipRepository(db),
+		tokenRepo:      repositories.NewDBTokenRepository(db),
+		gymRepo:        repositories.NewDBGymRepository(db),
 	}
 }
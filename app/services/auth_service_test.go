@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/app/repositories"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	db := newTestDB(t)
+	kodeRepo := repositories.NewDBKodeGymRepository(db)
+	gym := newTestGym(t, db, true)
+	inactiveGym := newTestGym(t, db, false)
+	nextWeek := time.Now().AddDate(0, 0, 7)
+	code := func(gym models.Gym, request utils.KodeGymRequest) string {
+		kodeGym, err := newKodeGym(kodeRepo, gym, request, nextWeek)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return kodeGym.KodeGym
+	}
+	multiUse := code(gym, utils.KodeGymRequest{Mode: models.KodeGymMultiUse})
+	usedUp := code(gym, utils.KodeGymRequest{})
+	expired := code(gym, utils.KodeGymRequest{Mode: models.KodeGymMultiUse})
+	revoked := code(gym, utils.KodeGymRequest{Mode: models.KodeGymMultiUse})
+	closedGym := code(inactiveGym, utils.KodeGymRequest{Mode: models.KodeGymMultiUse})
+
+	expiredKode, _ := kodeRepo.GetKodeGymByKode(expired)
+	expiredKode.ExpiredTime = time.Now().Add(-time.Minute)
+	if err := kodeRepo.UpdateKodeGym(expiredKode); err != nil {
+		t.Fatal(err)
+	}
+	revokedKode, _ := kodeRepo.GetKodeGymByKode(revoked)
+	if _, err := revokeKodeGym(kodeRepo, revokedKode); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewAuthService(db)
+	request := func(email string) utils.UserRequest {
+		return utils.UserRequest{Fullname: "Budi", Email: email, Password: "secret1", PasswordConfirmation: "secret1", Role: "admin"}
+	}
+	if _, err := service.Register(request("first@t.io"), usedUp); err != nil {
+		t.Fatalf("registering with a fresh single use code: %v", err)
+	}
+
+	mismatch := request("mismatch@t.io")
+	mismatch.PasswordConfirmation = "secret2"
+	tests := []struct {
+		name    string
+		request utils.UserRequest
+		kode    string
+		wantErr string
+	}{
+		{name: "registered", request: request("new@t.io"), kode: multiUse},
+		{name: "missing fields", request: utils.UserRequest{Email: "empty@t.io"}, kode: multiUse, wantErr: "fields_required"},
+		{name: "malformed email", request: request("not-an-email"), kode: multiUse, wantErr: "validation_failed"},
+		{name: "email taken", request: request("first@t.io"), kode: multiUse, wantErr: "email_taken"},
+		{name: "passwords differ", request: mismatch, kode: multiUse, wantErr: "password_mismatch"},
+		{name: "unknown code", request: request("unknown@t.io"), kode: "nope", wantErr: "kode_gym_invalid"},
+		{name: "used up code", request: request("used@t.io"), kode: usedUp, wantErr: "kode_gym_exhausted"},
+		{name: "expired code", request: request("expired@t.io"), kode: expired, wantErr: "kode_gym_expired"},
+		{name: "revoked code", request: request("revoked@t.io"), kode: revoked, wantErr: "kode_gym_revoked"},
+		{name: "deactivated gym", request: request("closed@t.io"), kode: closedGym, wantErr: "gym_inactive"},
+	}
+	userRepo := repositories.NewDBUserRepository(db)
+	membershipRepo := repositories.NewDBMembershipRepository(db)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := service.Register(test.request, test.kode)
+			user, lookupErr := userRepo.GetUserByEmail(test.request.Email)
+			if test.wantErr != "" {
+				var serviceErr *utils.Error
+				if !errors.As(err, &serviceErr) || serviceErr.Code != test.wantErr {
+					t.Errorf("Register error = %v, want %s", err, test.wantErr)
+				}
+				if test.wantErr != "email_taken" && lookupErr == nil {
+					t.Errorf("a rejected registration created user %s", user.Email)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Register: %v", err)
+			}
+			if lookupErr != nil {
+				t.Fatalf("user was not saved: %v", lookupErr)
+			}
+			token := response.Data.(formatter.TokenFormat)
+			if token.AccessToken == "" || token.UserId != user.IdUser || user.Role != "user" {
+				t.Errorf("registered %+v with role %s, want tokens for the new user and role user", token, user.Role)
+			}
+			memberships, err := membershipRepo.GetMembershipsByIdUser(user.IdUser)
+			if err != nil || len(memberships) != 1 || memberships[0].KodeGym != test.kode {
+				t.Errorf("memberships = %+v, %v, want one started with %s", memberships, err, test.kode)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// background tracks the work services carry on with after answering a
+// request, such as generating a data export, so shutdown can wait for it.
+var background sync.WaitGroup
+
+func runInBackground(work func()) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		work()
+	}()
+}
+
+// WaitBackground blocks until the background work has finished or ctx is
+// done, in which case it returns the error of ctx.
+func WaitBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitBackground(t *testing.T) {
+	if err := WaitBackground(context.Background()); err != nil {
+		t.Fatalf("WaitBackground without work = %v, want nil", err)
+	}
+
+	release := make(chan struct{})
+	finished := false
+	runInBackground(func() {
+		<-release
+		finished = true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := WaitBackground(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitBackground while work runs = %v, want the deadline", err)
+	}
+
+	close(release)
+	if err := WaitBackground(context.Background()); err != nil || !finished {
+		t.Errorf("WaitBackground = %v, finished %v, want it to return once the work is done", err, finished)
+	}
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"kalorize-api/app/models"
+	"kalorize-api/app/repositories"
+	"kalorize-api/app/storage"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// dataExportRetention is how long a finished export can be downloaded.
+	dataExportRetention = 7 * 24 * time.Hour
+	// dataExportStale is how long a pending export may take before a new
+	// request is allowed, in case the server restarted while generating it.
+	dataExportStale = time.Hour
+)
+
+type DataExportService interface {
+	RequestExport(bearerToken string) (utils.Response, error)
+	GetExport(bearerToken string, idExport uuid.UUID) (utils.Response, error)
+	DownloadExport(bearerToken string, idExport uuid.UUID) (io.ReadCloser, error)
+}
+
+type dataExportService struct {
+	dataExportRepo repositories.DataExportRepository
+	userRepo       repositories.UserRepository
+	historyRepo    repositories.HistoryRepository
+	makananRepo    repositories.MakananRepository
+	gymRepo        repositories.GymRepository
+	membershipRepo repositories.MembershipRepository
+	orderRepo      repositories.OrderRepository
+	tokenRepo      repositories.TokenRepository
+	fileStorage    storage.Storage
+}
+
+func NewDataExportService(db *gorm.DB, fileStorage storage.Storage) DataExportService {
+	return &dataExportService{
+		dataExportRepo: repositories.NewDBDataExportRepository(db),
+		userRepo:       repositories.NewDBUserRepository(db),
+		historyRepo:    repositories.NewDBHistoryRepository(db),
+		makananRepo:    repositories.NewDBMakananRepository(db),
+		gymRepo:        repositories.NewDBGymRepository(db),
+		membershipRepo: repositories.NewDBMembershipRepository(db),
+		orderRepo:      repositories.NewDBOrderRepository(db),
+		tokenRepo:      repositories.NewDBTokenRepository(db),
+		fileStorage:    fileStorage,
+	}
+}
+
+func (service *dataExportService) member(bearerToken string) (models.User, error) {
+	email, err := utils.ParseDataEmail(bearerToken)
+	if email == "" || err != nil {
+		return models.User{}, utils.Unauthorized("unauthorized")
+	}
+	user, err := service.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return user, utils.Unauthorized("unauthorized")
+	}
+	return user, nil
+}
+
+// RequestExport starts generating an export of the user's data. The ZIP is
+// built in the background; the client polls GetExport until it is ready.
+// A request made while an export is still being generated returns that one.
+func (service *dataExportService) RequestExport(bearerToken string) (utils.Response, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	now := time.Now()
+	dataExports, err := service.dataExportRepo.GetDataExportsByIdUser(user.IdUser)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to create data export", err)
+	}
+	if len(dataExports) > 0 && dataExports[0].Status == models.DataExportPending && now.Sub(dataExports[0].CreatedAt) < dataExportStale {
+		return utils.Response{StatusCode: 202, Messages: "export_processing", Data: dataExports[0]}, nil
+	}
+
+	dataExport := models.DataExport{
+		IdExport:  uuid.New(),
+		IdUser:    user.IdUser,
+		Status:    models.DataExportPending,
+		CreatedAt: now,
+	}
+	if err := service.dataExportRepo.CreateDataExport(dataExport); err != nil {
+		return utils.Response{}, utils.Internal("Failed to create data export", err)
+	}
+	runInBackground(func() { service.generate(dataExport, user) })
+	return utils.Response{StatusCode: 202, Messages: "export_processing", Data: dataExport}, nil
+}
+
+func (service *dataExportService) GetExport(bearerToken string, idExport uuid.UUID) (utils.Response, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	dataExport, err := service.dataExportRepo.GetDataExportById(idExport)
+	if err != nil || dataExport.IdUser != user.IdUser {
+		return utils.Response{}, utils.NotFound("export_not_found")
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: dataExport}, nil
+}
+
+// DownloadExport opens the ZIP of a ready export. The caller closes it.
+func (service *dataExportService) DownloadExport(bearerToken string, idExport uuid.UUID) (io.ReadCloser, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return nil, err
+	}
+	dataExport, err := service.dataExportRepo.GetDataExportById(idExport)
+	if err != nil || dataExport.IdUser != user.IdUser {
+		return nil, utils.NotFound("export_not_found")
+	}
+	if !dataExport.Downloadable(time.Now()) {
+		return nil, utils.Conflict("export_not_ready")
+	}
+	reader, err := service.fileStorage.Get(context.Background(), dataExport.FileKey)
+	if err != nil {
+		return nil, utils.Internal("Failed to get data export", err)
+	}
+	return reader, nil
+}
+
+// generate builds and stores the ZIP, then records the outcome on the export.
+func (service *dataExportService) generate(dataExport models.DataExport, user models.User) {
+	archive, err := service.buildArchive(user)
+	if err == nil {
+		dataExport.FileKey = "exports/" + dataExport.IdExport.String() + ".zip"
+		err = service.fileStorage.Put(context.Background(), dataExport.FileKey, bytes.NewReader(archive), storage.PutOptions{ContentType: "application/zip"})
+	}
+	now := time.Now()
+	dataExport.CompletedAt = &now
+	if err != nil {
+		slog.Error("data export failed", "export", dataExport.IdExport, "error", err)
+		dataExport.Status = models.DataExportFailed
+		dataExport.FileKey = ""
+	} else {
+		expiresAt := now.Add(dataExportRetention)
+		dataExport.Status = models.DataExportReady
+		dataExport.Size = int64(len(archive))
+		dataExport.ExpiresAt = &expiresAt
+	}
+	if err := service.dataExportRepo.UpdateDataExport(dataExport); err != nil {
+		slog.Error("saving data export status failed", "export", dataExport.IdExport, "error", err)
+	}
+}
+
+type exportQuestionnaire struct {
+	JenisKelamin int `json:"jenis_kelamin"`
+	Umur         int `json:"umur"`
+	BeratBadan   int `json:"berat_badan"`
+	TinggiBadan  int `json:"tinggi_badan"`
+	FrekuensiGym int `json:"frekuensi_gym"`
+	TargetKalori int `json:"target_kalori"`
+}
+
+type exportMembership struct {
+	formatter.MembershipFormat
+	NamaGym string `json:"nama_gym"`
+}
+
+type exportHistory struct {
+	models.History
+	NamaBreakfast string `json:"nama_breakfast"`
+	NamaLunch     string `json:"nama_lunch"`
+	NamaDinner    string `json:"nama_dinner"`
+}
+
+// exportToken describes a session without the token values themselves.
+type exportToken struct {
+	IdToken               uuid.UUID  `json:"id_token"`
+	AccessTokenExpiresAt  *time.Time `json:"access_token_expires_at"`
+	RefreshTokenExpiresAt *time.Time `json:"refresh_token_expires_at"`
+}
+
+// buildArchive collects everything stored about the user into a ZIP with a
+// JSON file per section and a CSV copy of the tabular ones.
+func (service *dataExportService) buildArchive(user models.User) ([]byte, error) {
+	now := time.Now()
+
+	memberships, err := service.membershipRepo.GetMembershipsByIdUser(user.IdUser)
+	if err != nil {
+		return nil, err
+	}
+	namaGyms := map[uuid.UUID]string{}
+	exportMemberships := make([]exportMembership, 0, len(memberships))
+	for _, membership := range memberships {
+		if _, ok := namaGyms[membership.IdGym]; !ok {
+			gym, _ := service.gymRepo.GetGymByIdIncludingDeleted(membership.IdGym)
+			namaGyms[membership.IdGym] = gym.NamaGym
+		}
+		exportMemberships = append(exportMemberships, exportMembership{
+			MembershipFormat: formatter.FormatterMembership(membership, now),
+			NamaGym:          namaGyms[membership.IdGym],
+		})
+	}
+
+	histories, err := service.historyRepo.GetHistoriesByIdUser(user.IdUser)
+	if err != nil {
+		return nil, err
+	}
+	namaMakanans := map[string]string{"": ""}
+	namaMakanan := func(id string) string {
+		if _, ok := namaMakanans[id]; !ok {
+			makanan, _ := service.makananRepo.GetMakananById(id)
+			namaMakanans[id] = makanan.Nama
+		}
+		return namaMakanans[id]
+	}
+	exportHistories := make([]exportHistory, 0, len(histories))
+	for _, history := range histories {
+		exportHistories = append(exportHistories, exportHistory{
+			History:       history,
+			NamaBreakfast: namaMakanan(history.IdBreakfast),
+			NamaLunch:     namaMakanan(history.IdLunch),
+			NamaDinner:    namaMakanan(history.IdDinner),
+		})
+	}
+
+	orders, err := service.orderRepo.GetOrdersByIdUser(user.IdUser)
+	if err != nil {
+		return nil, err
+	}
+	idOrders := make([]uuid.UUID, 0, len(orders))
+	for _, order := range orders {
+		idOrders = append(idOrders, order.IdOrder)
+	}
+	orderItems, err := service.orderRepo.GetOrderItemsByIdOrders(idOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := service.tokenRepo.GetTokensByUserId(user.IdUser)
+	if err != nil {
+		return nil, err
+	}
+	exportTokens := make([]exportToken, 0, len(tokens))
+	for _, token := range tokens {
+		exportTokens = append(exportTokens, exportToken{
+			IdToken:               token.IdToken,
+			AccessTokenExpiresAt:  utils.TokenExpiry(token.AccessToken),
+			RefreshTokenExpiresAt: utils.TokenExpiry(token.RefreshToken),
+		})
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", formatter.FormatterUser(user)},
+		{"questionnaire.json", exportQuestionnaire{
+			JenisKelamin: user.JenisKelamin,
+			Umur:         user.Umur,
+			BeratBadan:   user.BeratBadan,
+			TinggiBadan:  user.TinggiBadan,
+			FrekuensiGym: user.FrekuensiGym,
+			TargetKalori: user.TargetKalori,
+		}},
+		{"memberships.json", exportMemberships},
+		{"history.json", exportHistories},
+		{"orders.json", formatter.FormatterOrders(orders, orderItems)},
+		{"tokens.json", exportTokens},
+	}
+	for _, file := range files {
+		if err := writeJSONFile(archive, file.name, file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	membershipRows := [][]string{{"id_membership", "nama_gym", "kode_gym", "plan", "start_date", "end_date", "status"}}
+	for _, membership := range exportMemberships {
+		membershipRows = append(membershipRows, []string{
+			membership.IdMembership.String(), membership.NamaGym, membership.KodeGym, membership.Plan,
+			membership.StartDate.Format(time.RFC3339), membership.EndDate.Format(time.RFC3339), membership.Status,
+		})
+	}
+	historyRows := [][]string{{"tanggal", "breakfast", "lunch", "dinner", "total_kalori", "total_protein"}}
+	for _, history := range exportHistories {
+		historyRows = append(historyRows, []string{
+			history.TanggalDibuat.Format("2006-01-02"), history.NamaBreakfast, history.NamaLunch, history.NamaDinner,
+			strconv.Itoa(history.TotalKalori), strconv.Itoa(history.TotalProtein),
+		})
+	}
+	orderRows := [][]string{{"id_order", "id_franchise", "status", "waktu_makan", "total_harga", "total_kalori", "total_protein", "created_at"}}
+	for _, order := range orders {
+		orderRows = append(orderRows, []string{
+			order.IdOrder.String(), order.IdFranchise.String(), order.Status, order.WaktuMakan,
+			strconv.Itoa(order.TotalHarga), strconv.Itoa(order.TotalKalori), strconv.Itoa(order.TotalProtein),
+			order.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	tables := []struct {
+		name string
+		rows [][]string
+	}{
+		{"memberships.csv", membershipRows},
+		{"history.csv", historyRows},
+		{"orders.csv", orderRows},
+	}
+	for _, table := range tables {
+		if err := writeCSVFile(archive, table.name, table.rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func createFile(archive *zip.Writer, name string) (io.Writer, error) {
+	return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+}
+
+func writeJSONFile(archive *zip.Writer, name string, data interface{}) error {
+	file, err := createFile(archive, name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func writeCSVFile(archive *zip.Writer, name string, rows [][]string) error {
+	file, err := createFile(archive, name)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"kalorize-api/app/models"
+	"kalorize-api/app/storage"
+	"kalorize-api/config"
+	"kalorize-api/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDataExport(t *testing.T) {
+	db := newTestDB(t)
+	fileStorage, err := storage.NewLocalStorage(config.StorageConfig{Driver: "local", LocalPath: t.TempDir(), SigningKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewDataExportService(db, fileStorage)
+	user, token := newTestUser(t, db, "user")
+	_, otherToken := newTestUser(t, db, "user")
+	if err := db.Create(&models.Token{IdToken: uuid.New(), UserId: user.IdUser, AccessToken: token}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.History{IdHistory: uuid.New(), IdUser: user.IdUser, TotalKalori: 1800, TanggalDibuat: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+	wantCode := func(t *testing.T, err error, code string) {
+		t.Helper()
+		var serviceErr *utils.Error
+		if !errors.As(err, &serviceErr) || serviceErr.Code != code {
+			t.Errorf("error = %v, want %s", err, code)
+		}
+	}
+
+	response, err := service.RequestExport(token)
+	if err != nil {
+		t.Fatalf("RequestExport: %v", err)
+	}
+	requested := response.Data.(models.DataExport)
+	if response.StatusCode != 202 || requested.Status != models.DataExportPending {
+		t.Fatalf("RequestExport = %d %+v, want 202 and a pending export", response.StatusCode, requested)
+	}
+	if err := WaitBackground(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		response, err := service.GetExport(token, requested.IdExport)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dataExport := response.Data.(models.DataExport); dataExport.Status != models.DataExportReady || dataExport.Size == 0 || dataExport.ExpiresAt == nil {
+			t.Errorf("GetExport = %+v, want a ready export with its size and expiry", dataExport)
+		}
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		reader, err := service.DownloadExport(token, requested.IdExport)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := map[string]string{}
+		for _, file := range archive.File {
+			opened, err := file.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(opened)
+			opened.Close()
+			files[file.Name] = string(data)
+		}
+		for _, name := range []string{"profile.json", "questionnaire.json", "memberships.json", "history.json", "orders.json", "tokens.json", "memberships.csv", "history.csv", "orders.csv"} {
+			if _, ok := files[name]; !ok {
+				t.Errorf("archive has no %s", name)
+			}
+		}
+		if !strings.Contains(files["profile.json"], user.Email) {
+			t.Errorf("profile.json = %s, want the user's email", files["profile.json"])
+		}
+		if !strings.Contains(files["history.csv"], "1800") {
+			t.Errorf("history.csv = %s, want the logged day", files["history.csv"])
+		}
+		if !strings.Contains(files["tokens.json"], "access_token_expires_at") || strings.Contains(files["tokens.json"], token) {
+			t.Errorf("tokens.json = %s, want the session expiry without the token", files["tokens.json"])
+		}
+	})
+
+	t.Run("another user", func(t *testing.T) {
+		_, err := service.GetExport(otherToken, requested.IdExport)
+		wantCode(t, err, "export_not_found")
+		_, err = service.DownloadExport(otherToken, requested.IdExport)
+		wantCode(t, err, "export_not_found")
+	})
+
+	t.Run("pending export", func(t *testing.T) {
+		pending := models.DataExport{IdExport: uuid.New(), IdUser: user.IdUser, Status: models.DataExportPending, CreatedAt: time.Now()}
+		if err := db.Create(&pending).Error; err != nil {
+			t.Fatal(err)
+		}
+		response, err := service.RequestExport(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := response.Data.(models.DataExport); got.IdExport != pending.IdExport {
+			t.Errorf("RequestExport started %s, want the pending %s returned", got.IdExport, pending.IdExport)
+		}
+		_, err = service.DownloadExport(token, pending.IdExport)
+		wantCode(t, err, "export_not_ready")
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		_, err := service.RequestExport("not a token")
+		wantCode(t, err, "unauthorized")
+	})
+}
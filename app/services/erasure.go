@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"kalorize-api/app/models"
+	"kalorize-api/app/repositories"
+	"kalorize-api/app/storage"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// eraseUser anonymises a user and everything that refers to them, then
+// removes their data exports and, unless another user shares it, their
+// profile photo from storage.
+func eraseUser(userRepo repositories.UserRepository, dataExportRepo repositories.DataExportRepository, fileStorage storage.Storage, user models.User, now time.Time) error {
+	dataExports, err := dataExportRepo.GetDataExportsByIdUser(user.IdUser)
+	if err != nil {
+		return err
+	}
+	if err := userRepo.EraseUser(user.Anonymised(now)); err != nil {
+		return err
+	}
+
+	var keys []string
+	for _, dataExport := range dataExports {
+		if dataExport.FileKey != "" {
+			keys = append(keys, dataExport.FileKey)
+		}
+	}
+	if user.Foto != "" && !userRepo.IsFotoShared(user.Foto, user.IdUser) {
+		for _, url := range []string{user.FotoUrl, user.FotoMediumUrl, user.FotoThumbnailUrl} {
+			// Local storage URLs carry their signature in the query.
+			url, _, _ = strings.Cut(url, "?")
+			if index := strings.LastIndex(url, "images/"); index >= 0 {
+				keys = append(keys, url[index:])
+			}
+		}
+	}
+	for _, key := range keys {
+		if err := fileStorage.Delete(context.Background(), key); err != nil {
+			// The account is already anonymised; a leftover file is logged
+			// rather than failing the erasure.
+			slog.Error("erasure: deleting file failed", "key", key, "user", user.IdUser, "error", err)
+		}
+	}
+	return nil
+}
+
+// ErasureJob periodically erases the accounts whose deletion grace period has
+// ended and removes the data exports that can no longer be downloaded.
+type ErasureJob struct {
+	userRepo       repositories.UserRepository
+	dataExportRepo repositories.DataExportRepository
+	fileStorage    storage.Storage
+	interval       time.Duration
+}
+
+func NewErasureJob(db *gorm.DB, fileStorage storage.Storage, interval time.Duration) *ErasureJob {
+	return &ErasureJob{
+		userRepo:       repositories.NewDBUserRepository(db),
+		dataExportRepo: repositories.NewDBDataExportRepository(db),
+		fileStorage:    fileStorage,
+		interval:       interval,
+	}
+}
+
+// Run erases due accounts right away and then every interval until ctx is
+// cancelled.
+func (job *ErasureJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+	for {
+		job.RunOnce(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce erases every account due at now, removes expired data exports and
+// returns how many accounts were erased. A failing account or export is
+// logged and retried on the next run.
+func (job *ErasureJob) RunOnce(now time.Time) int {
+	job.removeExpiredExports(now)
+
+	users, err := job.userRepo.GetUsersDueForErasure(now)
+	if err != nil {
+		slog.Error("erasure: getting due users failed", "error", err)
+		return 0
+	}
+	erased := 0
+	for _, user := range users {
+		if err := eraseUser(job.userRepo, job.dataExportRepo, job.fileStorage, user, now); err != nil {
+			slog.Error("erasure: erasing user failed", "user", user.IdUser, "error", err)
+			continue
+		}
+		erased++
+	}
+	if erased > 0 {
+		slog.Info("erasure: erased accounts", "count", erased)
+	}
+	return erased
+}
+
+// removeExpiredExports deletes the ZIP and the row of every export whose
+// download period has ended, and of failed exports older than that period.
+func (job *ErasureJob) removeExpiredExports(now time.Time) {
+	dataExports, err := job.dataExportRepo.GetExpiredDataExports(now, now.Add(-dataExportRetention))
+	if err != nil {
+		slog.Error("erasure: getting expired exports failed", "error", err)
+		return
+	}
+	removed := 0
+	for _, dataExport := range dataExports {
+		if dataExport.FileKey != "" {
+			if err := job.fileStorage.Delete(context.Background(), dataExport.FileKey); err != nil {
+				slog.Error("erasure: deleting export file failed", "export", dataExport.IdExport, "error", err)
+				continue
+			}
+		}
+		if err := job.dataExportRepo.DeleteDataExport(dataExport.IdExport); err != nil {
+			slog.Error("erasure: deleting export failed", "export", dataExport.IdExport, "error", err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		slog.Info("erasure: removed expired exports", "count", removed)
+	}
+}
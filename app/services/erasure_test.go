@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/app/repositories"
+	"kalorize-api/app/storage"
+	"kalorize-api/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEraseUser(t *testing.T) {
+	db := newTestDB(t)
+	fileStorage, err := storage.NewLocalStorage(config.StorageConfig{Driver: "local", LocalPath: t.TempDir(), SigningKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo := repositories.NewDBUserRepository(db)
+	dataExportRepo := repositories.NewDBDataExportRepository(db)
+	variants := []string{"_original.png", "_medium.jpg", "_thumbnail.jpg"}
+	put := func(key string) {
+		if err := fileStorage.Put(context.Background(), key, strings.NewReader("content"), storage.PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// withPhoto gives the user the photo files stored under hash.
+	withPhoto := func(user models.User, hash string) models.User {
+		for _, variant := range variants {
+			put("images/" + hash + variant)
+		}
+		user.Foto = hash + variants[0]
+		user.FotoUrl = fileStorage.URL("images/" + hash + variants[0])
+		user.FotoMediumUrl = fileStorage.URL("images/" + hash + variants[1])
+		user.FotoThumbnailUrl = fileStorage.URL("images/" + hash + variants[2])
+		if err := userRepo.UpdateUser(user); err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	create := func(value interface{}) {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		share      func(hash string)
+		wantKeptBy string
+	}{
+		{name: "photo of their own"},
+		{
+			name: "photo shared with another user",
+			share: func(hash string) {
+				other, _ := newTestUser(t, db, "user")
+				withPhoto(other, hash)
+			},
+			wantKeptBy: "user",
+		},
+		{
+			name: "photo shared with a makanan",
+			share: func(hash string) {
+				create(&models.Makanan{IdMakanan: uuid.NewString(), Nama: "Nasi", Foto: fileStorage.URL("images/" + hash + variants[0])})
+			},
+			wantKeptBy: "makanan",
+		},
+		{
+			name: "photo shared with a deleted franchise",
+			share: func(hash string) {
+				franchise := models.Franchise{IdFranchise: uuid.New(), NamaFranchise: "Warung", FotoFranchise: fileStorage.URL("images/" + hash + variants[0])}
+				create(&franchise)
+				if err := db.Delete(&franchise).Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantKeptBy: "franchise",
+		},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user, _ := newTestUser(t, db, "user")
+			hash := strings.Repeat(string(rune('a'+i)), 16)
+			user = withPhoto(user, hash)
+			if test.share != nil {
+				test.share(hash)
+			}
+			exportKey := "exports/" + user.IdUser.String() + ".zip"
+			put(exportKey)
+			create(&models.DataExport{IdExport: uuid.New(), IdUser: user.IdUser, Status: "ready", FileKey: exportKey})
+			create(&models.History{IdHistory: uuid.New(), IdUser: user.IdUser})
+			create(&models.CartItem{IdUser: user.IdUser, IdMakanan: uuid.NewString(), Jumlah: 1})
+			create(&models.Token{IdToken: uuid.New(), UserId: user.IdUser, AccessToken: "access"})
+			order := models.Order{IdOrder: uuid.New(), IdUser: user.IdUser, Catatan: "ring the bell twice"}
+			create(&order)
+
+			now := time.Now()
+			if err := eraseUser(userRepo, dataExportRepo, fileStorage, user, now); err != nil {
+				t.Fatalf("eraseUser: %v", err)
+			}
+
+			erased, err := userRepo.GetUserByIdIncludingDeleted(user.IdUser)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if erased.Email == user.Email || erased.Fullname == user.Fullname || erased.Foto != "" || erased.ErasedAt == nil || !erased.DeletedAt.Valid {
+				t.Errorf("erased user = %+v, want it anonymised and deleted", erased)
+			}
+			for name, model := range map[string]interface{}{
+				"data exports": &models.DataExport{},
+				"history":      &models.History{},
+				"cart items":   &models.CartItem{},
+			} {
+				var count int64
+				db.Model(model).Where("id_user = ?", user.IdUser).Count(&count)
+				if count != 0 {
+					t.Errorf("%d %s left, want them deleted", count, name)
+				}
+			}
+			var tokens int64
+			db.Model(&models.Token{}).Where("user_id = ?", user.IdUser).Count(&tokens)
+			if tokens != 0 {
+				t.Errorf("%d tokens left, want them deleted", tokens)
+			}
+			var kept models.Order
+			if err := db.First(&kept, "id_order = ?", order.IdOrder).Error; err != nil || kept.Catatan != "" {
+				t.Errorf("order = %+v, %v, want it kept without its note", kept, err)
+			}
+
+			if _, err := fileStorage.Get(context.Background(), exportKey); !errors.Is(err, storage.ErrNotFound) {
+				t.Errorf("data export file: %v, want it deleted", err)
+			}
+			for _, variant := range variants {
+				key := "images/" + hash + variant
+				reader, err := fileStorage.Get(context.Background(), key)
+				if err == nil {
+					reader.Close()
+				}
+				switch {
+				case test.wantKeptBy == "" && !errors.Is(err, storage.ErrNotFound):
+					t.Errorf("%s: %v, want it deleted", key, err)
+				case test.wantKeptBy != "" && err != nil:
+					t.Errorf("%s: %v, want it kept for the %s sharing it", key, err, test.wantKeptBy)
+				}
+			}
+		})
+	}
+}
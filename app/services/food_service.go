@@ -12,87 +12,106 @@ import (
 )
 
 type makananService struct {
-	makananRepo repositories.MakananRepository
+	makananRepo   repositories.MakananRepository
+	franchiseRepo repositories.FranchiseRepository
 }
 
-func (service *makananService) GetAllMakanan() utils.Response {
+// GetAllMakanan lists every makanan in locale, falling back to Indonesian
+// for those that are not translated.
+func (service *makananService) GetAllMakanan(locale string) (utils.Response, error) {
 	var response utils.Response
-	makanan, err := service.makananRepo.GetAllMakanan()
+	makanan, err := service.makananRepo.GetAllMakananWithTranslation(locale)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Internal server error", err)
 	}
 	var formattedMakanan []formatter.MakananFormat
 	for i := range makanan {
-		formattedMakanan = append(formattedMakanan, formatter.FormatterMakananIndo(makanan[i]))
+		formattedMakanan = append(formattedMakanan, formatter.FormatterMakanan(makanan[i].Localized(locale)))
 	}
 	response.StatusCode = 200
 	response.Messages = "success"
 	response.Data = formattedMakanan
-	return response
+	return response, nil
 }
 
-func (service *makananService) GetMakananCSV(c echo.Context) utils.Response {
+func (service *makananService) GetMakananCSV(c echo.Context, locale string) (utils.Response, error) {
 	// response is .csv file generator
 	wr := csv.NewWriter(c.Response())
 	var response utils.Response
-	makanan, err := service.makananRepo.GetAllMakanan()
+	makanan, err := service.makananRepo.GetAllMakananWithTranslation(locale)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Internal server error", err)
+	}
+	for i := range makanan {
+		makanan[i] = makanan[i].Localized(locale)
 	}
 	formattedMultiMakanan := formatter.FormatterMakananToMultiDimentionalArray(makanan)
 	wr.WriteAll(formattedMultiMakanan)
 
 	response.StatusCode = 200
 	response.Messages = "success"
-	return response
+	return response, nil
 }
 
-func (service *makananService) GetMakananById(id string) utils.Response {
+func (service *makananService) GetMakananById(id string, locale string) (utils.Response, error) {
 	var response utils.Response
-	makanan, err := service.makananRepo.GetMakananById(id)
+	makanan, err := service.makananRepo.GetMakananByIdWithTranslation(id, locale)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Internal server error", err)
 	}
 
-	formattedMakanan := formatter.FormatterMakananIndo(makanan)
+	formattedMakanan := formatter.FormatterMakanan(makanan.Localized(locale))
 
 	response.StatusCode = 200
 	response.Messages = "success"
 	response.Data = formattedMakanan
-	return response
+	return response, nil
 }
 
-func (service *makananService) CreateMakanan(makanan models.Makanan) utils.Response {
+func (service *makananService) CreateMakanan(makanan models.Makanan) (utils.Response, error) {
 	var response utils.Response
 	err := service.makananRepo.CreateMakanan(makanan)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Internal server error", err)
 	}
 	response.StatusCode = 200
 	response.Messages = "success"
 	response.Data = makanan
-	return response
+	return response, nil
+}
+
+// GetFranchiseByMakanan lists the franchises that sell a makanan.
+func (service *makananService) GetFranchiseByMakanan(id string) (utils.Response, error) {
+	var response utils.Response
+	makanan, err := service.makananRepo.GetMakananById(id)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("makanan_not_found")
+	}
+	franchise, err := service.franchiseRepo.GetFranchiseByIdMakanan(makanan.IdMakanan)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Internal server error", err)
+	}
+	formattedFranchise := []formatter.FranchiseFormat{}
+	for i := range franchise {
+		formattedFranchise = append(formattedFranchise, formatter.FormatterFranchise(franchise[i]))
+	}
+	response.StatusCode = 200
+	response.Messages = "success"
+	response.Data = formattedFranchise
+	return response, nil
 }
 
 type MakananService interface {
-	GetAllMakanan() utils.Response
-	GetMakananById(id string) utils.Response
-	CreateMakanan(makanan models.Makanan) utils.Response
-	GetMakananCSV(c echo.Context) utils.Response
+	GetAllMakanan(locale string) (utils.Response, error)
+	GetMakananById(id string, locale string) (utils.Response, error)
+	CreateMakanan(makanan models.Makanan) (utils.Response, error)
+	GetMakananCSV(c echo.Context, locale string) (utils.Response, error)
+	GetFranchiseByMakanan(id string) (utils.Response, error)
 }
 
 func NewMakananService(db *gorm.DB) MakananService {
-	return &makananService{makananRepo: repositories.NewDBMakananRepository(db)}
+	return &makananService{
+		makananRepo:   repositories.NewDBMakananRepository(db),
+		franchiseRepo: repositories.NewDBFranchiseRepository(db),
+	}
 }
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"kalorize-api/app/models"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetMakananTranslation(t *testing.T) {
+	db := newTestDB(t)
+	rows := []interface{}{
+		&models.Makanan{IdMakanan: "1", Nama: "Nasi Goreng", Bahan: "nasi, telur", CookingStep: "goreng"},
+		&models.Makanan{IdMakanan: "2", Nama: "Sate Ayam", Bahan: "ayam", CookingStep: "bakar"},
+		&models.Makanan{IdMakanan: "3", Nama: "Rendang", Bahan: "daging", CookingStep: "masak"},
+		&models.MakananTranslation{IdMakanan: "1", Locale: utils.LocaleEN, Nama: "Fried Rice", Bahan: "rice, egg", CookingStep: "fry", UpdatedAt: time.Now()},
+		// Only the name of the satay has been translated so far.
+		&models.MakananTranslation{IdMakanan: "2", Locale: utils.LocaleEN, Nama: "Chicken Satay", UpdatedAt: time.Now()},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type makanan struct {
+		Nama        string
+		Bahan       []string
+		CookingStep []string
+	}
+	tests := []struct {
+		name   string
+		locale string
+		want   map[string]makanan
+	}{
+		{"english", utils.LocaleEN, map[string]makanan{
+			"1": {"Fried Rice", []string{"1. rice", "2. egg"}, []string{"1. fry"}},
+			"2": {"Chicken Satay", []string{"1. ayam"}, []string{"1. bakar"}},
+			"3": {"Rendang", []string{"1. daging"}, []string{"1. masak"}},
+		}},
+		{"indonesian", utils.LocaleID, map[string]makanan{
+			"1": {"Nasi Goreng", []string{"1. nasi", "2. telur"}, []string{"1. goreng"}},
+			"2": {"Sate Ayam", []string{"1. ayam"}, []string{"1. bakar"}},
+			"3": {"Rendang", []string{"1. daging"}, []string{"1. masak"}},
+		}},
+	}
+	service := NewMakananService(db)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := service.GetAllMakanan(test.locale)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]makanan{}
+			for _, item := range response.Data.([]formatter.MakananFormat) {
+				got[item.ID] = makanan{item.Nama, item.Bahan, item.CookingStep}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetAllMakanan(%s) = %+v, want %+v", test.locale, got, test.want)
+			}
+
+			for id, want := range test.want {
+				response, err := service.GetMakananById(id, test.locale)
+				if err != nil {
+					t.Fatal(err)
+				}
+				item := response.Data.(formatter.MakananFormat)
+				if got := (makanan{item.Nama, item.Bahan, item.CookingStep}); !reflect.DeepEqual(got, want) {
+					t.Errorf("GetMakananById(%s, %s) = %+v, want %+v", id, test.locale, got, want)
+				}
+			}
+		})
+	}
+}
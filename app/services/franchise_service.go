@@ -1,11 +1,14 @@
 package services
 
 import (
+	"kalorize-api/app/metrics"
 	"kalorize-api/app/models"
 	"kalorize-api/app/repositories"
+	"kalorize-api/formatter"
 	"kalorize-api/utils"
+	"sort"
+	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -13,82 +16,189 @@ type FranchiseService struct {
 	franchiseRepo repositories.FranchiseRepository
 }
 
-func (service *FranchiseService) GetAllFranchise() utils.Response {
+func (service *FranchiseService) GetAllFranchise() (utils.Response, error) {
 	var response utils.Response
 	franchise, err := service.franchiseRepo.GetAllFranchise()
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Internal server error", err)
+	}
+	formattedFranchise := []formatter.FranchiseFormat{}
+	for i := range franchise {
+		formattedFranchise = append(formattedFranchise, formatter.FormatterFranchise(franchise[i]))
 	}
 	response.StatusCode = 200
 	response.Messages = "success"
-	response.Data = franchise
-	return response
+	response.Data = formattedFranchise
+	return response, nil
 }
 
-func (service *FranchiseService) GetFranchiseById(id string) utils.Response {
+func (service *FranchiseService) GetFranchiseById(id string) (utils.Response, error) {
 	var response utils.Response
 	franchise, err := service.franchiseRepo.GetFranchiseById(id)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.NotFound("franchise_not_found")
 	}
 	response.StatusCode = 200
 	response.Messages = "success"
-	response.Data = franchise
-	return response
+	response.Data = formatter.FormatterFranchise(franchise)
+	return response, nil
 }
 
-func (service *FranchiseService) CreateFranchise(franchise models.Franchise) utils.Response {
+func (service *FranchiseService) GetFranchiseMenu(id string) (utils.Response, error) {
 	var response utils.Response
-	err := service.franchiseRepo.CreateFranchise(franchise)
+	franchise, err := service.franchiseRepo.GetFranchiseById(id)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+	makanan, err := service.franchiseRepo.GetMakananByIdFranchise(franchise.IdFranchise)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Internal server error", err)
+	}
+	franchiseMakanans, err := service.franchiseRepo.GetFranchiseMakananByIdFranchise(franchise.IdFranchise)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Internal server error", err)
 	}
 	response.StatusCode = 200
 	response.Messages = "success"
-	response.Data = franchise
-	return response
+	response.Data = formatter.FormatterFranchiseMenu(makanan, franchiseMakanans)
+	return response, nil
 }
 
-func (service *FranchiseService) UpdateFranchise(franchise models.Franchise) utils.Response {
-	var response utils.Response
-	err := service.franchiseRepo.UpdateFranchise(franchise)
+// GetNearbyFranchise lists the franchises within the radius of a coordinate,
+// nearest first.
+func (service *FranchiseService) GetNearbyFranchise(nearbyRequest utils.NearbyRequest) (utils.Response, error) {
+	box := utils.NewBoundingBox(nearbyRequest.Latitude, nearbyRequest.Longitude, nearbyRequest.RadiusKm)
+	franchises, err := service.franchiseRepo.GetFranchiseByArea(box.MinLat, box.MaxLat, box.MinLon, box.MaxLon)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Internal server error", err)
 	}
-	response.StatusCode = 200
-	response.Messages = "success"
-	response.Data = franchise
-	return response
+
+	nearby := []formatter.NearbyFranchiseFormat{}
+	for _, franchise := range franchises {
+		distance := utils.HaversineDistance(nearbyRequest.Latitude, nearbyRequest.Longitude, franchise.LatitudeFranchise, franchise.LongitudeFranchise)
+		if distance <= nearbyRequest.RadiusKm {
+			nearby = append(nearby, formatter.NearbyFranchiseFormat{
+				FranchiseFormat: formatter.FormatterFranchise(franchise),
+				DistanceKm:      distance,
+			})
+		}
+	}
+	sort.Slice(nearby, func(i, j int) bool {
+		return nearby[i].DistanceKm < nearby[j].DistanceKm
+	})
+
+	start, end := utils.PageBounds(len(nearby), nearbyRequest.Page, nearbyRequest.Limit)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: utils.Page{
+		Items: nearby[start:end],
+		Page:  nearbyRequest.Page,
+		Limit: nearbyRequest.Limit,
+		Total: len(nearby),
+	}}, nil
 }
 
-func (service *FranchiseService) ConnectFranchiseToMakanan(idMakanan string, idFranchise uuid.UUID) utils.Response {
-	var response utils.Response
-	var franchiseMakanan models.FranchiseMakanan
-	franchiseMakanan.IdFranchise = idFranchise
-	franchiseMakanan.IdMakanan = idMakanan
-	err := service.franchiseRepo.AddFranchiseMakanan(franchiseMakanan)
+// Login signs a franchise operator in with the email and password set when
+// the franchise was registered.
+func (service *FranchiseService) Login(email, password string) (utils.Response, error) {
+	response, err := service.login(email, password)
+	metrics.RecordLogin(metrics.AccountFranchise, err)
+	return response, err
+}
+
+func (service *FranchiseService) login(email, password string) (utils.Response, error) {
+	if email == "" || password == "" {
+		return utils.Response{}, utils.Invalid("credentials_required")
+	}
+	franchise, err := service.franchiseRepo.GetFranchiseByEmail(email)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Internal server error"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Unauthorized("email_not_registered")
 	}
-	response.StatusCode = 200
-	response.Messages = "success"
-	response.Data = franchiseMakanan
-	return response
+	if !utils.CheckPasswordHash(password, franchise.PasswordFranchise) {
+		return utils.Response{}, utils.Unauthorized("wrong_password")
+	}
+	accessToken, err := utils.GenerateJWTFranchiseToken(franchise.IdFranchise, franchise.NamaFranchise, utils.JWTSecret())
+	if err != nil {
+		return utils.Response{}, utils.Internal("Token generation failed", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: map[string]interface{}{
+		"accessToken": accessToken,
+		"franchise":   formatter.FormatterFranchise(franchise),
+	}}, nil
+}
+
+func (service *FranchiseService) operator(bearerToken string) (models.Franchise, error) {
+	return franchiseOperator(service.franchiseRepo, bearerToken)
+}
+
+// franchiseOperator returns the franchise a franchise login token was issued
+// to. Everything an operator changes is looked up through it, so they can
+// only ever touch their own franchise.
+func franchiseOperator(franchiseRepo repositories.FranchiseRepository, bearerToken string) (models.Franchise, error) {
+	idFranchise, err := utils.ParseDataIdFranchise(bearerToken)
+	if err != nil {
+		return models.Franchise{}, utils.Unauthorized("unauthorized")
+	}
+	franchise, err := franchiseRepo.GetFranchiseById(idFranchise.String())
+	if err != nil {
+		return franchise, utils.Unauthorized("unauthorized")
+	}
+	return franchise, nil
+}
+
+func (service *FranchiseService) menuItem(bearerToken, idMakanan string) (models.FranchiseMakanan, error) {
+	franchise, err := service.operator(bearerToken)
+	if err != nil {
+		return models.FranchiseMakanan{}, err
+	}
+	franchiseMakanan, err := service.franchiseRepo.GetFranchiseMakanan(franchise.IdFranchise, idMakanan)
+	if err != nil {
+		return franchiseMakanan, utils.NotFound("menu_item_not_found")
+	}
+	return franchiseMakanan, nil
+}
+
+func (service *FranchiseService) GetOwnMenu(bearerToken string) (utils.Response, error) {
+	franchise, err := service.operator(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	return service.GetFranchiseMenu(franchise.IdFranchise.String())
+}
+
+func (service *FranchiseService) UpdateMenuItem(bearerToken, idMakanan string, menuRequest utils.FranchiseMenuRequest) (utils.Response, error) {
+	franchiseMakanan, err := service.menuItem(bearerToken, idMakanan)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	if menuRequest.Harga != nil {
+		franchiseMakanan.Harga = *menuRequest.Harga
+	}
+	if menuRequest.Tersedia != nil {
+		franchiseMakanan.Tersedia = *menuRequest.Tersedia
+	}
+	if err := service.franchiseRepo.UpdateFranchiseMakanan(franchiseMakanan); err != nil {
+		return utils.Response{}, utils.Internal("Internal server error", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: franchiseMakanan}, nil
+}
+
+// SetOutOfStock marks a menu item out of stock until the given time, after
+// which it is available again without another call. A zero time clears it.
+func (service *FranchiseService) SetOutOfStock(bearerToken, idMakanan string, until time.Time) (utils.Response, error) {
+	franchiseMakanan, err := service.menuItem(bearerToken, idMakanan)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	if until.IsZero() {
+		franchiseMakanan.HabisSampai = nil
+	} else if !until.After(time.Now()) {
+		return utils.Response{}, utils.Invalid("expiry_in_past")
+	} else {
+		franchiseMakanan.HabisSampai = &until
+	}
+	if err := service.franchiseRepo.UpdateFranchiseMakanan(franchiseMakanan); err != nil {
+		return utils.Response{}, utils.Internal("Internal server error", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: franchiseMakanan}, nil
 }
 
 func NewFranchiseService(db *gorm.DB) *FranchiseService {
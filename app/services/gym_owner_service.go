@@ -3,56 +3,290 @@ package services
 import (
 	"kalorize-api/app/models"
 	"kalorize-api/app/repositories"
+	"kalorize-api/formatter"
 	"kalorize-api/utils"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// minAdherenceGroup is the smallest number of reporting members for which
+// adherence figures are shown, so owners cannot single out one member.
+const minAdherenceGroup = 5
+
 type gymOwnerService struct {
-	gymRepo     repositories.GymRepository
-	gymKode     repositories.KodeGymRepository
-	gymUsedCode repositories.UsedCodeRepository
+	userRepo       repositories.UserRepository
+	gymRepo        repositories.GymRepository
+	gymOwnerRepo   repositories.GymOwnerRepository
+	gymKode        repositories.KodeGymRepository
+	gymUsedCode    repositories.UsedCodeRepository
+	membershipRepo repositories.MembershipRepository
+	historyRepo    repositories.HistoryRepository
+	auditRepo      repositories.AuditLogRepository
 }
 
-func (gymOwner *gymOwnerService) GenerateKodeGym(idGym uuid.UUID) utils.Response {
-	var response utils.Response
-	Gym, err := gymOwner.gymRepo.GetGymById(idGym)
+// ownedGym returns the gym if the token belongs to one of its owners or to an
+// admin. Otherwise it returns the response to send instead.
+func (gymOwner *gymOwnerService) ownedGym(bearerToken string, idGym uuid.UUID) (models.Gym, error) {
+	_, gym, err := gymOwner.ownerOfGym(bearerToken, idGym)
+	return gym, err
+}
+
+// ownerOfGym is ownedGym that also returns the user, for changes that are
+// recorded in the audit log.
+func (gymOwner *gymOwnerService) ownerOfGym(bearerToken string, idGym uuid.UUID) (models.User, models.Gym, error) {
+	user, err := gymOwner.owner(bearerToken)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to get gym"
-		response.Data = nil
-		return response
+		return user, models.Gym{}, err
+	}
+	if user.Role != "admin" && !gymOwner.gymOwnerRepo.IsGymOwner(user.IdUser, idGym) {
+		return user, models.Gym{}, utils.Forbidden("forbidden")
 	}
-	var kodeGym = models.KodeGym{
-		IdKodeGym:   uuid.New(),
-		KodeGym:     utils.GenerateKodeGym(Gym.NamaGym),
-		IdGym:       idGym,
-		ExpiredTime: utils.GetExpiredTime(),
+	gym, err := gymOwner.gymRepo.GetGymById(idGym)
+	if err != nil {
+		return user, gym, utils.NotFound("gym_not_found")
 	}
+	return user, gym, nil
+}
 
-	err = gymOwner.gymKode.CreateNewKodeGym(kodeGym)
+func (gymOwner *gymOwnerService) owner(bearerToken string) (models.User, error) {
+	email, err := utils.ParseDataEmail(bearerToken)
+	if email == "" || err != nil {
+		return models.User{}, utils.Unauthorized("unauthorized")
+	}
+	user, err := gymOwner.userRepo.GetUserByEmail(email)
+	if err != nil || (user.Role != "gym_owner" && user.Role != "admin") {
+		return user, utils.Unauthorized("unauthorized")
+	}
+	return user, nil
+}
+
+func (gymOwner *gymOwnerService) GetGyms(bearerToken string) (utils.Response, error) {
+	user, err := gymOwner.owner(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	gyms, err := gymOwner.gymOwnerRepo.GetGymsByIdUser(user.IdUser)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get gym", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: gyms}, nil
+}
+
+func (gymOwner *gymOwnerService) UpdateGym(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, gymRequest utils.GymRequest) (utils.Response, error) {
+	if err := utils.Validate(gymRequest); err != nil {
+		return utils.Response{}, err
+	}
+	user, gym, err := gymOwner.ownerOfGym(bearerToken, idGym)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	before := gym
+	if gymRequest.NamaGym != "" {
+		gym.NamaGym = gymRequest.NamaGym
+	}
+	if gymRequest.AlamatGym != "" {
+		gym.AlamatGym = gymRequest.AlamatGym
+	}
+	if gymRequest.Latitude != 0 {
+		gym.Latitude = gymRequest.Latitude
+	}
+	if gymRequest.Longitude != 0 {
+		gym.Longitude = gymRequest.Longitude
+	}
+	if gymRequest.LinkGoogle != "" {
+		gym.LinkGoogle = gymRequest.LinkGoogle
+	}
+	if err := gymOwner.gymRepo.UpdateGym(gym); err != nil {
+		return utils.Response{}, utils.Internal("Failed to update gym", err)
+	}
+	recordAudit(gymOwner.auditRepo, user, requestMeta, "gym.update", auditEntityGym, gym.IdGym.String(), before, gym)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: gym}, nil
+}
 
-	if err == nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to generate kode gym"
-		response.Data = nil
-		return response
+func (gymOwner *gymOwnerService) GenerateKodeGym(bearerToken string, requestMeta utils.RequestMeta, kodeGymRequest utils.KodeGymRequest) (utils.Response, error) {
+	if err := utils.Validate(kodeGymRequest); err != nil {
+		return utils.Response{}, err
 	}
+	var response utils.Response
+	user, Gym, err := gymOwner.ownerOfGym(bearerToken, kodeGymRequest.IdGym)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	kodeGym, err := newKodeGym(gymOwner.gymKode, Gym, kodeGymRequest, utils.GetExpiredTime())
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to generate kode gym", err)
+	}
+	recordAudit(gymOwner.auditRepo, user, requestMeta, "kode_gym.create", auditEntityKodeGym, kodeGym.IdKodeGym.String(), nil, kodeGym)
 	response.StatusCode = 200
-	response.Messages = "Success"
+	response.Messages = "success"
 	response.Data = kodeGym
-	return response
+	return response, nil
+}
+
+func (gymOwner *gymOwnerService) GetKodeGym(bearerToken string, idGym uuid.UUID) (utils.Response, error) {
+	if _, err := gymOwner.ownedGym(bearerToken, idGym); err != nil {
+		return utils.Response{}, err
+	}
+	kodeGyms, err := gymOwner.gymKode.GetKodeGymByIdGym(idGym)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get kode gym", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formatter.FormatterKodeGym(kodeGyms)}, nil
+}
+
+func (gymOwner *gymOwnerService) RevokeKodeGym(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, idKodeGym uuid.UUID) (utils.Response, error) {
+	user, _, err := gymOwner.ownerOfGym(bearerToken, idGym)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	kodeGym, err := gymOwner.gymKode.GetKodeGymById(idKodeGym)
+	if err != nil || kodeGym.IdGym != idGym {
+		return utils.Response{}, utils.NotFound("kode_gym_not_found")
+	}
+	before := kodeGym
+	kodeGym, err = revokeKodeGym(gymOwner.gymKode, kodeGym)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to revoke kode gym", err)
+	}
+	recordAudit(gymOwner.auditRepo, user, requestMeta, "kode_gym.revoke", auditEntityKodeGym, kodeGym.IdKodeGym.String(), before, kodeGym)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: kodeGym}, nil
+}
+
+// gymMembers returns the current membership at the gym of everyone who has
+// ever been a member there, keyed by user.
+func (gymOwner *gymOwnerService) gymMembers(idGym uuid.UUID, now time.Time) (map[uuid.UUID]models.Membership, error) {
+	memberships, err := gymOwner.membershipRepo.GetMembershipsByIdGym(idGym)
+	if err != nil {
+		return nil, err
+	}
+	byUser := map[uuid.UUID][]models.Membership{}
+	for _, membership := range memberships {
+		byUser[membership.IdUser] = append(byUser[membership.IdUser], membership)
+	}
+	members := map[uuid.UUID]models.Membership{}
+	for idUser, userMemberships := range byUser {
+		if current := currentMembership(userMemberships, now); current != nil {
+			members[idUser] = *current
+		} else {
+			members[idUser] = userMemberships[len(userMemberships)-1]
+		}
+	}
+	return members, nil
+}
+
+func (gymOwner *gymOwnerService) GetMembers(bearerToken string, idGym uuid.UUID) (utils.Response, error) {
+	if _, err := gymOwner.ownedGym(bearerToken, idGym); err != nil {
+		return utils.Response{}, err
+	}
+	now := time.Now()
+	members, err := gymOwner.gymMembers(idGym, now)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get members", err)
+	}
+	ids := make([]uuid.UUID, 0, len(members))
+	for idUser := range members {
+		ids = append(ids, idUser)
+	}
+	users, err := gymOwner.userRepo.GetUsersByIds(ids)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get members", err)
+	}
+	result := make([]formatter.GymMemberFormat, 0, len(users))
+	for _, user := range users {
+		result = append(result, formatter.FormatterGymMember(user, members[user.IdUser], now))
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: result}, nil
+}
+
+// GetAdherence reports how closely the gym's active members follow their
+// calorie targets over the last days. Only totals are returned, and nothing
+// at all for groups too small to stay anonymous.
+func (gymOwner *gymOwnerService) GetAdherence(bearerToken string, idGym uuid.UUID, days int) (utils.Response, error) {
+	if _, err := gymOwner.ownedGym(bearerToken, idGym); err != nil {
+		return utils.Response{}, err
+	}
+	now := time.Now()
+	members, err := gymOwner.gymMembers(idGym, now)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get members", err)
+	}
+	var ids []uuid.UUID
+	for idUser, membership := range members {
+		if status := membership.Status(now); status == models.MembershipActive || status == models.MembershipGrace {
+			ids = append(ids, idUser)
+		}
+	}
+	users, err := gymOwner.userRepo.GetUsersByIds(ids)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get members", err)
+	}
+	targets := map[uuid.UUID]int{}
+	for _, user := range users {
+		if user.TargetKalori > 0 {
+			targets[user.IdUser] = user.TargetKalori
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	histories, err := gymOwner.historyRepo.GetHistoryByIdUsersBetween(ids, today.AddDate(0, 0, -days), today.AddDate(0, 0, 1))
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get history", err)
+	}
+	reporting := map[uuid.UUID]bool{}
+	var loggedDays, onTargetDays int
+	var percentSum float64
+	for _, history := range histories {
+		target, ok := targets[history.IdUser]
+		if !ok {
+			continue
+		}
+		reporting[history.IdUser] = true
+		loggedDays++
+		percent := float64(history.TotalKalori) * 100 / float64(target)
+		percentSum += percent
+		if percent >= 90 && percent <= 110 {
+			onTargetDays++
+		}
+	}
+
+	data := formatter.GymAdherenceFormat{
+		Days:             days,
+		ActiveMembers:    len(ids),
+		ReportingMembers: len(reporting),
+	}
+	if len(reporting) < minAdherenceGroup {
+		return utils.Response{StatusCode: 200, Messages: "not_enough_members", Data: data}, nil
+	}
+	averageLoggedDays := float64(loggedDays) / float64(len(reporting))
+	averageKaloriPercent := percentSum / float64(loggedDays)
+	onTargetPercent := float64(onTargetDays) * 100 / float64(loggedDays)
+	data.AverageLoggedDays = &averageLoggedDays
+	data.AverageKaloriPercent = &averageKaloriPercent
+	data.OnTargetPercent = &onTargetPercent
+	return utils.Response{StatusCode: 200, Messages: "success", Data: data}, nil
 }
 
 type GymOwnerService interface {
-	GenerateKodeGym(idGym uuid.UUID) utils.Response
+	GetGyms(bearerToken string) (utils.Response, error)
+	UpdateGym(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, gymRequest utils.GymRequest) (utils.Response, error)
+	GenerateKodeGym(bearerToken string, requestMeta utils.RequestMeta, kodeGymRequest utils.KodeGymRequest) (utils.Response, error)
+	GetKodeGym(bearerToken string, idGym uuid.UUID) (utils.Response, error)
+	RevokeKodeGym(bearerToken string, requestMeta utils.RequestMeta, idGym uuid.UUID, idKodeGym uuid.UUID) (utils.Response, error)
+	GetMembers(bearerToken string, idGym uuid.UUID) (utils.Response, error)
+	GetAdherence(bearerToken string, idGym uuid.UUID, days int) (utils.Response, error)
 }
 
 func NewGymOwnerService(db *gorm.DB) GymOwnerService {
 	return &gymOwnerService{
-		gymRepo:     repositories.NewDBGymRepository(db),
-		gymKode:     repositories.NewDBKodeGymRepository(db),
-		gymUsedCode: repositories.NewDBUsedCodeRepository(db),
+		userRepo:       repositories.NewDBUserRepository(db),
+		gymRepo:        repositories.NewDBGymRepository(db),
+		gymOwnerRepo:   repositories.NewDBGymOwnerRepository(db),
+		gymKode:        repositories.NewDBKodeGymRepository(db),
+		gymUsedCode:    repositories.NewDBUsedCodeRepository(db),
+		membershipRepo: repositories.NewDBMembershipRepository(db),
+		historyRepo:    repositories.NewDBHistoryRepository(db),
+		auditRepo:      repositories.NewDBAuditLogRepository(db),
 	}
 }
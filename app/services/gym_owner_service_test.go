@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// newTestMember saves an active member of gym with a calorie target who
+// logged their meals on the given number of the last days, on target.
+func newTestMember(t *testing.T, db *gorm.DB, gym models.Gym, loggedDays int) models.User {
+	t.Helper()
+	user, _ := newTestUser(t, db, "user")
+	user.TargetKalori = 2
+	now := time.Now()
+	fixtures := []interface{}{
+		&models.Membership{IdMembership: uuid.New(), IdUser: user.IdUser, IdGym: gym.IdGym, StartDate: now.AddDate(0, 0, -40), EndDate: now.AddDate(0, 0, 20)},
+	}
+	for day := 0; day < loggedDays; day++ {
+		fixtures = append(fixtures, &models.History{IdHistory: uuid.New(), IdUser: user.IdUser, TotalKalori: 2, TanggalDibuat: now.AddDate(0, 0, -day)})
+	}
+	if err := db.Model(&user).Update("target_kalori", user.TargetKalori).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, fixture := range fixtures {
+		if err := db.Create(fixture).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return user
+}
+
+func newTestOwner(t *testing.T, db *gorm.DB, gym models.Gym) string {
+	t.Helper()
+	owner, token := newTestUser(t, db, "gym_owner")
+	if err := db.Create(&models.GymOwner{IdUser: owner.IdUser, IdGym: gym.IdGym}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestGetAdherence(t *testing.T) {
+	tests := []struct {
+		name      string
+		reporting int
+		silent    int
+		days      int
+		wantShown bool
+	}{
+		{name: "no members", days: 30},
+		{name: "one below the threshold", reporting: minAdherenceGroup - 1, silent: 3, days: 30},
+		{name: "at the threshold", reporting: minAdherenceGroup, days: 30, wantShown: true},
+		{name: "above the threshold", reporting: minAdherenceGroup + 2, silent: 1, days: 30, wantShown: true},
+		{name: "shortest period", reporting: minAdherenceGroup, days: 1, wantShown: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newTestDB(t)
+			gym := newTestGym(t, db, true)
+			token := newTestOwner(t, db, gym)
+			for i := 0; i < test.reporting; i++ {
+				newTestMember(t, db, gym, 3)
+			}
+			for i := 0; i < test.silent; i++ {
+				newTestMember(t, db, gym, 0)
+			}
+
+			response, err := NewGymOwnerService(db).GetAdherence(token, gym.IdGym, test.days)
+			if err != nil {
+				t.Fatal(err)
+			}
+			adherence := response.Data.(formatter.GymAdherenceFormat)
+			if adherence.Days != test.days || adherence.ActiveMembers != test.reporting+test.silent || adherence.ReportingMembers != test.reporting {
+				t.Errorf("adherence = %+v, want %d days, %d active and %d reporting", adherence, test.days, test.reporting+test.silent, test.reporting)
+			}
+			shown := adherence.AverageLoggedDays != nil && adherence.AverageKaloriPercent != nil && adherence.OnTargetPercent != nil
+			hidden := adherence.AverageLoggedDays == nil && adherence.AverageKaloriPercent == nil && adherence.OnTargetPercent == nil
+			switch {
+			case test.wantShown && (!shown || response.Messages != "success"):
+				t.Errorf("%s: averages %+v, want them shown", response.Messages, adherence)
+			case !test.wantShown && (!hidden || response.Messages != "not_enough_members"):
+				t.Errorf("%s: averages %+v, want them hidden", response.Messages, adherence)
+			}
+			wantLoggedDays := 3.0
+			if test.days == 1 {
+				// Today and yesterday are within one day back.
+				wantLoggedDays = 2
+			}
+			if shown && (*adherence.AverageLoggedDays != wantLoggedDays || *adherence.OnTargetPercent != 100) {
+				t.Errorf("%.1f logged days and %.0f%% on target, want %.0f and 100%%", *adherence.AverageLoggedDays, *adherence.OnTargetPercent, wantLoggedDays)
+			}
+		})
+	}
+}
+
+func TestGetMembers(t *testing.T) {
+	db := newTestDB(t)
+	gym := newTestGym(t, db, true)
+	otherGym := newTestGym(t, db, true)
+	token := newTestOwner(t, db, gym)
+	otherToken := newTestOwner(t, db, otherGym)
+	member := newTestMember(t, db, gym, 0)
+	newTestMember(t, db, otherGym, 0)
+	service := NewGymOwnerService(db)
+
+	response, err := service.GetMembers(token, gym.IdGym)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := response.Data.([]formatter.GymMemberFormat)
+	if len(members) != 1 || members[0].IdUser != member.IdUser || members[0].Email != member.Email || members[0].Membership.Status != models.MembershipActive {
+		t.Errorf("GetMembers = %+v, want the one active member", members)
+	}
+
+	var serviceErr *utils.Error
+	if _, err := service.GetMembers(otherToken, gym.IdGym); !errors.As(err, &serviceErr) || serviceErr.Code != "forbidden" {
+		t.Errorf("owner of another gym: error = %v, want forbidden", err)
+	}
+}
@@ -1,9 +1,13 @@
 package services
 
 import (
+	"errors"
 	"kalorize-api/app/models"
 	"kalorize-api/app/repositories"
+	"kalorize-api/formatter"
 	"kalorize-api/utils"
+	"sort"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,51 +18,74 @@ type GymService struct {
 	gymUsedCode repositories.UsedCodeRepository
 }
 
-func (gymService *GymService) CheckGymCode(gymKode string) utils.Response {
+func (gymService *GymService) CheckGymCode(gymKode string) (utils.Response, error) {
 	kodeGym, err := gymService.gymKode.GetKodeGymByKode(gymKode)
 	if err != nil {
-		return utils.Response{StatusCode: 500, Messages: err.Error()}
+		return utils.Response{}, utils.NotFound("kode_gym_not_found")
 	}
 
-	if kodeGym.KodeGym == "" {
-		return utils.Response{StatusCode: 404, Messages: "Kode Gym tidak ditemukan"}
+	if status := kodeGymStatus(gymService.gymRepo, kodeGym, time.Now()); status != models.KodeGymActive {
+		return utils.Response{}, kodeGymUnavailable(status)
 	}
 
-	if gymService.IsUsed(kodeGym.KodeGym) == (utils.Response{StatusCode: 200, Messages: "Kode Gym sudah digunakan"}) {
-		return utils.Response{StatusCode: 400, Messages: "Kode Gym sudah digunakan"}
+	return utils.Response{StatusCode: 200, Messages: "kode_gym_valid"}, nil
+}
+
+func (gymService *GymService) IsUsed(gymCode string) (utils.Response, error) {
+	usedCode, err := gymService.gymUsedCode.GetUsedCodeByGymCode(gymCode)
+	if err != nil || usedCode.KodeGym == "" {
+		return utils.Response{}, utils.NotFound("kode_gym_unused")
 	}
 
-	return utils.Response{StatusCode: 200, Messages: "Kode Gym valid"}
+	return utils.Response{StatusCode: 200, Messages: "kode_gym_used"}, nil
 }
 
-func (gymService *GymService) IsUsed(gymCode string) utils.Response {
-	usedCode, err := gymService.gymUsedCode.GetUsedCodeByGymCode(gymCode)
-	if err != nil {
-		return utils.Response{StatusCode: 500, Messages: "Belum ada Gym terdaftar"}
+func (gymService *GymService) GetAllGym() (utils.Response, error) {
+	gym, err := gymService.gymRepo.GetActiveGym()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.Response{}, utils.NotFound("gym_not_found")
 	}
-	if usedCode.KodeGym == "" {
-		return utils.Response{StatusCode: 404, Messages: "Kode Gym tidak ditemukan"}
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get gym", err)
 	}
-
-	return utils.Response{StatusCode: 200, Messages: "Kode Gym sudah digunakan"}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: gym}, nil
 }
 
-func (gymService *GymService) GetAllGym() utils.Response {
-	gym, _ := gymService.gymRepo.GetGym()
-	if gym == nil {
-		return utils.Response{StatusCode: 404, Messages: "Belum ada gym terdaftar"}
+// GetNearbyGym lists the gyms within the radius of a coordinate, nearest
+// first.
+func (gymService *GymService) GetNearbyGym(nearbyRequest utils.NearbyRequest) (utils.Response, error) {
+	box := utils.NewBoundingBox(nearbyRequest.Latitude, nearbyRequest.Longitude, nearbyRequest.RadiusKm)
+	gyms, err := gymService.gymRepo.GetGymByArea(box.MinLat, box.MaxLat, box.MinLon, box.MaxLon)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get gym", err)
 	}
-	return utils.Response{StatusCode: 200, Messages: "Success", Data: gym}
+
+	nearby := []formatter.NearbyGymFormat{}
+	for _, gym := range gyms {
+		distance := utils.HaversineDistance(nearbyRequest.Latitude, nearbyRequest.Longitude, gym.Latitude, gym.Longitude)
+		if distance <= nearbyRequest.RadiusKm {
+			nearby = append(nearby, formatter.NearbyGymFormat{Gym: gym, DistanceKm: distance})
+		}
+	}
+	sort.Slice(nearby, func(i, j int) bool {
+		return nearby[i].DistanceKm < nearby[j].DistanceKm
+	})
+
+	start, end := utils.PageBounds(len(nearby), nearbyRequest.Page, nearbyRequest.Limit)
+	return utils.Response{StatusCode: 200, Messages: "success", Data: utils.Page{
+		Items: nearby[start:end],
+		Page:  nearbyRequest.Page,
+		Limit: nearbyRequest.Limit,
+		Total: len(nearby),
+	}}, nil
 }
 
 func (gymService *GymService) FindGymFromGymCode(gymCode string) (models.Gym, error) {
-	var gym models.Gym
-	gymName := utils.GetAlphabetFromCode(gymCode)
-	gym, err := gymService.gymRepo.GetGymByGymName(gymName)
+	kodeGym, err := gymService.gymKode.GetKodeGymByKode(gymCode)
 	if err != nil {
-		return gym, err
+		return models.Gym{}, err
 	}
-	return gym, nil
+	return gymService.gymRepo.GetGymById(kodeGym.IdGym)
 }
 
 func NewGymService(db *gorm.DB) *GymService {
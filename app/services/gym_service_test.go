@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGetNearbyGym(t *testing.T) {
+	db := newTestDB(t)
+	deactivatedAt := time.Now()
+	gyms := []models.Gym{
+		{NamaGym: "Monas", Latitude: -6.1754, Longitude: 106.8272},
+		{NamaGym: "Senayan", Latitude: -6.2183, Longitude: 106.8018},
+		{NamaGym: "Bogor", Latitude: -6.5950, Longitude: 106.8166},
+		{NamaGym: "Closed", Latitude: -6.1760, Longitude: 106.8270, DeactivatedAt: &deactivatedAt},
+		{NamaGym: "Suva", Latitude: -16.5, Longitude: 179.95},
+		{NamaGym: "Taveuni", Latitude: -16.5, Longitude: -179.95},
+		{NamaGym: "No coordinates"},
+	}
+	for i := range gyms {
+		gyms[i].IdGym = uuid.New()
+		if err := db.Create(&gyms[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Rows from before coordinates were required may hold NULL.
+	nullGym := models.Gym{IdGym: uuid.New(), NamaGym: "NULL coordinates"}
+	if err := db.Create(&nullGym).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("UPDATE gyms SET latitude = NULL, longitude = NULL WHERE id = ?", nullGym.IdGym).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		request utils.NearbyRequest
+		want    []string
+	}{
+		{"nearest first", utils.NearbyRequest{Latitude: -6.1754, Longitude: 106.8272, RadiusKm: 10}, []string{"Monas", "Senayan"}},
+		{"wider radius", utils.NearbyRequest{Latitude: -6.1754, Longitude: 106.8272, RadiusKm: 50}, []string{"Monas", "Senayan", "Bogor"}},
+		{"radius stops short", utils.NearbyRequest{Latitude: -6.1754, Longitude: 106.8272, RadiusKm: 5.5}, []string{"Monas"}},
+		{"page", utils.NearbyRequest{Latitude: -6.1754, Longitude: 106.8272, RadiusKm: 50, Page: 2, Limit: 2}, []string{"Bogor"}},
+		{"east of the antimeridian", utils.NearbyRequest{Latitude: -16.5, Longitude: 179.9, RadiusKm: 20}, []string{"Suva", "Taveuni"}},
+		{"west of the antimeridian", utils.NearbyRequest{Latitude: -16.5, Longitude: -179.9, RadiusKm: 20}, []string{"Taveuni", "Suva"}},
+		{"gyms without coordinates", utils.NearbyRequest{Latitude: 0, Longitude: 0, RadiusKm: 50}, []string{}},
+		{"nothing nearby", utils.NearbyRequest{Latitude: 51.5, Longitude: -0.12, RadiusKm: 50}, []string{}},
+	}
+	service := NewGymService(db)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.request.Page == 0 {
+				test.request.Page, test.request.Limit = 1, 20
+			}
+			response, err := service.GetNearbyGym(test.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, nearby := range response.Data.(utils.Page).Items.([]formatter.NearbyGymFormat) {
+				if nearby.DistanceKm > test.request.RadiusKm {
+					t.Errorf("%s is %.2f km away, outside %.2f km", nearby.NamaGym, nearby.DistanceKm, test.request.RadiusKm)
+				}
+				got = append(got, nearby.NamaGym)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetNearbyGym = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetAllGym(t *testing.T) {
+	tests := []struct {
+		name    string
+		gyms    []bool
+		closeDB bool
+		want    int
+		wantErr string
+	}{
+		{name: "no gyms", want: 0},
+		{name: "active gyms only", gyms: []bool{true, false, true}, want: 2},
+		{name: "database failure", closeDB: true, wantErr: "internal_error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newTestDB(t)
+			for _, active := range test.gyms {
+				newTestGym(t, db, active)
+			}
+			if test.closeDB {
+				sqlDB, err := db.DB()
+				if err != nil {
+					t.Fatal(err)
+				}
+				sqlDB.Close()
+			}
+
+			response, err := NewGymService(db).GetAllGym()
+			if test.wantErr != "" {
+				var serviceErr *utils.Error
+				if !errors.As(err, &serviceErr) || serviceErr.Code != test.wantErr {
+					t.Errorf("GetAllGym error = %v, want %s", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gyms := response.Data.([]models.Gym); len(gyms) != test.want {
+				t.Errorf("GetAllGym = %d gyms, want %d", len(gyms), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/app/repositories"
+	"kalorize-api/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newKodeGym builds and saves an invitation code for gym. Codes default to
+// single use on the monthly plan; multi-use codes are unlimited unless
+// MaxRedemptions is set.
+func newKodeGym(kodeRepo repositories.KodeGymRepository, gym models.Gym, request utils.KodeGymRequest, defaultExpiry time.Time) (models.KodeGym, error) {
+	kodeGym := models.KodeGym{
+		IdKodeGym:      uuid.New(),
+		IdGym:          gym.IdGym,
+		Mode:           request.Mode,
+		Plan:           request.Plan,
+		MaxRedemptions: request.MaxRedemptions,
+		ExpiredTime:    defaultExpiry,
+		CreatedAt:      time.Now(),
+	}
+	if kodeGym.Mode == "" {
+		kodeGym.Mode = models.KodeGymSingleUse
+	}
+	if kodeGym.Plan == "" {
+		kodeGym.Plan = models.MembershipMonthly
+	}
+	if kodeGym.Mode == models.KodeGymSingleUse {
+		kodeGym.MaxRedemptions = 1
+	}
+	if request.ExpiredDays > 0 {
+		kodeGym.ExpiredTime = time.Now().AddDate(0, 0, request.ExpiredDays)
+	}
+
+	// Codes are short, so retry on the rare collision with an existing one.
+	for attempt := 0; attempt < 5; attempt++ {
+		kode := utils.GenerateKodeGym(gym.NamaGym)
+		if _, err := kodeRepo.GetKodeGymByKode(kode); err == nil {
+			continue
+		}
+		kodeGym.KodeGym = kode
+		return kodeGym, kodeRepo.CreateNewKodeGym(kodeGym)
+	}
+	return kodeGym, errors.New("could not generate a unique kode gym")
+}
+
+// revokeKodeGym stops a code from being redeemed. Revoking twice keeps the
+// original revocation time.
+func revokeKodeGym(kodeRepo repositories.KodeGymRepository, kodeGym models.KodeGym) (models.KodeGym, error) {
+	if kodeGym.RevokedAt != nil {
+		return kodeGym, nil
+	}
+	now := time.Now()
+	kodeGym.RevokedAt = &now
+	return kodeGym, kodeRepo.UpdateKodeGym(kodeGym)
+}
+
+// kodeGymStatus is KodeGym.Status, but also treats the codes of a gym that
+// is deactivated or deleted as unavailable.
+func kodeGymStatus(gymRepo repositories.GymRepository, kodeGym models.KodeGym, now time.Time) string {
+	if status := kodeGym.Status(now); status != models.KodeGymActive {
+		return status
+	}
+	gym, err := gymRepo.GetGymById(kodeGym.IdGym)
+	if err != nil || !gym.Active() {
+		return models.KodeGymGymInactive
+	}
+	return models.KodeGymActive
+}
+
+// kodeGymUnavailable explains why a code can no longer be redeemed.
+func kodeGymUnavailable(status string) error {
+	switch status {
+	case models.KodeGymExpired:
+		return utils.Conflict("kode_gym_expired")
+	case models.KodeGymRevoked:
+		return utils.Conflict("kode_gym_revoked")
+	case models.KodeGymGymInactive:
+		return utils.Conflict("gym_inactive")
+	default:
+		return utils.Conflict("kode_gym_exhausted")
+	}
+}
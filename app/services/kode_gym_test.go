@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/app/repositories"
+	"kalorize-api/config"
+	"kalorize-api/utils"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB returns an empty SQLite database with every migration applied.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestGym(t *testing.T, db *gorm.DB, active bool) models.Gym {
+	t.Helper()
+	gym := models.Gym{IdGym: uuid.New(), NamaGym: "Test Gym"}
+	if !active {
+		now := time.Now()
+		gym.DeactivatedAt = &now
+	}
+	if err := repositories.NewDBGymRepository(db).CreateNewGym(gym); err != nil {
+		t.Fatal(err)
+	}
+	return gym
+}
+
+// newTestUser saves a user with the role and returns them with their token.
+func newTestUser(t *testing.T, db *gorm.DB, role string) (models.User, string) {
+	t.Helper()
+	idUser := uuid.New()
+	user := models.User{IdUser: idUser, Fullname: role + " " + idUser.String()[:8], Email: idUser.String()[:8] + "@t.io", Role: role}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	token, err := utils.GenerateJWTAccessToken(user.IdUser, user.Fullname, user.Email, utils.JWTSecret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user, token
+}
+
+func TestKodeGymStatus(t *testing.T) {
+	db := newTestDB(t)
+	gymRepo := repositories.NewDBGymRepository(db)
+	activeGym := newTestGym(t, db, true)
+	inactiveGym := newTestGym(t, db, false)
+	now := time.Now()
+	tomorrow := now.AddDate(0, 0, 1)
+	yesterday := now.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name    string
+		kodeGym models.KodeGym
+		want    string
+	}{
+		{"unused single use", models.KodeGym{Mode: models.KodeGymSingleUse, MaxRedemptions: 1, ExpiredTime: tomorrow}, models.KodeGymActive},
+		{"used single use", models.KodeGym{Mode: models.KodeGymSingleUse, MaxRedemptions: 1, Redemptions: 1, ExpiredTime: tomorrow}, models.KodeGymExhausted},
+		{"unlimited multi use", models.KodeGym{Mode: models.KodeGymMultiUse, Redemptions: 1000, ExpiredTime: tomorrow}, models.KodeGymActive},
+		{"multi use below max", models.KodeGym{Mode: models.KodeGymMultiUse, MaxRedemptions: 3, Redemptions: 2, ExpiredTime: tomorrow}, models.KodeGymActive},
+		{"multi use at max", models.KodeGym{Mode: models.KodeGymMultiUse, MaxRedemptions: 3, Redemptions: 3, ExpiredTime: tomorrow}, models.KodeGymExhausted},
+		{"expired", models.KodeGym{Mode: models.KodeGymMultiUse, ExpiredTime: yesterday}, models.KodeGymExpired},
+		{"expiring now", models.KodeGym{Mode: models.KodeGymMultiUse, ExpiredTime: now}, models.KodeGymExpired},
+		{"revoked", models.KodeGym{Mode: models.KodeGymMultiUse, ExpiredTime: tomorrow, RevokedAt: &yesterday}, models.KodeGymRevoked},
+		{"revoked after expiring", models.KodeGym{Mode: models.KodeGymMultiUse, ExpiredTime: yesterday, RevokedAt: &now}, models.KodeGymRevoked},
+		{"deactivated gym", models.KodeGym{IdGym: inactiveGym.IdGym, Mode: models.KodeGymMultiUse, ExpiredTime: tomorrow}, models.KodeGymGymInactive},
+		{"unknown gym", models.KodeGym{IdGym: uuid.New(), Mode: models.KodeGymMultiUse, ExpiredTime: tomorrow}, models.KodeGymGymInactive},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.kodeGym.IdGym == uuid.Nil {
+				test.kodeGym.IdGym = activeGym.IdGym
+			}
+			if got := kodeGymStatus(gymRepo, test.kodeGym, now); got != test.want {
+				t.Errorf("kodeGymStatus = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestKodeGymRedemption(t *testing.T) {
+	db := newTestDB(t)
+	kodeRepo := repositories.NewDBKodeGymRepository(db)
+	gym := newTestGym(t, db, true)
+
+	tests := []struct {
+		name    string
+		request utils.KodeGymRequest
+		revoke  bool
+		expire  bool
+		// redeemed is how many of five users get a membership.
+		redeemed int
+		// Revoked and expired codes are also refused by the redemption
+		// itself; callers report why from kodeGymStatus beforehand.
+		wantErr string
+	}{
+		{name: "single use", request: utils.KodeGymRequest{}, redeemed: 1, wantErr: "kode_gym_exhausted"},
+		{name: "single use ignores max redemptions", request: utils.KodeGymRequest{Mode: models.KodeGymSingleUse, MaxRedemptions: 3}, redeemed: 1, wantErr: "kode_gym_exhausted"},
+		{name: "unlimited multi use", request: utils.KodeGymRequest{Mode: models.KodeGymMultiUse}, redeemed: 5},
+		{name: "multi use with max redemptions", request: utils.KodeGymRequest{Mode: models.KodeGymMultiUse, MaxRedemptions: 3}, redeemed: 3, wantErr: "kode_gym_exhausted"},
+		{name: "revoked", request: utils.KodeGymRequest{Mode: models.KodeGymMultiUse}, revoke: true, wantErr: "kode_gym_exhausted"},
+		{name: "expired", request: utils.KodeGymRequest{Mode: models.KodeGymMultiUse}, expire: true, wantErr: "kode_gym_exhausted"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kodeGym, err := newKodeGym(kodeRepo, gym, test.request, time.Now().AddDate(0, 0, 7))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.revoke {
+				revoked, err := revokeKodeGym(kodeRepo, kodeGym)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if again, _ := revokeKodeGym(kodeRepo, revoked); !again.RevokedAt.Equal(*revoked.RevokedAt) {
+					t.Errorf("revoking twice moved RevokedAt from %v to %v", revoked.RevokedAt, again.RevokedAt)
+				}
+			}
+			if test.expire {
+				kodeGym.ExpiredTime = time.Now().Add(-time.Minute)
+				if err := kodeRepo.UpdateKodeGym(kodeGym); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			redeemed := 0
+			var lastErr error
+			for i := 0; i < 5; i++ {
+				err := db.Transaction(func(tx *gorm.DB) error {
+					_, err := startMembership(tx, kodeGym, uuid.New(), nil)
+					return err
+				})
+				if err != nil {
+					lastErr = membershipFailed(err)
+					continue
+				}
+				redeemed++
+			}
+			if redeemed != test.redeemed {
+				t.Errorf("redeemed %d times, want %d", redeemed, test.redeemed)
+			}
+			var serviceErr *utils.Error
+			switch {
+			case test.wantErr == "" && lastErr != nil:
+				t.Errorf("redemption failed: %v", lastErr)
+			case test.wantErr != "" && (!errors.As(lastErr, &serviceErr) || serviceErr.Code != test.wantErr):
+				t.Errorf("redemption error = %v, want %s", lastErr, test.wantErr)
+			}
+
+			saved, err := kodeRepo.GetKodeGymById(kodeGym.IdKodeGym)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.Redemptions != test.redeemed {
+				t.Errorf("Redemptions = %d, want %d", saved.Redemptions, test.redeemed)
+			}
+			var usedCodes, memberships int64
+			db.Model(&models.UsedCode{}).Where("id_kode = ?", kodeGym.KodeGym).Count(&usedCodes)
+			db.Model(&models.Membership{}).Where("kode_gym = ?", kodeGym.KodeGym).Count(&memberships)
+			if usedCodes != int64(test.redeemed) || memberships != int64(test.redeemed) {
+				t.Errorf("%d used codes and %d memberships, want %d of each", usedCodes, memberships, test.redeemed)
+			}
+		})
+	}
+}
+
+func TestKodeGymDefaults(t *testing.T) {
+	db := newTestDB(t)
+	kodeRepo := repositories.NewDBKodeGymRepository(db)
+	gym := newTestGym(t, db, true)
+	defaultExpiry := time.Now().AddDate(0, 0, 7)
+
+	tests := []struct {
+		name               string
+		request            utils.KodeGymRequest
+		wantMode, wantPlan string
+		wantMax            int
+		wantExpiryDays     int
+	}{
+		{"empty request", utils.KodeGymRequest{}, models.KodeGymSingleUse, models.MembershipMonthly, 1, 7},
+		{"multi use", utils.KodeGymRequest{Mode: models.KodeGymMultiUse}, models.KodeGymMultiUse, models.MembershipMonthly, 0, 7},
+		{"yearly plan for 30 days", utils.KodeGymRequest{Plan: models.MembershipYearly, ExpiredDays: 30}, models.KodeGymSingleUse, models.MembershipYearly, 1, 30},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kodeGym, err := newKodeGym(kodeRepo, gym, test.request, defaultExpiry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kodeGym.KodeGym == "" || kodeGym.Mode != test.wantMode || kodeGym.Plan != test.wantPlan || kodeGym.MaxRedemptions != test.wantMax {
+				t.Errorf("newKodeGym = %q %s %s max %d, want a code %s %s max %d",
+					kodeGym.KodeGym, kodeGym.Mode, kodeGym.Plan, kodeGym.MaxRedemptions, test.wantMode, test.wantPlan, test.wantMax)
+			}
+			days := time.Until(kodeGym.ExpiredTime).Hours() / 24
+			if days < float64(test.wantExpiryDays)-0.01 || days > float64(test.wantExpiryDays)+0.01 {
+				t.Errorf("expires in %.2f days, want %d", days, test.wantExpiryDays)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/app/repositories"
+	"kalorize-api/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errKodeGymUsedUp      = errors.New("kode gym used up")
+	errKodeGymAlreadyUsed = errors.New("kode gym already used by this user")
+)
+
+// currentMembership returns the latest membership that has already started,
+// or nil when the user has none.
+func currentMembership(memberships []models.Membership, now time.Time) *models.Membership {
+	for i := range memberships {
+		if !now.Before(memberships[i].StartDate) {
+			return &memberships[i]
+		}
+	}
+	return nil
+}
+
+// startMembership redeems kodeGym for a user and records the used code and
+// the membership it grants. It writes through tx, the transaction the caller
+// also saves the user in, so a failure never leaves a redemption used up.
+// Renewing at the same gym before the grace period ends continues from the
+// end of the previous membership, so no paid days are lost.
+func startMembership(tx *gorm.DB, kodeGym models.KodeGym, idUser uuid.UUID, memberships []models.Membership) (models.Membership, error) {
+	kodeRepo := repositories.NewDBKodeGymRepository(tx)
+	usedCodeRepo := repositories.NewDBUsedCodeRepository(tx)
+	membershipRepo := repositories.NewDBMembershipRepository(tx)
+	now := time.Now()
+	start := now
+	for _, membership := range memberships {
+		if membership.KodeGym == kodeGym.KodeGym {
+			return models.Membership{}, errKodeGymAlreadyUsed
+		}
+		if membership.IdGym == kodeGym.IdGym && now.Before(membership.GraceUntil()) && membership.EndDate.After(start) {
+			start = membership.EndDate
+		}
+	}
+
+	redeemed, err := kodeRepo.RedeemKodeGym(kodeGym.IdKodeGym)
+	if err != nil {
+		return models.Membership{}, err
+	}
+	if !redeemed {
+		return models.Membership{}, errKodeGymUsedUp
+	}
+
+	membership := models.Membership{
+		IdMembership: uuid.New(),
+		IdUser:       idUser,
+		IdGym:        kodeGym.IdGym,
+		KodeGym:      kodeGym.KodeGym,
+		Plan:         kodeGym.Plan,
+		StartDate:    start,
+		EndDate:      utils.GetMembershipEndTime(start, kodeGym.Plan),
+		CreatedAt:    now,
+	}
+	usedCode := models.UsedCode{
+		IdGym:     kodeGym.IdGym,
+		KodeGym:   kodeGym.KodeGym,
+		IdUser:    idUser,
+		ExpiredAt: membership.EndDate,
+	}
+	if err := usedCodeRepo.CreateNewUsedCode(usedCode); err != nil {
+		return membership, err
+	}
+	return membership, membershipRepo.CreateNewMembership(membership)
+}
+
+// membershipFailed turns a startMembership error into a service error.
+func membershipFailed(err error) error {
+	switch {
+	case errors.Is(err, errKodeGymUsedUp):
+		return kodeGymUnavailable(models.KodeGymExhausted)
+	case errors.Is(err, errKodeGymAlreadyUsed):
+		return utils.Conflict("kode_gym_already_used")
+	default:
+		return utils.Internal("Membership creation failed", err)
+	}
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kalorize-api/app/models"
+	"kalorize-api/app/payment"
+	"kalorize-api/app/repositories"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// activeOrderStatuses are the statuses shown in a franchise's order queue
+// unless others are asked for.
+var activeOrderStatuses = []string{models.OrderPlaced, models.OrderAccepted, models.OrderReady}
+
+type orderService struct {
+	userRepo        repositories.UserRepository
+	franchiseRepo   repositories.FranchiseRepository
+	makananRepo     repositories.MakananRepository
+	cartRepo        repositories.CartRepository
+	orderRepo       repositories.OrderRepository
+	historyRepo     repositories.HistoryRepository
+	paymentProvider payment.Provider
+}
+
+func (service *orderService) member(bearerToken string) (models.User, error) {
+	email, err := utils.ParseDataEmail(bearerToken)
+	if email == "" || err != nil {
+		return models.User{}, utils.Unauthorized("unauthorized")
+	}
+	user, err := service.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return user, utils.Unauthorized("unauthorized")
+	}
+	return user, nil
+}
+
+// cart prices the user's cart against the franchise's current menu.
+func (service *orderService) cart(idUser uuid.UUID) (formatter.CartFormat, error) {
+	cart := formatter.CartFormat{Items: []formatter.CartItemFormat{}}
+	cartItems, err := service.cartRepo.GetCartByIdUser(idUser)
+	if err != nil {
+		return cart, err
+	}
+	now := time.Now()
+	for _, cartItem := range cartItems {
+		idFranchise := cartItem.IdFranchise
+		cart.IdFranchise = &idFranchise
+		// An item deleted or taken off the menu since it was added stays in
+		// the cart as unavailable, so the user can see why the order is
+		// refused and remove it.
+		makanan, err := service.makananRepo.GetMakananById(cartItem.IdMakanan)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			cart.Items = append(cart.Items, formatter.CartItemFormat{
+				IdMakanan:   cartItem.IdMakanan,
+				NamaMakanan: cartItem.IdMakanan,
+				Jumlah:      cartItem.Jumlah,
+			})
+			continue
+		}
+		if err != nil {
+			return cart, err
+		}
+		item := formatter.CartItemFormat{
+			IdMakanan:   makanan.IdMakanan,
+			NamaMakanan: makanan.Nama,
+			Jumlah:      cartItem.Jumlah,
+			Kalori:      makanan.Kalori,
+			Protein:     makanan.Protein,
+		}
+		franchiseMakanan, err := service.franchiseRepo.GetFranchiseMakanan(cartItem.IdFranchise, cartItem.IdMakanan)
+		if err == nil {
+			item.Harga = franchiseMakanan.Harga
+			item.Tersedia = franchiseMakanan.Available(now)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return cart, err
+		}
+		cart.Items = append(cart.Items, item)
+		cart.TotalHarga += item.Harga * item.Jumlah
+		cart.TotalKalori += item.Kalori * item.Jumlah
+		cart.TotalProtein += item.Protein * item.Jumlah
+	}
+	return cart, nil
+}
+
+func (service *orderService) GetCart(bearerToken string) (utils.Response, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	cart, err := service.cart(user.IdUser)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get cart", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: cart}, nil
+}
+
+// SetCartItem sets how many of a makanan are in the cart. Zero removes it.
+func (service *orderService) SetCartItem(bearerToken string, cartItemRequest utils.CartItemRequest) (utils.Response, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	if cartItemRequest.Jumlah == 0 {
+		if err := service.cartRepo.DeleteCartItem(user.IdUser, cartItemRequest.IdMakanan); err != nil {
+			return utils.Response{}, utils.Internal("Failed to update cart", err)
+		}
+		return service.GetCart(bearerToken)
+	}
+
+	if _, err := service.franchiseRepo.GetFranchiseById(cartItemRequest.IdFranchise.String()); err != nil {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+	franchiseMakanan, err := service.franchiseRepo.GetFranchiseMakanan(cartItemRequest.IdFranchise, cartItemRequest.IdMakanan)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("menu_item_not_found")
+	}
+	if !franchiseMakanan.Available(time.Now()) {
+		makanan, err := service.makananRepo.GetMakananById(cartItemRequest.IdMakanan)
+		if err != nil {
+			return utils.Response{}, utils.Internal("Failed to get makanan", err)
+		}
+		return utils.Response{}, utils.Conflict("makanan_unavailable", makanan.Nama)
+	}
+	cartItems, err := service.cartRepo.GetCartByIdUser(user.IdUser)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get cart", err)
+	}
+	for _, cartItem := range cartItems {
+		if cartItem.IdFranchise != cartItemRequest.IdFranchise {
+			return utils.Response{}, utils.Conflict("cart_other_franchise")
+		}
+	}
+
+	err = service.cartRepo.SaveCartItem(models.CartItem{
+		IdUser:      user.IdUser,
+		IdMakanan:   cartItemRequest.IdMakanan,
+		IdFranchise: cartItemRequest.IdFranchise,
+		Jumlah:      cartItemRequest.Jumlah,
+	})
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to update cart", err)
+	}
+	return service.GetCart(bearerToken)
+}
+
+func (service *orderService) ClearCart(bearerToken string) (utils.Response, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	if err := service.cartRepo.ClearCart(user.IdUser); err != nil {
+		return utils.Response{}, utils.Internal("Failed to clear cart", err)
+	}
+	return service.GetCart(bearerToken)
+}
+
+// PlaceOrder turns the cart into an order at the current menu prices, takes
+// payment and empties the cart. Retrying with the same idempotency key
+// returns the order placed the first time without charging again.
+func (service *orderService) PlaceOrder(bearerToken string, orderRequest utils.OrderRequest) (utils.Response, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	var idempotencyKey *string
+	if orderRequest.IdempotencyKey != "" {
+		idempotencyKey = &orderRequest.IdempotencyKey
+		if placed, err := service.orderRepo.GetOrderByIdempotencyKey(user.IdUser, *idempotencyKey); err == nil {
+			return service.placedOrder(placed)
+		}
+	}
+	cart, err := service.cart(user.IdUser)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get cart", err)
+	}
+	if len(cart.Items) == 0 {
+		return utils.Response{}, utils.Invalid("cart_empty")
+	}
+	if _, err := service.franchiseRepo.GetFranchiseById(cart.IdFranchise.String()); err != nil {
+		return utils.Response{}, utils.NotFound("franchise_not_found")
+	}
+
+	now := time.Now()
+	order := models.Order{
+		IdOrder:         uuid.New(),
+		IdUser:          user.IdUser,
+		IdFranchise:     *cart.IdFranchise,
+		Status:          models.OrderPlaced,
+		WaktuMakan:      orderRequest.WaktuMakan,
+		Catatan:         orderRequest.Catatan,
+		TotalHarga:      cart.TotalHarga,
+		TotalKalori:     cart.TotalKalori,
+		TotalProtein:    cart.TotalProtein,
+		PaymentProvider: service.paymentProvider.Name(),
+		IdempotencyKey:  idempotencyKey,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	orderItems := make([]models.OrderItem, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		if !item.Tersedia {
+			return utils.Response{}, utils.Conflict("makanan_unavailable", item.NamaMakanan)
+		}
+		orderItems = append(orderItems, models.OrderItem{
+			IdOrderItem: uuid.New(),
+			IdOrder:     order.IdOrder,
+			IdMakanan:   item.IdMakanan,
+			NamaMakanan: item.NamaMakanan,
+			Jumlah:      item.Jumlah,
+			Harga:       item.Harga,
+			Kalori:      item.Kalori,
+			Protein:     item.Protein,
+		})
+	}
+
+	reference, err := service.paymentProvider.Charge(context.Background(), payment.Charge{
+		IdOrder:     order.IdOrder,
+		IdUser:      user.IdUser,
+		Amount:      order.TotalHarga,
+		Description: fmt.Sprintf("Kalorize order %s", order.IdOrder),
+	})
+	if errors.Is(err, payment.ErrDeclined) {
+		return utils.Response{}, utils.PaymentRequired("payment_declined")
+	}
+	if err != nil {
+		return utils.Response{}, utils.Internal("Payment failed", err)
+	}
+	order.PaymentReference = reference
+
+	if err := service.orderRepo.PlaceOrder(order, orderItems); err != nil {
+		if refundErr := service.paymentProvider.Refund(context.Background(), reference); refundErr != nil {
+			slog.Error("refund failed", "order", order.IdOrder, "payment_reference", reference, "error", refundErr)
+		}
+		// A concurrent retry with the same key may have placed it first.
+		if idempotencyKey != nil {
+			if placed, err := service.orderRepo.GetOrderByIdempotencyKey(user.IdUser, *idempotencyKey); err == nil {
+				return service.placedOrder(placed)
+			}
+		}
+		return utils.Response{}, utils.Internal("Failed to create order", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formatter.OrderFormat{Order: order, Items: orderItems}}, nil
+}
+
+// placedOrder answers a retried PlaceOrder with the order placed the first
+// time.
+func (service *orderService) placedOrder(order models.Order) (utils.Response, error) {
+	orders, err := service.formatOrders([]models.Order{order})
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get order", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: orders[0]}, nil
+}
+
+func (service *orderService) formatOrders(orders []models.Order) ([]formatter.OrderFormat, error) {
+	idOrders := make([]uuid.UUID, 0, len(orders))
+	for _, order := range orders {
+		idOrders = append(idOrders, order.IdOrder)
+	}
+	orderItems, err := service.orderRepo.GetOrderItemsByIdOrders(idOrders)
+	if err != nil {
+		return nil, err
+	}
+	return formatter.FormatterOrders(orders, orderItems), nil
+}
+
+func (service *orderService) GetOrders(bearerToken string) (utils.Response, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	orders, err := service.orderRepo.GetOrdersByIdUser(user.IdUser)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get order", err)
+	}
+	ordersFormatted, err := service.formatOrders(orders)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get order", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: ordersFormatted}, nil
+}
+
+func (service *orderService) memberOrder(bearerToken string, idOrder uuid.UUID) (models.Order, error) {
+	user, err := service.member(bearerToken)
+	if err != nil {
+		return models.Order{}, err
+	}
+	order, err := service.orderRepo.GetOrderById(idOrder)
+	if err != nil || order.IdUser != user.IdUser {
+		return order, utils.NotFound("order_not_found")
+	}
+	return order, nil
+}
+
+func (service *orderService) GetOrderById(bearerToken string, idOrder uuid.UUID) (utils.Response, error) {
+	order, err := service.memberOrder(bearerToken, idOrder)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	ordersFormatted, err := service.formatOrders([]models.Order{order})
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get order", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: ordersFormatted[0]}, nil
+}
+
+// CancelOrder lets a member cancel an order the franchise has not accepted
+// yet.
+func (service *orderService) CancelOrder(bearerToken string, idOrder uuid.UUID) (utils.Response, error) {
+	order, err := service.memberOrder(bearerToken, idOrder)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	if order.Status == models.OrderCancelled {
+		return utils.Response{}, utils.Conflict("order_cancelled")
+	}
+	if order.Status != models.OrderPlaced {
+		return utils.Response{}, utils.Conflict("order_in_progress")
+	}
+	return service.transition(order, models.OrderCancelled)
+}
+
+func (service *orderService) GetFranchiseOrders(bearerToken string, statuses []string) (utils.Response, error) {
+	franchise, err := franchiseOperator(service.franchiseRepo, bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	if len(statuses) == 0 {
+		statuses = activeOrderStatuses
+	}
+	orders, err := service.orderRepo.GetOrdersByIdFranchise(franchise.IdFranchise, statuses)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get order", err)
+	}
+	ordersFormatted, err := service.formatOrders(orders)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get order", err)
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: ordersFormatted}, nil
+}
+
+// UpdateFranchiseOrderStatus moves one of the franchise's own orders along
+// its lifecycle.
+func (service *orderService) UpdateFranchiseOrderStatus(bearerToken string, idOrder uuid.UUID, status string) (utils.Response, error) {
+	franchise, err := franchiseOperator(service.franchiseRepo, bearerToken)
+	if err != nil {
+		return utils.Response{}, err
+	}
+	order, err := service.orderRepo.GetOrderById(idOrder)
+	if err != nil || order.IdFranchise != franchise.IdFranchise {
+		return utils.Response{}, utils.NotFound("order_not_found")
+	}
+	return service.transition(order, status)
+}
+
+// transition applies a status change and its side effects: a cancelled order
+// is refunded and a picked up order is added to the member's food log.
+func (service *orderService) transition(order models.Order, status string) (utils.Response, error) {
+	if !order.CanTransition(status) {
+		return utils.Response{}, utils.Conflict("order_status_transition", order.Status, status)
+	}
+	updated, err := service.orderRepo.UpdateOrderStatus(order.IdOrder, order.Status, status)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to update order status", err)
+	}
+	if !updated {
+		return utils.Response{}, utils.Conflict("order_status_changed")
+	}
+	order.Status = status
+	order.UpdatedAt = time.Now()
+
+	orderItems, err := service.orderRepo.GetOrderItemsByIdOrders([]uuid.UUID{order.IdOrder})
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get order", err)
+	}
+	switch status {
+	case models.OrderCancelled:
+		if err := service.paymentProvider.Refund(context.Background(), order.PaymentReference); err != nil {
+			slog.Error("refund failed", "order", order.IdOrder, "payment_reference", order.PaymentReference, "error", err)
+		}
+	case models.OrderPickedUp:
+		if err := service.logOrder(order, orderItems); err != nil {
+			slog.Error("adding order to food log failed", "order", order.IdOrder, "error", err)
+		}
+	}
+	return utils.Response{StatusCode: 200, Messages: "success", Data: formatter.OrderFormat{Order: order, Items: orderItems}}, nil
+}
+
+// logOrder adds a picked up order to the member's food log for today. The
+// order's first makanan fills its meal slot if that slot is still empty.
+func (service *orderService) logOrder(order models.Order, orderItems []models.OrderItem) error {
+	t := time.Now()
+	tanggal := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	history, err := service.historyRepo.GetHistoryByIdUserAndDate(order.IdUser, tanggal)
+	if err != nil {
+		return err
+	}
+	isNew := history.IdHistory == uuid.Nil
+	if isNew {
+		history = models.History{IdHistory: uuid.New(), IdUser: order.IdUser, TanggalDibuat: tanggal}
+	}
+	history.TotalKalori += order.TotalKalori
+	history.TotalProtein += order.TotalProtein
+	if len(orderItems) > 0 {
+		idMakanan := orderItems[0].IdMakanan
+		switch {
+		case order.WaktuMakan == "breakfast" && history.IdBreakfast == "":
+			history.IdBreakfast = idMakanan
+		case order.WaktuMakan == "lunch" && history.IdLunch == "":
+			history.IdLunch = idMakanan
+		case order.WaktuMakan == "dinner" && history.IdDinner == "":
+			history.IdDinner = idMakanan
+		}
+	}
+	if isNew {
+		return service.historyRepo.CreateHistory(history)
+	}
+	return service.historyRepo.UpdateHistory(history)
+}
+
+type OrderService interface {
+	GetCart(bearerToken string) (utils.Response, error)
+	SetCartItem(bearerToken string, cartItemRequest utils.CartItemRequest) (utils.Response, error)
+	ClearCart(bearerToken string) (utils.Response, error)
+	PlaceOrder(bearerToken string, orderRequest utils.OrderRequest) (utils.Response, error)
+	GetOrders(bearerToken string) (utils.Response, error)
+	GetOrderById(bearerToken string, idOrder uuid.UUID) (utils.Response, error)
+	CancelOrder(bearerToken string, idOrder uuid.UUID) (utils.Response, error)
+	GetFranchiseOrders(bearerToken string, statuses []string) (utils.Response, error)
+	UpdateFranchiseOrderStatus(bearerToken string, idOrder uuid.UUID, status string) (utils.Response, error)
+}
+
+func NewOrderService(db *gorm.DB, paymentProvider payment.Provider) OrderService {
+	return &orderService{
+		userRepo:        repositories.NewDBUserRepository(db),
+		franchiseRepo:   repositories.NewDBFranchiseRepository(db),
+		makananRepo:     repositories.NewDBMakananRepository(db),
+		cartRepo:        repositories.NewDBCartRepository(db),
+		orderRepo:       repositories.NewDBOrderRepository(db),
+		historyRepo:     repositories.NewDBHistoryRepository(db),
+		paymentProvider: paymentProvider,
+	}
+}
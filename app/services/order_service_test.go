@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"kalorize-api/app/models"
+	"kalorize-api/app/payment"
+	"kalorize-api/formatter"
+	"kalorize-api/utils"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// recordingProvider remembers the references of the charges it made.
+type recordingProvider struct {
+	*payment.FakeProvider
+	references []string
+}
+
+func (provider *recordingProvider) Charge(ctx context.Context, charge payment.Charge) (string, error) {
+	reference, err := provider.FakeProvider.Charge(ctx, charge)
+	if err == nil {
+		provider.references = append(provider.references, reference)
+	}
+	return reference, err
+}
+
+// newTestCart saves a member with two of a 20000 rupiah makanan in their cart
+// and returns their token.
+func newTestCart(t *testing.T, db *gorm.DB) (models.User, string) {
+	t.Helper()
+	user := models.User{IdUser: uuid.New(), Fullname: "Member", Email: "member@t.io", Role: "user"}
+	franchise := models.Franchise{IdFranchise: uuid.New(), NamaFranchise: "Sehat"}
+	makanan := models.Makanan{IdMakanan: "1", Nama: "Nasi Merah", Kalori: 300, Protein: 10}
+	fixtures := []interface{}{
+		&user,
+		&franchise,
+		&makanan,
+		&models.FranchiseMakanan{IdFranchiseMakanan: uuid.New(), IdFranchise: franchise.IdFranchise, IdMakanan: makanan.IdMakanan, Harga: 20000, Tersedia: true},
+		&models.CartItem{IdUser: user.IdUser, IdFranchise: franchise.IdFranchise, IdMakanan: makanan.IdMakanan, Jumlah: 2},
+	}
+	for _, fixture := range fixtures {
+		if err := db.Create(fixture).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	token, err := utils.GenerateJWTAccessToken(user.IdUser, user.Fullname, user.Email, utils.JWTSecret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user, token
+}
+
+func TestPlaceOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		decline bool
+		// breakDB makes saving the order fail after payment was taken.
+		breakDB bool
+		// deleteMakanan deletes the makanan in the cart before ordering.
+		deleteMakanan bool
+		wantErr       string
+		wantCharges   int
+		wantRefunded  bool
+		wantOrders    int64
+		wantCart      int64
+	}{
+		{name: "declined", decline: true, wantErr: "payment_declined", wantCart: 1},
+		{name: "database failure", breakDB: true, wantErr: "internal_error", wantCharges: 1, wantRefunded: true, wantCart: 1},
+		{name: "deleted makanan", deleteMakanan: true, wantErr: "makanan_unavailable", wantCart: 1},
+		{name: "placed", wantCharges: 1, wantOrders: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := newTestDB(t)
+			user, token := newTestCart(t, db)
+			provider := &recordingProvider{FakeProvider: payment.NewFakeProvider()}
+			provider.Decline = test.decline
+			service := NewOrderService(db, provider)
+			if test.breakDB {
+				if err := db.Migrator().DropTable(&models.OrderItem{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if test.deleteMakanan {
+				if err := db.Delete(&models.Makanan{}, "id = ?", "1").Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			response, err := service.PlaceOrder(token, utils.OrderRequest{WaktuMakan: "lunch"})
+			var serviceErr *utils.Error
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Fatalf("PlaceOrder: %v", err)
+			case test.wantErr != "" && (!errors.As(err, &serviceErr) || serviceErr.Code != test.wantErr):
+				t.Fatalf("PlaceOrder error = %v, want %s", err, test.wantErr)
+			case test.breakDB && errors.Unwrap(serviceErr.Err) == nil:
+				t.Error("PlaceOrder dropped the database error")
+			}
+			if test.wantErr == "" {
+				order := response.Data.(formatter.OrderFormat)
+				if order.TotalHarga != 40000 || len(order.Items) != 1 || order.PaymentReference == "" {
+					t.Errorf("order = %+v, want 40000 for one item with a payment reference", order)
+				}
+			}
+
+			if len(provider.references) != test.wantCharges {
+				t.Fatalf("%d charges, want %d", len(provider.references), test.wantCharges)
+			}
+			for _, reference := range provider.references {
+				if provider.Refunded(reference) != test.wantRefunded {
+					t.Errorf("Refunded(%s) = %v, want %v", reference, !test.wantRefunded, test.wantRefunded)
+				}
+			}
+			var orders, cartItems int64
+			db.Model(&models.Order{}).Where("id_user = ?", user.IdUser).Count(&orders)
+			db.Model(&models.CartItem{}).Where("id_user = ?", user.IdUser).Count(&cartItems)
+			if orders != test.wantOrders || cartItems != test.wantCart {
+				t.Errorf("%d orders and %d cart items, want %d and %d", orders, cartItems, test.wantOrders, test.wantCart)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderIdempotency(t *testing.T) {
+	db := newTestDB(t)
+	user, token := newTestCart(t, db)
+	provider := &recordingProvider{FakeProvider: payment.NewFakeProvider()}
+	service := NewOrderService(db, provider)
+
+	place := func(key string) (formatter.OrderFormat, error) {
+		response, err := service.PlaceOrder(token, utils.OrderRequest{IdempotencyKey: key})
+		if err != nil {
+			return formatter.OrderFormat{}, err
+		}
+		return response.Data.(formatter.OrderFormat), nil
+	}
+	first, err := place("key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	retried, err := place("key-1")
+	if err != nil {
+		t.Fatalf("retrying with the same key: %v", err)
+	}
+	if retried.IdOrder != first.IdOrder || len(retried.Items) != len(first.Items) {
+		t.Errorf("retry returned order %s with %d items, want %s with %d", retried.IdOrder, len(retried.Items), first.IdOrder, len(first.Items))
+	}
+	if len(provider.references) != 1 {
+		t.Errorf("%d charges, want 1", len(provider.references))
+	}
+
+	// The cart was emptied by the first order, so a new key orders nothing.
+	var serviceErr *utils.Error
+	if _, err := place("key-2"); !errors.As(err, &serviceErr) || serviceErr.Code != "cart_empty" {
+		t.Errorf("new key with an empty cart: error = %v, want cart_empty", err)
+	}
+
+	// A key only finds the orders of the user who sent it.
+	other := models.User{IdUser: uuid.New(), Fullname: "Other", Email: "other@t.io", Role: "user"}
+	if err := db.Create(&other).Error; err != nil {
+		t.Fatal(err)
+	}
+	otherToken, err := utils.GenerateJWTAccessToken(other.IdUser, other.Fullname, other.Email, utils.JWTSecret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.PlaceOrder(otherToken, utils.OrderRequest{IdempotencyKey: "key-1"}); !errors.As(err, &serviceErr) || serviceErr.Code != "cart_empty" {
+		t.Errorf("another user's key: error = %v, want cart_empty", err)
+	}
+	var orders int64
+	db.Model(&models.Order{}).Where("id_user = ?", user.IdUser).Count(&orders)
+	if orders != 1 {
+		t.Errorf("%d orders, want 1", orders)
+	}
+}
+
+func TestGetCartWithDeletedMakanan(t *testing.T) {
+	db := newTestDB(t)
+	_, token := newTestCart(t, db)
+	if err := db.Delete(&models.Makanan{}, "id = ?", "1").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := NewOrderService(db, payment.NewFakeProvider()).GetCart(token)
+	if err != nil {
+		t.Fatalf("GetCart: %v", err)
+	}
+	cart := response.Data.(formatter.CartFormat)
+	if len(cart.Items) != 1 || cart.Items[0].IdMakanan != "1" || cart.Items[0].Tersedia || cart.TotalHarga != 0 {
+		t.Errorf("cart = %+v, want the deleted makanan as one unavailable line", cart)
+	}
+}
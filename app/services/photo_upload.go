@@ -0,0 +1,55 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"kalorize-api/app/storage"
+	"kalorize-api/utils"
+)
+
+type photoUrls struct {
+	Original  string
+	Medium    string
+	Thumbnail string
+}
+
+// uploadPhoto validates and resizes an uploaded photo, then stores every
+// variant under images/ with content-addressed names. It returns the
+// filename of the original variant and the public URL of each variant.
+func uploadPhoto(fileStorage storage.Storage, photo utils.UploadedPhoto) (string, photoUrls, error) {
+	var urls photoUrls
+	processed, err := utils.ProcessPhoto(photo.File)
+	if err != nil {
+		return "", urls, err
+	}
+	variants := []struct {
+		variant utils.PhotoVariant
+		url     *string
+	}{
+		{processed.Original, &urls.Original},
+		{processed.Medium, &urls.Medium},
+		{processed.Thumbnail, &urls.Thumbnail},
+	}
+	for _, v := range variants {
+		key := "images/" + processed.Hash + "_" + v.variant.Name + v.variant.Extension
+		err := fileStorage.Put(context.Background(), key, bytes.NewReader(v.variant.Data), storage.PutOptions{ContentType: v.variant.ContentType, Public: true})
+		if err != nil {
+			return "", urls, err
+		}
+		*v.url = fileStorage.URL(key)
+	}
+	return processed.Hash + "_original" + processed.Original.Extension, urls, nil
+}
+
+// photoUploadFailed turns an uploadPhoto error into a service error,
+// reporting rejected images as client errors.
+func photoUploadFailed(err error) error {
+	if errors.Is(err, utils.ErrPhotoTooLarge) {
+		return utils.Invalid("photo_too_large")
+	}
+	if errors.Is(err, utils.ErrPhotoUnsupported) {
+		return utils.Invalid("photo_unsupported")
+	}
+	return utils.Internal("Failed to upload photo", err)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"kalorize-api/app/repositories"
+	"kalorize-api/formatter"
 	"kalorize-api/utils"
 
 	"gorm.io/gorm"
@@ -11,7 +12,7 @@ type questionnaireService struct {
 	questionnaireRepo repositories.UserRepository
 }
 type QuestionnaireService interface {
-	FillQuestionnaire(questionnaireRequest utils.UserRequest) utils.Response
+	FillQuestionnaire(questionnaireRequest utils.UserRequest) (utils.Response, error)
 }
 
 func NewQuestionnaireService(db *gorm.DB) QuestionnaireService {
@@ -20,54 +21,27 @@ func NewQuestionnaireService(db *gorm.DB) QuestionnaireService {
 	}
 }
 
-func (service *questionnaireService) FillQuestionnaire(questionnaireRequest utils.UserRequest) utils.Response {
+func (service *questionnaireService) FillQuestionnaire(questionnaireRequest utils.UserRequest) (utils.Response, error) {
 	var response utils.Response
+	if err := utils.Validate(questionnaireRequest); err != nil {
+		return utils.Response{}, err
+	}
 	var user, err = service.questionnaireRepo.GetUserById(questionnaireRequest.IdUser)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "User is not found"
-		response.Data = nil
-		return response
-	}
-	if questionnaireRequest.Umur < 0 || questionnaireRequest.Umur > 100 {
-		response.StatusCode = 500
-		response.Messages = "Umur is not valid"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.NotFound("user_not_found")
 	}
 	user.Umur = questionnaireRequest.Umur
 	user.BeratBadan = questionnaireRequest.BeratBadan
 	user.TinggiBadan = questionnaireRequest.TinggiBadan
-	if questionnaireRequest.JenisKelamin > 1 || questionnaireRequest.JenisKelamin < 0 {
-		response.StatusCode = 500
-		response.Messages = "Jenis kelamin is not valid"
-		response.Data = nil
-		return response
-	}
 	user.JenisKelamin = questionnaireRequest.JenisKelamin
-	if questionnaireRequest.FrekuensiGym > 3 || questionnaireRequest.FrekuensiGym < 0 {
-		response.StatusCode = 500
-		response.Messages = "Frekuensi gym is not valid"
-		response.Data = nil
-		return response
-	}
 	user.FrekuensiGym = questionnaireRequest.FrekuensiGym
-	if questionnaireRequest.TargetKalori > 2 || questionnaireRequest.TargetKalori < 0 {
-		response.StatusCode = 500
-		response.Messages = "Target kalori is not valid"
-		response.Data = nil
-		return response
-	}
 	user.TargetKalori = questionnaireRequest.TargetKalori
 	err = service.questionnaireRepo.UpdateUser(user)
 	if err != nil {
-		response.StatusCode = 500
-		response.Messages = "Failed to fill questionnaire"
-		response.Data = nil
-		return response
+		return utils.Response{}, utils.Internal("Failed to fill questionnaire", err)
 	}
 	response.StatusCode = 200
-	response.Messages = "Success"
-	response.Data = user
-	return response
+	response.Messages = "success"
+	response.Data = formatter.FormatterUser(user)
+	return response, nil
 }
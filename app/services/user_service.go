@@ -1,63 +1,68 @@
 package services
 
 import (
-	"context"
-	"fmt"
-	"io"
+	"kalorize-api/app/metrics"
 	"kalorize-api/app/models"
 	"kalorize-api/app/repositories"
+	"kalorize-api/app/storage"
 	"kalorize-api/formatter"
 	"kalorize-api/utils"
-	"path/filepath"
 	"reflect"
 	"time"
 
-	"cloud.google.com/go/storage"
-	firebase "firebase.google.com/go"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
-	"google.golang.org/api/option"
 	"gorm.io/gorm"
 )
 
 type UserService interface {
-	GetHistory(token string, date time.Time) utils.Response
-	CreateHistory(token string, historyPayload utils.HistoryRequest) utils.Response
-	EditUser(token string, payload utils.UserRequest) utils.Response
-	EditPassword(token string, payload utils.UserRequest, oldPassword string) utils.Response
-	EditPhoto(token string, payload utils.UploadedPhoto) utils.Response
+	GetHistory(token string, date time.Time) (utils.Response, error)
+	CreateHistory(token string, historyPayload utils.HistoryRequest) (utils.Response, error)
+	EditUser(token string, payload utils.UserRequest) (utils.Response, error)
+	EditPassword(token string, payload utils.UserRequest, oldPassword string) (utils.Response, error)
+	EditPhoto(token string, payload utils.UploadedPhoto) (utils.Response, error)
+	GetMembership(token string) (utils.Response, error)
+	RenewMembership(token string, gymKode string) (utils.Response, error)
+	DeleteAccount(token string, password string) (utils.Response, error)
 }
 
 type userService struct {
-	userRepository     repositories.UserRepository
-	historyRepository  repositories.HistoryRepository
-	makananrRepository repositories.MakananRepository
+	db                   *gorm.DB
+	userRepository       repositories.UserRepository
+	historyRepository    repositories.HistoryRepository
+	makananrRepository   repositories.MakananRepository
+	kodeGymRepository    repositories.KodeGymRepository
+	gymRepository        repositories.GymRepository
+	membershipRepository repositories.MembershipRepository
+	fileStorage          storage.Storage
+	deletionGrace        time.Duration
 }
 
-func NewUserService(db *gorm.DB) UserService {
+func NewUserService(db *gorm.DB, fileStorage storage.Storage, deletionGrace time.Duration) UserService {
 	return &userService{
-		userRepository:     repositories.NewDBUserRepository(db),
-		historyRepository:  repositories.NewDBHistoryRepository(db),
-		makananrRepository: repositories.NewDBMakananRepository(db),
+		db:                   db,
+		userRepository:       repositories.NewDBUserRepository(db),
+		historyRepository:    repositories.NewDBHistoryRepository(db),
+		makananrRepository:   repositories.NewDBMakananRepository(db),
+		kodeGymRepository:    repositories.NewDBKodeGymRepository(db),
+		gymRepository:        repositories.NewDBGymRepository(db),
+		membershipRepository: repositories.NewDBMembershipRepository(db),
+		fileStorage:          fileStorage,
+		deletionGrace:        deletionGrace,
 	}
 }
 
-func (service *userService) CreateHistory(token string, historyPayload utils.HistoryRequest) utils.Response {
+func (service *userService) CreateHistory(token string, historyPayload utils.HistoryRequest) (utils.Response, error) {
+	if err := utils.Validate(historyPayload); err != nil {
+		return utils.Response{}, err
+	}
 	emailUser, err := utils.ParseDataEmail(token)
 	if err != nil || emailUser == "" {
-		return utils.Response{
-			StatusCode: 401,
-			Messages:   "Unauthorized",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	user, err := service.userRepository.GetUserByEmail(emailUser)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get user",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get user", err)
 	}
 	t := time.Now()
 	tanggal := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
@@ -65,11 +70,7 @@ func (service *userService) CreateHistory(token string, historyPayload utils.His
 	// Check if history already exists for the user on the same date
 	existingHistory, err := service.historyRepository.GetHistoryByIdUserAndDate(user.IdUser, tanggal)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to check existing history",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to check existing history", err)
 	}
 
 	// If history exists, update it; otherwise, create a new one
@@ -81,17 +82,14 @@ func (service *userService) CreateHistory(token string, historyPayload utils.His
 		existingHistory.TotalKalori = historyPayload.TotalKalori
 		err = service.historyRepository.UpdateHistory(existingHistory)
 		if err != nil {
-			return utils.Response{
-				StatusCode: 500,
-				Messages:   "Failed to update history",
-				Data:       nil,
-			}
+			return utils.Response{}, utils.Internal("Failed to update history", err)
 		}
+		metrics.RecordHistoryWrite(false)
 		return utils.Response{
 			StatusCode: 200,
-			Messages:   "History updated successfully",
+			Messages:   "history_updated",
 			Data:       existingHistory,
-		}
+		}, nil
 	}
 
 	// Create new history if no existing history found for the user on the same date
@@ -107,102 +105,70 @@ func (service *userService) CreateHistory(token string, historyPayload utils.His
 	}
 	err = service.historyRepository.CreateHistory(newHistory)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to create history",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to create history", err)
 	}
+	metrics.RecordHistoryWrite(true)
 	return utils.Response{
 		StatusCode: 200,
-		Messages:   "New history created successfully",
+		Messages:   "history_created",
 		Data:       newHistory,
-	}
+	}, nil
 }
 
-func (service *userService) GetHistory(token string, date time.Time) utils.Response {
+func (service *userService) GetHistory(token string, date time.Time) (utils.Response, error) {
 	emailUser, err := utils.ParseDataEmail(token)
 	if err != nil || emailUser == "" {
-		return utils.Response{
-			StatusCode: 401,
-			Messages:   "Unauthorized",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	user, err := service.userRepository.GetUserByEmail(emailUser)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get user",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get user", err)
 	}
 
 	history, err := service.historyRepository.GetHistoryByIdUserAndDate(user.IdUser, date)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get history",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get history", err)
 	}
 	breakfast, err := service.makananrRepository.GetMakananById(history.IdBreakfast)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get breakfast",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get breakfast", err)
 	}
-	formattedBreakfast := formatter.FormatterMakananIndo(breakfast)
+	formattedBreakfast := formatter.FormatterMakanan(breakfast)
 	lunch, err := service.makananrRepository.GetMakananById(history.IdLunch)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get lunch",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get lunch", err)
 	}
-	formattedLunch := formatter.FormatterMakananIndo(lunch)
+	formattedLunch := formatter.FormatterMakanan(lunch)
 	dinner, err := service.makananrRepository.GetMakananById(history.IdDinner)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get dinner",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get dinner", err)
 	}
-	formattedDinner := formatter.FormatterMakananIndo(dinner)
+	formattedDinner := formatter.FormatterMakanan(dinner)
 	var response utils.Response
 	response.StatusCode = 200
-	response.Messages = "Success"
-	response.Data = map[string]interface{}{
-		"breakfast":    formattedBreakfast,
-		"lunch":        formattedLunch,
-		"dinner":       formattedDinner,
-		"totalKalori":  history.TotalKalori,
-		"totalProtein": history.TotalProtein,
+	response.Messages = "success"
+	response.Data = formatter.HistoryFormat{
+		Breakfast:    formattedBreakfast,
+		Lunch:        formattedLunch,
+		Dinner:       formattedDinner,
+		TotalKalori:  history.TotalKalori,
+		TotalProtein: history.TotalProtein,
 	}
 
-	return response
+	return response, nil
 }
 
-func (service *userService) EditUser(token string, payload utils.UserRequest) utils.Response {
+func (service *userService) EditUser(token string, payload utils.UserRequest) (utils.Response, error) {
+	if err := utils.Validate(payload); err != nil {
+		return utils.Response{}, err
+	}
 	nameUser, err := utils.ParseDataId(token)
 	if err != nil && nameUser == uuid.Nil {
-		return utils.Response{
-			StatusCode: 401,
-			Messages:   "Unauthorized",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	user, err := service.userRepository.GetUserById(nameUser)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get user",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get user", err)
 	}
 	validateAndAssign(&user.Fullname, payload.Fullname)
 	validateAndAssign(&user.Email, payload.Email)
@@ -210,17 +176,13 @@ func (service *userService) EditUser(token string, payload utils.UserRequest) ut
 
 	err = service.userRepository.UpdateUser(user)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to edit user",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to edit user", err)
 	}
 	return utils.Response{
 		StatusCode: 200,
-		Messages:   "Success",
-		Data:       user,
-	}
+		Messages:   "success",
+		Data:       formatter.FormatterUser(user),
+	}, nil
 }
 
 func validateAndAssign(target interface{}, source interface{}) {
@@ -243,154 +205,154 @@ func validateAndAssign(target interface{}, source interface{}) {
 	}
 }
 
-func (service *userService) EditPassword(token string, payload utils.UserRequest, oldPassword string) utils.Response {
+func (service *userService) EditPassword(token string, payload utils.UserRequest, oldPassword string) (utils.Response, error) {
 	emailUser, err := utils.ParseDataEmail(token)
 	if err != nil || emailUser == "" {
-		return utils.Response{
-			StatusCode: 401,
-			Messages:   "Unauthorized",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	user, err := service.userRepository.GetUserByEmail(emailUser)
 	if err != nil && user.Email != emailUser {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get user",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get user", err)
 	}
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword))
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Old password is wrong",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Invalid("wrong_old_password")
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Password hashing failed",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Password hashing failed", err)
 	}
 	user.Password = string(hashedPassword)
 	err = service.userRepository.UpdateUser(user)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to edit user",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to edit user", err)
 	}
 
 	return utils.Response{
 		StatusCode: 200,
-		Messages:   "Success",
-		Data:       user,
-	}
+		Messages:   "success",
+		Data:       formatter.FormatterUser(user),
+	}, nil
 }
 
-func (service *userService) EditPhoto(token string, payload utils.UploadedPhoto) utils.Response {
+func (service *userService) EditPhoto(token string, payload utils.UploadedPhoto) (utils.Response, error) {
 	emailUser, err := utils.ParseDataEmail(token)
 	if err != nil || emailUser == "" {
-		return utils.Response{
-			StatusCode: 401,
-			Messages:   "Unauthorized",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
 	user, err := service.userRepository.GetUserByEmail(emailUser)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get user",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get user", err)
 	}
-	filename := payload.Handler.Filename
-	if payload.Alias != "" {
-		filename = fmt.Sprintf("%s%s", payload.Alias, filepath.Ext(payload.Handler.Filename))
+	filename, photoUrls, err := uploadPhoto(service.fileStorage, payload)
+	if err != nil {
+		return utils.Response{}, photoUploadFailed(err)
 	}
 
-	// Initialize Firebase app
-	opt := option.WithCredentialsFile("config/credentials.json")
-	app, err := firebase.NewApp(context.Background(), nil, opt)
+	// Set user properties
+	user.Foto = filename
+	user.FotoUrl = photoUrls.Original
+	user.FotoMediumUrl = photoUrls.Medium
+	user.FotoThumbnailUrl = photoUrls.Thumbnail
+
+	// Update user in the database
+	err = service.userRepository.UpdateUser(user)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to initialize Firebase app",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to edit user", err)
 	}
+	return utils.Response{
+		StatusCode: 200,
+		Messages:   "success",
+		Data:       formatter.FormatterUser(user),
+	}, nil
+}
 
-	// Initialize Firebase Storage client
-	// Initialize Firebase Storage client
-	client, err := app.Storage(context.Background())
+func (service *userService) GetMembership(token string) (utils.Response, error) {
+	emailUser, err := utils.ParseDataEmail(token)
+	if err != nil || emailUser == "" {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
+	}
+	user, err := service.userRepository.GetUserByEmail(emailUser)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to initialize Firebase Storage client",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get user", err)
 	}
-
-	// Specify the path within the bucket where the file should be stored
-	storagePath := fmt.Sprintf("images/%s", filename)
-
-	// Open a new reader for the file
-	reader := payload.File
-
-	// Get the bucket handle from the client
-	bucket, err := client.Bucket("kalorize-71324.appspot.com")
+	memberships, err := service.membershipRepository.GetMembershipsByIdUser(user.IdUser)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to get bucket handle from the client",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get membership", err)
 	}
-	// Initialize the writer for the file
-	wc := bucket.Object(storagePath).NewWriter(context.Background())
-	wc.ACL = []storage.ACLRule{{Entity: storage.AllUsers, Role: storage.RoleReader}}
 
-	// Upload the file to Firebase Storage
-	if _, err := io.Copy(wc, reader); err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to upload file to Firebase Storage",
-			Data:       nil,
-		}
+	now := time.Now()
+	membershipsFormatted := formatter.MembershipsFormat{History: formatter.FormatterMemberships(memberships, now)}
+	if membership := currentMembership(memberships, now); membership != nil {
+		current := formatter.FormatterMembership(*membership, now)
+		membershipsFormatted.Current = &current
 	}
+	return utils.Response{
+		StatusCode: 200,
+		Messages:   "success",
+		Data:       membershipsFormatted,
+	}, nil
+}
 
-	// Close the writer after copying
-	if err := wc.Close(); err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to close Firebase Storage writer",
-			Data:       nil,
-		}
+func (service *userService) RenewMembership(token string, gymKode string) (utils.Response, error) {
+	emailUser, err := utils.ParseDataEmail(token)
+	if err != nil || emailUser == "" {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
-
-	// Set user properties
-	user.Foto = payload.Alias + filepath.Ext(payload.Handler.Filename)
-	user.FotoUrl = fmt.Sprintf("https://storage.googleapis.com/kalorize-71324.appspot.com/%s", storagePath)
-
-	// Update user in the database
-	err = service.userRepository.UpdateUser(user)
+	user, err := service.userRepository.GetUserByEmail(emailUser)
 	if err != nil {
-		return utils.Response{
-			StatusCode: 500,
-			Messages:   "Failed to edit user",
-			Data:       nil,
-		}
+		return utils.Response{}, utils.Internal("Failed to get user", err)
+	}
+	kodeGym, err := service.kodeGymRepository.GetKodeGymByKode(gymKode)
+	if err != nil {
+		return utils.Response{}, utils.Invalid("kode_gym_invalid")
+	}
+	if status := kodeGymStatus(service.gymRepository, kodeGym, time.Now()); status != models.KodeGymActive {
+		return utils.Response{}, kodeGymUnavailable(status)
+	}
+	memberships, err := service.membershipRepository.GetMembershipsByIdUser(user.IdUser)
+	if err != nil {
+		return utils.Response{}, utils.Internal("Failed to get membership", err)
+	}
+	var membership models.Membership
+	err = service.db.Transaction(func(tx *gorm.DB) error {
+		membership, err = startMembership(tx, kodeGym, user.IdUser, memberships)
+		return err
+	})
+	if err != nil {
+		return utils.Response{}, membershipFailed(err)
 	}
 	return utils.Response{
 		StatusCode: 200,
-		Messages:   "Success",
-		Data:       user,
+		Messages:   "success",
+		Data:       formatter.FormatterMembership(membership, time.Now()),
+	}, nil
+}
+
+// DeleteAccount deletes the user's own account after checking their password.
+// Logging in again before the grace period ends restores it; after that it is
+// erased for good.
+func (service *userService) DeleteAccount(token string, password string) (utils.Response, error) {
+	emailUser, err := utils.ParseDataEmail(token)
+	if emailUser == "" || err != nil {
+		return utils.Response{}, utils.Unauthorized("unauthorized")
 	}
+	user, err := service.userRepository.GetUserByEmail(emailUser)
+	if err != nil {
+		return utils.Response{}, utils.NotFound("user_not_found")
+	}
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return utils.Response{}, utils.Unauthorized("wrong_password")
+	}
+	erasureAt := time.Now().Add(service.deletionGrace)
+	if _, err := service.userRepository.ScheduleErasure(user.IdUser, erasureAt); err != nil {
+		return utils.Response{}, utils.Internal("Failed to delete account", err)
+	}
+	return utils.Response{
+		StatusCode:  200,
+		Messages:    "account_erasure_scheduled",
+		MessageArgs: []interface{}{erasureAt.Format("2006-01-02")},
+		Data:        formatter.ErasureFormat{ErasureScheduledAt: erasureAt},
+	}, nil
 }
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"kalorize-api/config"
+	"net/http"
+	"strings"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// gcsStorage covers both Firebase Storage and plain Google Cloud Storage,
+// Firebase buckets being regular GCS buckets.
+type gcsStorage struct {
+	bucket    *gcs.BucketHandle
+	publicURL string
+}
+
+func NewGCSStorage(ctx context.Context, cfg config.StorageConfig) (Storage, error) {
+	var opts []option.ClientOption
+	if cfg.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+	}
+	client, err := gcs.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage client: %w", err)
+	}
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		publicURL = "https://storage.googleapis.com/" + cfg.Bucket
+	}
+	return &gcsStorage{
+		bucket:    client.Bucket(cfg.Bucket),
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}, nil
+}
+
+func (s *gcsStorage) Put(ctx context.Context, key string, reader io.Reader, options PutOptions) error {
+	wc := s.bucket.Object(key).NewWriter(ctx)
+	wc.ContentType = options.ContentType
+	if options.Public {
+		wc.ACL = []gcs.ACLRule{{Entity: gcs.AllUsers, Role: gcs.RoleReader}}
+	}
+	if _, err := io.Copy(wc, reader); err != nil {
+		wc.Close()
+		return err
+	}
+	return wc.Close()
+}
+
+func (s *gcsStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	reader, err := s.bucket.Object(key).NewReader(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil, ErrNotFound
+	}
+	return reader, err
+}
+
+func (s *gcsStorage) Delete(ctx context.Context, key string) error {
+	err := s.bucket.Object(key).Delete(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *gcsStorage) Ping(ctx context.Context) error {
+	_, err := s.bucket.Attrs(ctx)
+	return err
+}
+
+func (s *gcsStorage) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *gcsStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return s.bucket.SignedURL(key, &gcs.SignedURLOptions{
+		Scheme:  gcs.SigningSchemeV4,
+		Method:  http.MethodGet,
+		Expires: time.Now().Add(expiry),
+	})
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"kalorize-api/config"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const localPublicPath = "/api/v1/storage"
+
+// LocalStorage keeps files on disk under a root directory. Objects are served
+// by routes.RoutePhotoStatic, so URLs point back at this API.
+type LocalStorage struct {
+	root       string
+	publicURL  string
+	signingKey []byte
+}
+
+func NewLocalStorage(cfg config.StorageConfig) (*LocalStorage, error) {
+	if err := os.MkdirAll(cfg.LocalPath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		publicURL = localPublicPath
+	}
+	return &LocalStorage{
+		root:       cfg.LocalPath,
+		publicURL:  strings.TrimSuffix(publicURL, "/"),
+		signingKey: []byte(cfg.SigningKey),
+	}, nil
+}
+
+// path maps a key onto the root directory; cleaning it as an absolute path
+// first strips any ".." that would escape the root.
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, reader io.Reader, options PutOptions) error {
+	target := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so readers never see a partial upload.
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) Ping(ctx context.Context) error {
+	info, err := os.Stat(s.root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", s.root)
+	}
+	return nil
+}
+
+// URL returns a signed URL that does not expire. Files on disk have no ACL,
+// so every object is served only with a valid signature, public ones
+// included.
+func (s *LocalStorage) URL(key string) string {
+	query := url.Values{}
+	query.Set("signature", s.sign(key, ""))
+	return s.objectURL(key) + "?" + query.Encode()
+}
+
+func (s *LocalStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return s.objectURL(key) + "?" + query.Encode(), nil
+}
+
+// Verify checks the signature of a URL produced by URL, where expires is
+// empty, or by SignedURL. A missing signature never verifies.
+func (s *LocalStorage) Verify(key, expires, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	if expires != "" {
+		expiresAt, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || time.Now().Unix() > expiresAt {
+			return false
+		}
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
+
+func (s *LocalStorage) objectURL(key string) string {
+	return s.publicURL + "/" + strings.TrimPrefix(key, "/")
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"kalorize-api/config"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	local, err := NewLocalStorage(config.StorageConfig{Driver: "local", LocalPath: t.TempDir(), SigningKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local
+}
+
+func TestLocalStoragePutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	local := newTestLocalStorage(t)
+
+	tests := []struct {
+		name    string
+		putKey  string
+		getKey  string
+		content string
+		wantErr error
+	}{
+		{name: "stored object", putKey: "images/a.png", getKey: "images/a.png", content: "a"},
+		{name: "overwritten object", putKey: "images/a.png", getKey: "images/a.png", content: "b"},
+		{name: "nested key", putKey: "exports/2024/b.zip", getKey: "exports/2024/b.zip", content: "zip"},
+		{name: "dot dot stays under root", putKey: "../../escape.txt", getKey: "escape.txt", content: "c"},
+		{name: "missing object", getKey: "images/missing.png", wantErr: ErrNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.putKey != "" {
+				if err := local.Put(ctx, test.putKey, strings.NewReader(test.content), PutOptions{ContentType: "text/plain"}); err != nil {
+					t.Fatalf("Put: %v", err)
+				}
+			}
+			reader, err := local.Get(ctx, test.getKey)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Get error = %v, want %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			content, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.content {
+				t.Errorf("Get = %q, want %q", content, test.content)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(local.root, "escape.txt")); err != nil {
+		t.Errorf("key with .. was not stored under the root: %v", err)
+	}
+
+	for _, key := range []string{"images/a.png", "images/missing.png"} {
+		if err := local.Delete(ctx, key); err != nil {
+			t.Errorf("Delete(%q) = %v, want nil", key, err)
+		}
+		if _, err := local.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) after Delete = %v, want ErrNotFound", key, err)
+		}
+	}
+}
+
+func TestLocalStorageVerify(t *testing.T) {
+	local := newTestLocalStorage(t)
+	other, err := NewLocalStorage(config.StorageConfig{Driver: "local", LocalPath: t.TempDir(), SigningKey: "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := func(rawURL string) url.Values {
+		t.Helper()
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed.Query()
+	}
+	signed := func(storage *LocalStorage, key string, expiry time.Duration) url.Values {
+		t.Helper()
+		signedURL, err := storage.SignedURL(context.Background(), key, expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return query(signedURL)
+	}
+
+	permanent := query(local.URL("images/a.png"))
+	valid := signed(local, "images/a.png", time.Hour)
+	expired := signed(local, "images/a.png", -time.Minute)
+	foreign := signed(other, "images/a.png", time.Hour)
+
+	tests := []struct {
+		name      string
+		key       string
+		expires   string
+		signature string
+		want      bool
+	}{
+		{"permanent URL", "images/a.png", "", permanent.Get("signature"), true},
+		{"signed URL", "images/a.png", valid.Get("expires"), valid.Get("signature"), true},
+		{"expired signed URL", "images/a.png", expired.Get("expires"), expired.Get("signature"), false},
+		{"missing signature", "images/a.png", "", "", false},
+		{"missing signature with expiry", "images/a.png", valid.Get("expires"), "", false},
+		{"tampered signature", "images/a.png", valid.Get("expires"), strings.Repeat("0", len(valid.Get("signature"))), false},
+		{"extended expiry", "images/a.png", "4102444800", valid.Get("signature"), false},
+		{"expiry dropped from signed URL", "images/a.png", "", valid.Get("signature"), false},
+		{"malformed expiry", "images/a.png", "tomorrow", valid.Get("signature"), false},
+		{"signature of another key", "images/b.png", valid.Get("expires"), valid.Get("signature"), false},
+		{"permanent signature of another key", "exports/a.zip", "", permanent.Get("signature"), false},
+		{"signed with another key", "images/a.png", foreign.Get("expires"), foreign.Get("signature"), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := local.Verify(test.key, test.expires, test.signature); got != test.want {
+				t.Errorf("Verify(%q, %q, %q) = %v, want %v", test.key, test.expires, test.signature, got, test.want)
+			}
+		})
+	}
+}
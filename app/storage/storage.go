@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"kalorize-api/config"
+	"time"
+)
+
+// ErrNotFound is returned by Get when the object does not exist.
+var ErrNotFound = errors.New("storage: object not found")
+
+// PutOptions describe an object being stored.
+type PutOptions struct {
+	ContentType string
+	// Public objects can be read by anyone through URL. The others are only
+	// served through SignedURL or by the API itself.
+	Public bool
+}
+
+// Storage stores uploaded files (profile and gym photos) under slash
+// separated keys such as "images/avatar.png".
+type Storage interface {
+	Put(ctx context.Context, key string, reader io.Reader, options PutOptions) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns the permanent URL of an object stored as public.
+	URL(key string) string
+	// SignedURL returns a URL granting temporary read access to an object.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// Ping checks that the bucket or directory can be reached.
+	Ping(ctx context.Context) error
+}
+
+// New builds the backend selected by storage.driver.
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "firebase", "gcs":
+		return NewGCSStorage(context.Background(), cfg)
+	case "s3":
+		return NewS3Storage(cfg)
+	case "local":
+		local, err := NewLocalStorage(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return local, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	vl "github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+const (
+	ProfileDev  = "dev"
+	ProfileTest = "test"
+	ProfileProd = "prod"
+
+	redactedValue = "******"
+)
+
+// ServerConfig controls the HTTP server. MetricsPort is a separate port for
+// the metrics, which must not be published. ShutdownTimeout bounds how long
+// in-flight requests and background work get to finish on SIGTERM.
+type ServerConfig struct {
+	Host            string        `mapstructure:"host"`
+	Port            int           `mapstructure:"port" validate:"min=1,max=65535"`
+	MetricsPort     int           `mapstructure:"metrics_port" validate:"min=1,max=65535,nefield=Port"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout" validate:"min=1s"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout" validate:"min=1s"`
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout" validate:"min=1s"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" validate:"min=1s"`
+	// ClientIPHeader names the header a trusted proxy in front of the server
+	// sets to the client address. Empty means the connection address.
+	ClientIPHeader string `mapstructure:"client_ip_header"`
+}
+
+// LogConfig controls the structured server log.
+type LogConfig struct {
+	Level  string `mapstructure:"level" validate:"oneof=debug info warn error"`
+	Format string `mapstructure:"format" validate:"oneof=json text"`
+}
+
+type JWTConfig struct {
+	Secret string `mapstructure:"secret" validate:"required"`
+}
+
+// AuditConfig holds the key of the fingerprints that stand in for personal
+// fields in the audit log. Changing it makes old and new fingerprints of the
+// same value differ.
+type AuditConfig struct {
+	FingerprintKey string `mapstructure:"fingerprint_key" validate:"required"`
+}
+
+type StorageConfig struct {
+	Driver          string `mapstructure:"driver" validate:"oneof=firebase gcs s3 local"`
+	Bucket          string `mapstructure:"bucket" validate:"required_unless=Driver local"`
+	PublicURL       string `mapstructure:"public_url" validate:"omitempty,url"`
+	CredentialsFile string `mapstructure:"credentials_file" validate:"required_if=Driver firebase"`
+	Endpoint        string `mapstructure:"endpoint" validate:"required_if=Driver s3"`
+	Region          string `mapstructure:"region"`
+	AccessKeyID     string `mapstructure:"access_key_id" validate:"required_if=Driver s3"`
+	SecretAccessKey string `mapstructure:"secret_access_key" validate:"required_if=Driver s3"`
+	UseSSL          bool   `mapstructure:"use_ssl"`
+	LocalPath       string `mapstructure:"local_path" validate:"required_if=Driver local"`
+	SigningKey      string `mapstructure:"signing_key" validate:"required_if=Driver local"`
+}
+
+type CORSConfig struct {
+	AllowOrigins []string `mapstructure:"allow_origins" validate:"required,min=1"`
+}
+
+type MailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port" validate:"required_with=Host"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from" validate:"required_with=Host,omitempty,email"`
+}
+
+type PaymentConfig struct {
+	Driver string `mapstructure:"driver" validate:"oneof=cash fake"`
+}
+
+// AccountConfig controls self-service account deletion.
+type AccountConfig struct {
+	DeletionGraceDays int           `mapstructure:"deletion_grace_days" validate:"min=1"`
+	ErasureInterval   time.Duration `mapstructure:"erasure_interval" validate:"min=1m"`
+}
+
+// DeletionGrace is how long a deleted account can still be restored.
+func (account AccountConfig) DeletionGrace() time.Duration {
+	return time.Duration(account.DeletionGraceDays) * 24 * time.Hour
+}
+
+type Config struct {
+	Profile  string         `mapstructure:"profile" validate:"oneof=dev test prod"`
+	Server   ServerConfig   `mapstructure:"server"`
+	Log      LogConfig      `mapstructure:"log"`
+	Database DatabaseConfig `mapstructure:"database"`
+	JWT      JWTConfig      `mapstructure:"jwt"`
+	Storage  StorageConfig  `mapstructure:"storage"`
+	CORS     CORSConfig     `mapstructure:"cors"`
+	Mail     MailConfig     `mapstructure:"mail"`
+	Payment  PaymentConfig  `mapstructure:"payment"`
+	Account  AccountConfig  `mapstructure:"account"`
+	Audit    AuditConfig    `mapstructure:"audit"`
+}
+
+// Load builds the configuration from defaults, the profile file
+// (<profile>.yaml, or KALORIZE_CONFIG_FILE) and KALORIZE_* environment
+// variables, in that order, and validates the result.
+func Load() (Config, error) {
+	var config Config
+	profile := os.Getenv("KALORIZE_PROFILE")
+	if profile == "" {
+		profile = ProfileProd
+	}
+
+	v := viper.New()
+	setDefaults(v)
+	v.Set("profile", profile)
+
+	v.SetEnvPrefix("KALORIZE")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	// Fly.io and most PaaS hosts hand the port over as plain PORT.
+	if err := v.BindEnv("server.port", "KALORIZE_SERVER_PORT", "PORT"); err != nil {
+		return config, err
+	}
+
+	configFile := os.Getenv("KALORIZE_CONFIG_FILE")
+	if configFile == "" {
+		configFile = profile + ".yaml"
+	}
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		// The profile file is optional as long as the environment provides
+		// everything, but an explicitly requested file must exist.
+		if !errors.Is(err, fs.ErrNotExist) || os.Getenv("KALORIZE_CONFIG_FILE") != "" {
+			return config, fmt.Errorf("reading config file %s: %w", configFile, err)
+		}
+	}
+
+	if err := v.Unmarshal(&config); err != nil {
+		return config, fmt.Errorf("unmarshalling config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.metrics_port", 9091)
+	v.SetDefault("server.read_timeout", "15s")
+	v.SetDefault("server.write_timeout", "60s")
+	v.SetDefault("server.idle_timeout", "120s")
+	v.SetDefault("server.shutdown_timeout", "25s")
+	v.SetDefault("server.client_ip_header", "")
+
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", "json")
+
+	v.SetDefault("database.driver", DriverMySQL)
+	v.SetDefault("database.host", "")
+	v.SetDefault("database.port", "")
+	v.SetDefault("database.dbname", "")
+	v.SetDefault("database.username", "")
+	v.SetDefault("database.password", "")
+	v.SetDefault("database.sslmode", "")
+	v.SetDefault("database.automigrate", false)
+
+	v.SetDefault("jwt.secret", "")
+
+	v.SetDefault("storage.driver", "firebase")
+	v.SetDefault("storage.bucket", "kalorize-71324.appspot.com")
+	v.SetDefault("storage.public_url", "")
+	v.SetDefault("storage.credentials_file", "config/credentials.json")
+	v.SetDefault("storage.endpoint", "")
+	v.SetDefault("storage.region", "")
+	v.SetDefault("storage.access_key_id", "")
+	v.SetDefault("storage.secret_access_key", "")
+	v.SetDefault("storage.use_ssl", true)
+	v.SetDefault("storage.local_path", "storage")
+	v.SetDefault("storage.signing_key", "")
+
+	v.SetDefault("cors.allow_origins", []string{"http://kalorize-api.fly.dev", "*"})
+
+	v.SetDefault("mail.host", "")
+	v.SetDefault("mail.port", 0)
+	v.SetDefault("mail.username", "")
+	v.SetDefault("mail.password", "")
+	v.SetDefault("mail.from", "")
+
+	v.SetDefault("payment.driver", "cash")
+
+	v.SetDefault("account.deletion_grace_days", 30)
+	v.SetDefault("account.erasure_interval", "1h")
+
+	v.SetDefault("audit.fingerprint_key", "")
+}
+
+// Validate reports every invalid setting at once so a misconfigured deploy
+// fails on startup with the full list instead of one field at a time.
+func (config Config) Validate() error {
+	err := vl.New().Struct(config)
+	var validationErrors vl.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+	var problems []string
+	for _, fieldErr := range validationErrors {
+		field := strings.TrimPrefix(fieldErr.Namespace(), "Config.")
+		if fieldErr.Param() != "" {
+			problems = append(problems, fmt.Sprintf("%s failed %s=%s", field, fieldErr.Tag(), fieldErr.Param()))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s failed %s", field, fieldErr.Tag()))
+		}
+	}
+	return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+}
+
+// Redacted returns a copy that is safe to log, with secrets masked.
+func (config Config) Redacted() Config {
+	redact(&config.Database.Password)
+	redact(&config.JWT.Secret)
+	redact(&config.Storage.SecretAccessKey)
+	redact(&config.Storage.SigningKey)
+	redact(&config.Mail.Password)
+	redact(&config.Audit.FingerprintKey)
+	return config
+}
+
+func redact(value *string) {
+	if *value != "" {
+		*value = redactedValue
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfigFile = `
+server:
+  port: 9000
+  write_timeout: 30s
+database:
+  driver: sqlite
+  dbname: test.db
+jwt:
+  secret: "from-file"
+storage:
+  driver: local
+  local_path: storage
+  signing_key: "signing"
+audit:
+  fingerprint_key: "audit"
+payment:
+  driver: fake
+`
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		check   func(t *testing.T, config Config)
+		wantErr []string
+	}{
+		{
+			name: "file over defaults",
+			file: testConfigFile,
+			check: func(t *testing.T, config Config) {
+				if config.Server.Port != 9000 || config.Server.WriteTimeout != 30*time.Second || config.Server.ReadTimeout != 15*time.Second {
+					t.Errorf("server = %+v, want port 9000, write timeout 30s and the default read timeout", config.Server)
+				}
+				if config.JWT.Secret != "from-file" || config.Database.Driver != DriverSQLite {
+					t.Errorf("jwt secret %q and driver %q, want the values of the file", config.JWT.Secret, config.Database.Driver)
+				}
+			},
+		},
+		{
+			name: "environment over file",
+			file: testConfigFile,
+			env:  map[string]string{"KALORIZE_JWT_SECRET": "from-env", "PORT": "7000", "KALORIZE_ACCOUNT_DELETION_GRACE_DAYS": "7"},
+			check: func(t *testing.T, config Config) {
+				if config.JWT.Secret != "from-env" || config.Server.Port != 7000 || config.Account.DeletionGraceDays != 7 {
+					t.Errorf("jwt secret %q, port %d, grace %d days, want the environment values", config.JWT.Secret, config.Server.Port, config.Account.DeletionGraceDays)
+				}
+			},
+		},
+		{
+			name:    "every invalid setting at once",
+			file:    "database:\n  driver: sqlite\nstorage:\n  driver: local\nlog:\n  level: loud\n",
+			wantErr: []string{"JWT.Secret failed required", "Storage.SigningKey failed required_if", "Log.Level failed oneof", "Audit.FingerprintKey failed required"},
+		},
+		{
+			name:    "metrics on the server port",
+			file:    testConfigFile,
+			env:     map[string]string{"KALORIZE_SERVER_METRICS_PORT": "9000"},
+			wantErr: []string{"Server.MetricsPort failed nefield=Port"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(test.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("KALORIZE_PROFILE", ProfileTest)
+			t.Setenv("KALORIZE_CONFIG_FILE", path)
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if test.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Load: %v", err)
+				}
+				if config.Profile != ProfileTest {
+					t.Errorf("profile = %q, want %q", config.Profile, ProfileTest)
+				}
+				test.check(t, config)
+				return
+			}
+			if err == nil {
+				t.Fatal("Load succeeded, want an error")
+			}
+			for _, want := range test.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load error = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	t.Setenv("KALORIZE_PROFILE", ProfileTest)
+	t.Setenv("KALORIZE_CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Errorf("Load error = %v, want the missing file reported", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	config := Config{
+		Database: DatabaseConfig{Password: "db", Username: "satria"},
+		JWT:      JWTConfig{Secret: "jwt"},
+		Storage:  StorageConfig{SecretAccessKey: "s3", SigningKey: "sign"},
+		Mail:     MailConfig{Password: "mail"},
+		Audit:    AuditConfig{FingerprintKey: "audit"},
+	}
+	redacted := config.Redacted()
+	for name, value := range map[string]string{
+		"database password":   redacted.Database.Password,
+		"jwt secret":          redacted.JWT.Secret,
+		"storage secret":      redacted.Storage.SecretAccessKey,
+		"storage signing key": redacted.Storage.SigningKey,
+		"mail password":       redacted.Mail.Password,
+		"audit key":           redacted.Audit.FingerprintKey,
+	} {
+		if value != redactedValue {
+			t.Errorf("%s = %q, want it masked", name, value)
+		}
+	}
+	if redacted.Database.Username != "satria" || config.JWT.Secret != "jwt" {
+		t.Error("Redacted masked a setting that is not a secret or changed the original")
+	}
+}
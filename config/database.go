@@ -2,50 +2,120 @@ package config
 
 import (
 	"fmt"
+	"kalorize-api/app/logging"
+	"log/slog"
+	"net"
+	"strings"
 
-	"github.com/spf13/viper"
+	"github.com/glebarez/sqlite"
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     string `mapstructure:"port"`
-	DBName   string `mapstructure:"dbname"`
-	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
-}
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
 
-type Config struct {
-	Database DatabaseConfig `mapstructure:"database"`
+type DatabaseConfig struct {
+	Driver      string `mapstructure:"driver" validate:"oneof=mysql postgres sqlite"`
+	Host        string `mapstructure:"host" validate:"required_unless=Driver sqlite"`
+	Port        string `mapstructure:"port" validate:"required_unless=Driver sqlite"`
+	DBName      string `mapstructure:"dbname" validate:"required_unless=Driver sqlite"`
+	Username    string `mapstructure:"username" validate:"required_unless=Driver sqlite"`
+	Password    string `mapstructure:"password"`
+	SSLMode     string `mapstructure:"sslmode"`
+	AutoMigrate bool   `mapstructure:"automigrate"`
 }
 
-func InitDB() *gorm.DB {
-	viper.SetConfigFile("prod.yaml")
-	err := viper.ReadInConfig()
+func InitDB(config DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := NewDialector(config)
 	if err != nil {
-		fmt.Println("Error reading config file :", err)
-		return nil
+		return nil, err
 	}
-	var config Config
-	err = viper.Unmarshal(&config)
+	db, err := gorm.Open(dialector, &gorm.Config{SkipDefaultTransaction: true, PrepareStmt: true, Logger: logging.Gorm(slog.Default())})
 	if err != nil {
-		fmt.Println("Error unmarshalling config :", err)
-		return nil
+		return nil, fmt.Errorf("can't connect to database: %w", err)
+	}
+	if dialector.Name() == DriverSQLite {
+		// SQLite only allows a single writer; sharing one connection also keeps
+		// ":memory:" databases from being recreated per pooled connection.
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, fmt.Errorf("can't connect to database: %w", err)
+		}
+		sqlDB.SetMaxOpenConns(1)
 	}
+	if config.AutoMigrate {
+		if err := AutoMigration(db); err != nil {
+			return nil, err
+		}
+	}
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
 
-	host := config.Database.Host
-	port := config.Database.Port
-	dbname := config.Database.DBName
-	username := config.Database.Username
-	password := config.Database.Password
+// NewDialector picks the gorm dialector matching the configured driver.
+func NewDialector(config DatabaseConfig) (gorm.Dialector, error) {
+	switch config.Driver {
+	case DriverMySQL:
+		return mysql.Open(mysqlDSN(config)), nil
+	case DriverPostgres:
+		return postgres.Open(postgresDSN(config)), nil
+	case DriverSQLite:
+		return sqlite.Open(sqliteDSN(config)), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", config.Driver)
+	}
+}
 
-	dsn := username + ":" + password + "@tcp(" + host + ":" + port + ")/" + dbname + "?parseTime=true"
-	fmt.Println(dsn)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{SkipDefaultTransaction: true, PrepareStmt: true})
+func mysqlDSN(config DatabaseConfig) string {
+	dsn := mysqlDriver.NewConfig()
+	dsn.User = config.Username
+	dsn.Passwd = config.Password
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(config.Host, config.Port)
+	dsn.DBName = config.DBName
+	dsn.ParseTime = true
+	return dsn.FormatDSN()
+}
 
-	if err != nil {
-		panic("Can't connect to database")
+func postgresDSN(config DatabaseConfig) string {
+	sslMode := config.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	params := []string{
+		"host=" + quoteDSNValue(config.Host),
+		"port=" + quoteDSNValue(config.Port),
+		"user=" + quoteDSNValue(config.Username),
+		"password=" + quoteDSNValue(config.Password),
+		"dbname=" + quoteDSNValue(config.DBName),
+		"sslmode=" + quoteDSNValue(sslMode),
 	}
-	return db
+	return strings.Join(params, " ")
+}
+
+// sqliteDSN treats dbname as the database file path, e.g. "kalorize.db" or
+// ":memory:" for a throwaway database.
+func sqliteDSN(config DatabaseConfig) string {
+	path := config.DBName
+	if path == "" {
+		path = "kalorize.db"
+	}
+	if strings.Contains(path, "?") {
+		return path
+	}
+	return path + "?_pragma=foreign_keys(1)"
+}
+
+func quoteDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
 }
@@ -1,19 +1,487 @@
 package config
 
 import (
+	"fmt"
+	"kalorize-api/app/models"
+	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func AutoMigration(db *gorm.DB) {
-	// db.AutoMigrate(&models.User{})
-	// db.AutoMigrate(&models.Token{})
-	// db.AutoMigrate(&models.UsedCode{})
-	// db.AutoMigrate(&models.Gym{})
-	// db.AutoMigrate(&models.Makanan{})
-	// db.AutoMigrate(&models.KodeGym{})
-	// db.AutoMigrate(&models.MealSet{})
-	// db.AutoMigrate(&models.Franchise{})
-	// db.AutoMigrate(&models.History{})
-	// db.AutoMigrate(&models.FranchiseMakanan{})
+// AutoMigration creates or alters every table to match the models. It may
+// change the type of existing columns, so it is only meant for development
+// and test databases; production schema changes go through Migrate.
+func AutoMigration(db *gorm.DB) error {
+	if err := db.AutoMigrate(allModels...); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// allModels are the models with a table of their own.
+var allModels = []interface{}{
+	&models.User{},
+	&models.Token{},
+	&models.UsedCode{},
+	&models.Gym{},
+	&models.Makanan{},
+	&models.MakananTranslation{},
+	&models.KodeGym{},
+	&models.MealSet{},
+	&models.Franchise{},
+	&models.History{},
+	&models.FranchiseMakanan{},
+	&models.Membership{},
+	&models.GymOwner{},
+	&models.CartItem{},
+	&models.Order{},
+	&models.OrderItem{},
+	&models.DataExport{},
+	&models.AuditLog{},
+}
+
+// migration is a reviewed schema change. Steps only add tables, columns and
+// indexes, and check for them first, so a migration that failed halfway can
+// simply run again.
+type migration struct {
+	id    string
+	steps []func(db *gorm.DB) error
+}
+
+// migrations run in order, each once. Append new ones at the end and never
+// edit one that has been deployed.
+var migrations = []migration{
+	// Databases that predate migrations already have these tables.
+	{id: "0000_baseline", steps: []func(db *gorm.DB) error{
+		createTables(&models.User{}, &models.Token{}, &models.UsedCode{}, &models.Gym{}, &models.Makanan{},
+			&models.KodeGym{}, &models.MealSet{}, &models.Franchise{}, &models.History{}, &models.FranchiseMakanan{}),
+	}},
+	{id: "0001_uuid_key_columns", steps: []func(db *gorm.DB) error{
+		// Both columns were declared too small for a UUID.
+		widenToUUID(&models.KodeGym{}, "IdKodeGym"),
+		widenToUUID(&models.History{}, "IdUser"),
+	}},
+	{id: "0002_photo_variants", steps: []func(db *gorm.DB) error{
+		addColumns(&models.User{}, "FotoMediumUrl", "FotoThumbnailUrl"),
+		addColumns(&models.Gym{}, "PhotoMediumUrl", "PhotoThumbnailUrl"),
+		addColumns(&models.Makanan{}, "FotoMedium", "FotoThumbnail"),
+		addColumns(&models.Franchise{}, "FotoMedium", "FotoThumbnail"),
+	}},
+	{id: "0003_kode_gym_lifecycle", steps: []func(db *gorm.DB) error{
+		addColumns(&models.KodeGym{}, "Mode", "MaxRedemptions", "Redemptions", "RevokedAt", "CreatedAt"),
+		createIndexes(&models.KodeGym{}, "IdGym"),
+		// Codes used to be single use; count the redemptions they already had.
+		exec(`UPDATE kode_gyms SET mode = 'single', max_redemptions = 1,
+			redemptions = (SELECT COUNT(*) FROM used_codes WHERE used_codes.id_kode = kode_gyms.kode_gym)
+			WHERE mode IS NULL OR mode = ''`),
+	}},
+	{id: "0004_memberships", steps: []func(db *gorm.DB) error{
+		createTables(&models.Membership{}),
+		addColumns(&models.KodeGym{}, "Plan"),
+		exec(`UPDATE kode_gyms SET plan = 'monthly' WHERE plan IS NULL OR plan = ''`),
+	}},
+	{id: "0005_gym_owners", steps: []func(db *gorm.DB) error{
+		createTables(&models.GymOwner{}),
+	}},
+	{id: "0006_location_indexes", steps: []func(db *gorm.DB) error{
+		createIndexes(&models.Gym{}, "idx_gyms_location"),
+		createIndexes(&models.Franchise{}, "idx_franchises_location"),
+	}},
+	{id: "0007_franchise_menus", steps: []func(db *gorm.DB) error{
+		migrateListFranchise,
+		dedupe("franchise_makanans", "id_franchise_makanan", "id_franchise", "id_makanan"),
+		createIndexes(&models.FranchiseMakanan{}, "idx_franchise_makanan", "idx_franchise_makanans_id_makanan"),
+	}},
+	{id: "0008_franchise_operators", steps: []func(db *gorm.DB) error{
+		addColumns(&models.FranchiseMakanan{}, "Harga", "Tersedia", "HabisSampai"),
+		migrateFranchisePassword,
+	}},
+	{id: "0009_orders", steps: []func(db *gorm.DB) error{
+		createTables(&models.CartItem{}, &models.Order{}, &models.OrderItem{}),
+	}},
+	{id: "0010_gym_franchise_soft_delete", steps: []func(db *gorm.DB) error{
+		addColumns(&models.Gym{}, "DeactivatedAt", "DeletedAt"),
+		createIndexes(&models.Gym{}, "DeletedAt"),
+		addColumns(&models.Franchise{}, "DeletedAt"),
+		createIndexes(&models.Franchise{}, "DeletedAt"),
+	}},
+	{id: "0011_user_deactivation", steps: []func(db *gorm.DB) error{
+		addColumns(&models.User{}, "DeactivatedAt"),
+	}},
+	{id: "0012_account_deletion", steps: []func(db *gorm.DB) error{
+		addColumns(&models.User{}, "DeletedAt", "ErasureScheduledAt", "ErasedAt"),
+		createIndexes(&models.User{}, "DeletedAt", "ErasureScheduledAt"),
+	}},
+	{id: "0013_data_exports", steps: []func(db *gorm.DB) error{
+		createTables(&models.DataExport{}),
+	}},
+	{id: "0014_audit_logs", steps: []func(db *gorm.DB) error{
+		createTables(&models.AuditLog{}),
+	}},
+	{id: "0015_makanan_translations", steps: []func(db *gorm.DB) error{
+		createTables(&models.MakananTranslation{}),
+	}},
+	{id: "0016_kode_gym_keys", steps: []func(db *gorm.DB) error{
+		// Codes are looked up by kode_gym, so a repeated one keeps only its
+		// oldest row.
+		dedupe("kode_gyms", "id_kode", "kode_gym"),
+		createIndexes(&models.KodeGym{}, "KodeGym"),
+		// A multi use code is redeemed by many users at the same gym.
+		rebuildPrimaryKey(&models.UsedCode{}, "IdUser"),
+	}},
+	{id: "0017_legacy_memberships", steps: []func(db *gorm.DB) error{
+		migrateLegacyMemberships,
+	}},
+	{id: "0018_order_idempotency", steps: []func(db *gorm.DB) error{
+		addColumns(&models.Order{}, "IdempotencyKey"),
+		createIndexes(&models.Order{}, "idx_orders_idempotency"),
+	}},
+	{id: "0019_audit_actor_email", steps: []func(db *gorm.DB) error{
+		// The actor is known by id_actor; the email would outlive erasure.
+		dropColumns(&models.AuditLog{}, "actor_email"),
+		// SQLite drops a column by rebuilding the table without its indexes.
+		createIndexes(&models.AuditLog{}, "IdActor", "idx_audit_logs_entity", "CreatedAt"),
+	}},
+	{id: "0020_audit_entity_id_length", steps: []func(db *gorm.DB) error{
+		// Links between two entities are recorded as "<id>/<id>".
+		widenColumn(&models.AuditLog{}, "EntityId", 100),
+		createIndexes(&models.AuditLog{}, "IdActor", "idx_audit_logs_entity", "CreatedAt"),
+	}},
+}
+
+// schemaMigration records a migration that has been applied.
+type schemaMigration struct {
+	Id        string    `gorm:"column:id;primary_key;size:100;"`
+	AppliedAt time.Time `gorm:"column:applied_at;"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrate applies the migrations that have not run on db yet, in order.
+func Migrate(db *gorm.DB) error {
+	if err := createTables(&schemaMigration{})(db); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	var applied []string
+	if err := db.Model(&schemaMigration{}).Pluck("id", &applied).Error; err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	done := map[string]bool{}
+	for _, id := range applied {
+		done[id] = true
+	}
+	for _, migration := range migrations {
+		if done[migration.id] {
+			continue
+		}
+		for _, step := range migration.steps {
+			if err := step(db); err != nil {
+				return fmt.Errorf("migration %s failed: %w", migration.id, err)
+			}
+		}
+		if err := db.Create(&schemaMigration{Id: migration.id, AppliedAt: time.Now()}).Error; err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", migration.id, err)
+		}
+		slog.Info("applied migration", "migration", migration.id)
+	}
+	return nil
+}
+
+func createTables(models ...interface{}) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		for _, model := range models {
+			if db.Migrator().HasTable(model) {
+				continue
+			}
+			if err := db.Migrator().CreateTable(model); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func addColumns(model interface{}, fields ...string) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		for _, field := range fields {
+			if db.Migrator().HasColumn(model, field) {
+				continue
+			}
+			if err := db.Migrator().AddColumn(model, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// dropColumns drops the given columns of the table of model, which no longer
+// declares them, when they exist.
+func dropColumns(model interface{}, columns ...string) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		for _, column := range columns {
+			if !db.Migrator().HasColumn(model, column) {
+				continue
+			}
+			if err := db.Migrator().DropColumn(model, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// createIndexes creates the indexes declared on model, given by index name
+// or by the name of the indexed field.
+func createIndexes(model interface{}, names ...string) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		for _, name := range names {
+			if db.Migrator().HasIndex(model, name) {
+				continue
+			}
+			if err := db.Migrator().CreateIndex(model, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func exec(sql string) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		return db.Exec(sql).Error
+	}
+}
+
+// widenToUUID changes the column of field to the type declared on model
+// unless it already holds strings of at least 36 characters.
+func widenToUUID(model interface{}, field string) func(db *gorm.DB) error {
+	return widenColumn(model, field, 36)
+}
+
+// widenColumn changes the column of field to the type declared on model
+// unless it already holds strings of at least length characters.
+func widenColumn(model interface{}, field string, length int64) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		column := stmt.Schema.LookUpField(field).DBName
+		columnTypes, err := db.Migrator().ColumnTypes(model)
+		if err != nil {
+			return err
+		}
+		for _, columnType := range columnTypes {
+			if columnType.Name() != column {
+				continue
+			}
+			switch strings.ToLower(columnType.DatabaseTypeName()) {
+			case "varchar", "char", "character varying", "character", "text", "uuid":
+				if current, ok := columnType.Length(); !ok || current >= length {
+					return nil
+				}
+			}
+			return db.Migrator().AlterColumn(model, field)
+		}
+		return nil
+	}
+}
+
+// dedupe deletes the rows of table that repeat the values of keyColumns,
+// keeping the one with the lowest idColumn, so a unique index can be added.
+func dedupe(table string, idColumn string, keyColumns ...string) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		if !db.Migrator().HasTable(table) {
+			return nil
+		}
+		var matches []string
+		for _, column := range keyColumns {
+			matches = append(matches, "kept."+column+" = "+table+"."+column)
+		}
+		// The extra derived table lets MySQL read the table it deletes from.
+		return db.Exec(fmt.Sprintf(`DELETE FROM %[1]s WHERE EXISTS (
+			SELECT 1 FROM (SELECT * FROM %[1]s) kept
+			WHERE %[2]s AND kept.%[3]s < %[1]s.%[3]s)`,
+			table, strings.Join(matches, " AND "), idColumn)).Error
+	}
+}
+
+// rebuildPrimaryKey recreates the table of model with the primary key it
+// declares, unless field is already part of it. SQLite cannot alter a primary
+// key, so the rows are copied into a new table on every database.
+func rebuildPrimaryKey(model interface{}, field string) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		column := stmt.Schema.LookUpField(field).DBName
+		columnTypes, err := db.Migrator().ColumnTypes(model)
+		if err != nil {
+			return err
+		}
+		for _, columnType := range columnTypes {
+			if primaryKey, ok := columnType.PrimaryKey(); ok && primaryKey && columnType.Name() == column {
+				return nil
+			}
+		}
+
+		table := stmt.Schema.Table
+		old := table + "_old"
+		columns := strings.Join(stmt.Schema.DBNames, ", ")
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Migrator().RenameTable(table, old); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateTable(model); err != nil {
+				return err
+			}
+			err := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", table, columns, columns, old)).Error
+			if err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(old)
+		})
+	}
+}
+
+// migrateListFranchise moves the comma separated franchise names that used to
+// be stored in makanans.franchise into franchise_makanans. Names that match
+// no franchise are left in the column and logged, so running it again after
+// fixing them picks them up.
+func migrateListFranchise(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("makanans", "franchise") {
+		return nil
+	}
+	var rows []struct {
+		Id        string
+		Franchise string
+	}
+	err := db.Table("makanans").Select("id, franchise").
+		Where("franchise IS NOT NULL AND franchise <> ''").Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+
+	// Migrations run against older schemas, so they query tables rather
+	// than the current models.
+	var franchises []struct {
+		IdFranchise   string
+		NamaFranchise string
+	}
+	if err := db.Table("franchises").Select("id_franchise, nama_franchise").Scan(&franchises).Error; err != nil {
+		return err
+	}
+	byName := map[string]string{}
+	for _, franchise := range franchises {
+		byName[strings.ToLower(strings.TrimSpace(franchise.NamaFranchise))] = franchise.IdFranchise
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			var unmatched []string
+			for _, name := range strings.Split(row.Franchise, ",") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+				idFranchise, ok := byName[strings.ToLower(name)]
+				if !ok {
+					unmatched = append(unmatched, name)
+					continue
+				}
+				franchiseMakanan := map[string]interface{}{
+					"id_franchise_makanan": uuid.New().String(),
+					"id_franchise":         idFranchise,
+					"id_makanan":           row.Id,
+				}
+				err := tx.Table("franchise_makanans").Clauses(clause.OnConflict{DoNothing: true}).Create(franchiseMakanan).Error
+				if err != nil {
+					return err
+				}
+			}
+			if len(unmatched) > 0 {
+				slog.Warn("seeding makanan: no franchise with that name", "makanan", row.Id, "franchise", unmatched)
+			}
+			err := tx.Table("makanans").Where("id = ?", row.Id).Update("franchise", strings.Join(unmatched, ", ")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateFranchisePassword hashes franchise passwords that were stored in
+// plaintext, so existing operators can sign in with the password they were
+// given.
+func migrateFranchisePassword(db *gorm.DB) error {
+	var franchises []struct {
+		IdFranchise string
+		Password    string
+	}
+	err := db.Table("franchises").Select("id_franchise, password").
+		Where("password IS NOT NULL AND password <> ''").Scan(&franchises).Error
+	if err != nil {
+		return err
+	}
+	for _, franchise := range franchises {
+		if _, err := bcrypt.Cost([]byte(franchise.Password)); err == nil {
+			continue
+		}
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(franchise.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		err = db.Table("franchises").Where("id_franchise = ?", franchise.IdFranchise).Update("password", string(hashedPassword)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLegacyMemberships records the membership of every member who joined
+// before memberships existed and so only has a used code. Codes were then
+// valid for a month, ending when the used code expires.
+func migrateLegacyMemberships(db *gorm.DB) error {
+	var usedCodes []struct {
+		IdGym     string
+		IdKode    string
+		IdUser    string
+		ExpiredAt time.Time
+	}
+	err := db.Table("used_codes").Select("id_gym, id_kode, id_user, expired_at").
+		Where("NOT EXISTS (SELECT 1 FROM memberships WHERE memberships.id_user = used_codes.id_user)").
+		Scan(&usedCodes).Error
+	if err != nil || len(usedCodes) == 0 {
+		return err
+	}
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, usedCode := range usedCodes {
+			membership := map[string]interface{}{
+				"id_membership": uuid.New().String(),
+				"id_user":       usedCode.IdUser,
+				"id_gym":        usedCode.IdGym,
+				"kode_gym":      usedCode.IdKode,
+				"plan":          models.MembershipMonthly,
+				"start_date":    usedCode.ExpiredAt.AddDate(0, 0, -30),
+				"end_date":      usedCode.ExpiredAt,
+				"created_at":    now,
+			}
+			if err := tx.Table("memberships").Create(membership).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
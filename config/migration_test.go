@@ -0,0 +1,110 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// TestMigrateCleanDatabase runs every migration on an empty SQLite database
+// and checks that the result has every table, column and index the models
+// declare, so a migration that breaks on a clean schema fails here.
+func TestMigrateCleanDatabase(t *testing.T) {
+	db, err := InitDB(DatabaseConfig{Driver: DriverSQLite, DBName: filepath.Join(t.TempDir(), "clean.db")})
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+
+	var applied []string
+	if err := db.Model(&schemaMigration{}).Order("id").Pluck("id", &applied).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("%d migrations applied, want %d", len(applied), len(migrations))
+	}
+	for i, migration := range migrations {
+		if applied[i] != migration.id {
+			t.Errorf("migration %d is %s, want %s", i, applied[i], migration.id)
+		}
+	}
+
+	for _, model := range allModels {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		table := statement.Schema.Table
+		if !db.Migrator().HasTable(model) {
+			t.Errorf("table %s is missing", table)
+			continue
+		}
+		for _, field := range statement.Schema.Fields {
+			if field.DBName != "" && !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("column %s.%s is missing", table, field.DBName)
+			}
+		}
+		for _, index := range statement.Schema.ParseIndexes() {
+			if !db.Migrator().HasIndex(model, index.Name) {
+				t.Errorf("index %s on %s is missing", index.Name, table)
+			}
+		}
+	}
+
+	// Applied migrations are recorded, so running again changes nothing.
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate again: %v", err)
+	}
+	var count int64
+	db.Model(&schemaMigration{}).Count(&count)
+	if count != int64(len(migrations)) {
+		t.Errorf("%d migrations recorded after running again, want %d", count, len(migrations))
+	}
+}
+
+func TestNewDialector(t *testing.T) {
+	tests := []struct {
+		driver  string
+		want    string
+		wantErr bool
+	}{
+		{DriverMySQL, "mysql", false},
+		{DriverPostgres, "postgres", false},
+		{DriverSQLite, "sqlite", false},
+		{"oracle", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.driver, func(t *testing.T) {
+			dialector, err := NewDialector(DatabaseConfig{Driver: test.driver, Host: "db", Port: "1", DBName: "kalorize", Username: "u"})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewDialector error = %v, want error %v", err, test.wantErr)
+			}
+			if err == nil && dialector.Name() != test.want {
+				t.Errorf("dialector = %s, want %s", dialector.Name(), test.want)
+			}
+		})
+	}
+}
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		want string
+	}{
+		{"mysql", mysqlDSN(DatabaseConfig{Host: "db", Port: "3306", DBName: "kalorize", Username: "satria", Password: "p@ss"}),
+			"satria:p@ss@tcp(db:3306)/kalorize?parseTime=true"},
+		{"postgres default sslmode", postgresDSN(DatabaseConfig{Host: "db", Port: "5432", DBName: "kalorize", Username: "satria", Password: `it's`}),
+			`host='db' port='5432' user='satria' password='it\'s' dbname='kalorize' sslmode='disable'`},
+		{"sqlite file", sqliteDSN(DatabaseConfig{DBName: "kalorize.db"}), "kalorize.db?_pragma=foreign_keys(1)"},
+		{"sqlite default file", sqliteDSN(DatabaseConfig{}), "kalorize.db?_pragma=foreign_keys(1)"},
+		{"sqlite with options", sqliteDSN(DatabaseConfig{DBName: "file.db?mode=ro"}), "file.db?mode=ro"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.dsn != test.want {
+				t.Errorf("DSN = %q, want %q", test.dsn, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package formatter
+
+import (
+	"kalorize-api/app/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FranchiseFormat is the public view of a franchise, without its login
+// credentials.
+type FranchiseFormat struct {
+	IdFranchise        uuid.UUID `json:"id_franchise"`
+	NamaFranchise      string    `json:"nama_franchise"`
+	LongitudeFranchise float64   `json:"longitude_franchise"`
+	LatitudeFranchise  float64   `json:"latitude_franchise"`
+	NoTeleponFranchise string    `json:"telepon_franchise"`
+	FotoFranchise      string    `json:"foto_franchise"`
+	FotoMedium         string    `json:"foto_medium_franchise"`
+	FotoThumbnail      string    `json:"foto_thumbnail_franchise"`
+	EmailFranchise     string    `json:"email_franchise"`
+	LokasiFranchise    string    `json:"lokasi_franchise"`
+}
+
+func FormatterFranchise(franchise models.Franchise) FranchiseFormat {
+	return FranchiseFormat{
+		IdFranchise:        franchise.IdFranchise,
+		NamaFranchise:      franchise.NamaFranchise,
+		LongitudeFranchise: franchise.LongitudeFranchise,
+		LatitudeFranchise:  franchise.LatitudeFranchise,
+		NoTeleponFranchise: franchise.NoTeleponFranchise,
+		FotoFranchise:      franchise.FotoFranchise,
+		FotoMedium:         franchise.FotoMedium,
+		FotoThumbnail:      franchise.FotoThumbnail,
+		EmailFranchise:     franchise.EmailFranchise,
+		LokasiFranchise:    franchise.LokasiFranchise,
+	}
+}
+
+type NearbyFranchiseFormat struct {
+	FranchiseFormat
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// FranchiseMenuFormat is a makanan as sold by one franchise. Tersedia is false
+// while the item is switched off or marked out of stock.
+type FranchiseMenuFormat struct {
+	MakananFormat
+	Harga       int
+	Tersedia    bool
+	HabisSampai *time.Time
+}
+
+func FormatterFranchiseMenu(makanans []models.Makanan, franchiseMakanans []models.FranchiseMakanan) []FranchiseMenuFormat {
+	now := time.Now()
+	byIdMakanan := make(map[string]models.FranchiseMakanan, len(franchiseMakanans))
+	for _, franchiseMakanan := range franchiseMakanans {
+		byIdMakanan[franchiseMakanan.IdMakanan] = franchiseMakanan
+	}
+	menu := make([]FranchiseMenuFormat, 0, len(makanans))
+	for _, makanan := range makanans {
+		franchiseMakanan := byIdMakanan[makanan.IdMakanan]
+		menu = append(menu, FranchiseMenuFormat{
+			MakananFormat: FormatterMakanan(makanan),
+			Harga:         franchiseMakanan.Harga,
+			Tersedia:      franchiseMakanan.Available(now),
+			HabisSampai:   franchiseMakanan.HabisSampai,
+		})
+	}
+	return menu
+}
@@ -0,0 +1,45 @@
+package formatter
+
+import (
+	"kalorize-api/app/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NearbyGymFormat struct {
+	models.Gym
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// GymMemberFormat is a member of a gym as shown to its owners, with the
+// membership at that gym.
+type GymMemberFormat struct {
+	IdUser     uuid.UUID        `json:"idUser"`
+	Fullname   string           `json:"fullname"`
+	Email      string           `json:"email"`
+	Foto       string           `json:"foto"`
+	Membership MembershipFormat `json:"membership"`
+}
+
+func FormatterGymMember(user models.User, membership models.Membership, now time.Time) GymMemberFormat {
+	return GymMemberFormat{
+		IdUser:     user.IdUser,
+		Fullname:   user.Fullname,
+		Email:      user.Email,
+		Foto:       user.FotoThumbnailUrl,
+		Membership: FormatterMembership(membership, now),
+	}
+}
+
+// GymAdherenceFormat sums up how the active members of a gym follow their
+// calorie targets. The averages are nil when too few members report to stay
+// anonymous.
+type GymAdherenceFormat struct {
+	Days                 int      `json:"days"`
+	ActiveMembers        int      `json:"activeMembers"`
+	ReportingMembers     int      `json:"reportingMembers"`
+	AverageLoggedDays    *float64 `json:"averageLoggedDays,omitempty"`
+	AverageKaloriPercent *float64 `json:"averageKaloriPercent,omitempty"`
+	OnTargetPercent      *float64 `json:"onTargetPercent,omitempty"`
+}
@@ -0,0 +1,10 @@
+package formatter
+
+// HistoryFormat is what a user ate on one day.
+type HistoryFormat struct {
+	Breakfast    MakananFormat `json:"breakfast"`
+	Lunch        MakananFormat `json:"lunch"`
+	Dinner       MakananFormat `json:"dinner"`
+	TotalKalori  int           `json:"totalKalori"`
+	TotalProtein int           `json:"totalProtein"`
+}
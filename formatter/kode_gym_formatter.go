@@ -0,0 +1,23 @@
+package formatter
+
+import (
+	"kalorize-api/app/models"
+	"time"
+)
+
+type KodeGymFormat struct {
+	models.KodeGym
+	Status string `json:"status"`
+}
+
+func FormatterKodeGym(kodeGyms []models.KodeGym) []KodeGymFormat {
+	now := time.Now()
+	kodeGymsFormatted := make([]KodeGymFormat, 0, len(kodeGyms))
+	for _, kodeGym := range kodeGyms {
+		kodeGymsFormatted = append(kodeGymsFormatted, KodeGymFormat{
+			KodeGym: kodeGym,
+			Status:  kodeGym.Status(now),
+		})
+	}
+	return kodeGymsFormatted
+}
@@ -6,17 +6,21 @@ import (
 )
 
 type MakananFormat struct {
-	ID          string
-	Nama        string
-	Jenis       string
-	Bahan       []string
-	CookingStep []string
-	Kalori      int
-	Protein     int
-	Foto        string
+	ID            string
+	Nama          string
+	Jenis         string
+	Bahan         []string
+	CookingStep   []string
+	Kalori        int
+	Protein       int
+	Foto          string
+	FotoMedium    string
+	FotoThumbnail string
 }
 
-func FormatterMakananIndo(makanan models.Makanan) MakananFormat {
+// FormatterMakanan formats a makanan as it is stored, in Indonesian. Format
+// makanan.Localized(locale) to show it in another language.
+func FormatterMakanan(makanan models.Makanan) MakananFormat {
 	var makananFormatted MakananFormat
 	makananFormatted.ID = makanan.IdMakanan
 	makananFormatted.Nama = makanan.Nama
@@ -31,5 +35,7 @@ func FormatterMakananIndo(makanan models.Makanan) MakananFormat {
 	makananFormatted.Kalori = makanan.Kalori
 	makananFormatted.Protein = makanan.Protein
 	makananFormatted.Foto = makanan.Foto
+	makananFormatted.FotoMedium = makanan.FotoMedium
+	makananFormatted.FotoThumbnail = makanan.FotoThumbnail
 	return makananFormatted
 }
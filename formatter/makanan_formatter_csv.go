@@ -6,7 +6,7 @@ import (
 )
 
 func FormatterMakananToMultiDimentionalArray(makanan []models.Makanan) [][]string {
-	var header = []string{"id", "Nama", "Jenis", "Foto", "Bahan", "Cooking Step", "Kalori", "Protein"}
+	var header = []string{"id", "Nama", "Foto", "Bahan", "Cooking Step", "Kalori", "Protein"}
 
 	var result [][]string
 	result = append(result, header)
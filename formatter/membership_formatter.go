@@ -0,0 +1,35 @@
+package formatter
+
+import (
+	"kalorize-api/app/models"
+	"time"
+)
+
+type MembershipFormat struct {
+	models.Membership
+	GraceUntil time.Time `json:"grace_until"`
+	Status     string    `json:"status"`
+}
+
+func FormatterMembership(membership models.Membership, now time.Time) MembershipFormat {
+	return MembershipFormat{
+		Membership: membership,
+		GraceUntil: membership.GraceUntil(),
+		Status:     membership.Status(now),
+	}
+}
+
+func FormatterMemberships(memberships []models.Membership, now time.Time) []MembershipFormat {
+	membershipsFormatted := make([]MembershipFormat, 0, len(memberships))
+	for _, membership := range memberships {
+		membershipsFormatted = append(membershipsFormatted, FormatterMembership(membership, now))
+	}
+	return membershipsFormatted
+}
+
+// MembershipsFormat is the membership in effect, if any, and every
+// membership of a user.
+type MembershipsFormat struct {
+	Current *MembershipFormat  `json:"current"`
+	History []MembershipFormat `json:"history"`
+}
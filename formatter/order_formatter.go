@@ -0,0 +1,46 @@
+package formatter
+
+import (
+	"kalorize-api/app/models"
+
+	"github.com/google/uuid"
+)
+
+type OrderFormat struct {
+	models.Order
+	Items []models.OrderItem `json:"items"`
+}
+
+func FormatterOrders(orders []models.Order, orderItems []models.OrderItem) []OrderFormat {
+	byIdOrder := map[uuid.UUID][]models.OrderItem{}
+	for _, orderItem := range orderItems {
+		byIdOrder[orderItem.IdOrder] = append(byIdOrder[orderItem.IdOrder], orderItem)
+	}
+	ordersFormatted := make([]OrderFormat, 0, len(orders))
+	for _, order := range orders {
+		items := byIdOrder[order.IdOrder]
+		if items == nil {
+			items = []models.OrderItem{}
+		}
+		ordersFormatted = append(ordersFormatted, OrderFormat{Order: order, Items: items})
+	}
+	return ordersFormatted
+}
+
+type CartItemFormat struct {
+	IdMakanan   string `json:"id_makanan"`
+	NamaMakanan string `json:"nama_makanan"`
+	Jumlah      int    `json:"jumlah"`
+	Harga       int    `json:"harga"`
+	Kalori      int    `json:"kalori"`
+	Protein     int    `json:"protein"`
+	Tersedia    bool   `json:"tersedia"`
+}
+
+type CartFormat struct {
+	IdFranchise  *uuid.UUID       `json:"id_franchise"`
+	Items        []CartItemFormat `json:"items"`
+	TotalHarga   int              `json:"total_harga"`
+	TotalKalori  int              `json:"total_kalori"`
+	TotalProtein int              `json:"total_protein"`
+}
@@ -0,0 +1,98 @@
+package formatter
+
+import (
+	"kalorize-api/app/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserFormat is a user as returned by the API, without the password hash.
+type UserFormat struct {
+	IdUser             uuid.UUID  `json:"id_user"`
+	Fullname           string     `json:"fullname"`
+	Email              string     `json:"email"`
+	Role               string     `json:"role"`
+	JenisKelamin       int        `json:"jenis_kelamin"`
+	Umur               int        `json:"umur"`
+	BeratBadan         int        `json:"berat_badan"`
+	TinggiBadan        int        `json:"tinggi_badan"`
+	FrekuensiGym       int        `json:"frekuensi_gym"`
+	TargetKalori       int        `json:"target_kalori"`
+	ReferalCode        string     `json:"referal_code"`
+	Foto               string     `json:"foto"`
+	FotoUrl            string     `json:"foto_url"`
+	FotoMediumUrl      string     `json:"foto_medium_url"`
+	FotoThumbnailUrl   string     `json:"foto_thumbnail_url"`
+	NoTelepon          string     `json:"no_telepon"`
+	DeactivatedAt      *time.Time `json:"deactivated_at"`
+	ErasureScheduledAt *time.Time `json:"erasure_scheduled_at"`
+}
+
+func FormatterUser(user models.User) UserFormat {
+	return UserFormat{
+		IdUser:             user.IdUser,
+		Fullname:           user.Fullname,
+		Email:              user.Email,
+		Role:               user.Role,
+		JenisKelamin:       user.JenisKelamin,
+		Umur:               user.Umur,
+		BeratBadan:         user.BeratBadan,
+		TinggiBadan:        user.TinggiBadan,
+		FrekuensiGym:       user.FrekuensiGym,
+		TargetKalori:       user.TargetKalori,
+		ReferalCode:        user.ReferalCode,
+		Foto:               user.Foto,
+		FotoUrl:            user.FotoUrl,
+		FotoMediumUrl:      user.FotoMediumUrl,
+		FotoThumbnailUrl:   user.FotoThumbnailUrl,
+		NoTelepon:          user.NoTelepon,
+		DeactivatedAt:      user.DeactivatedAt,
+		ErasureScheduledAt: user.ErasureScheduledAt,
+	}
+}
+
+// AdminUserFormat adds the membership that decides the user's status to the
+// admin user listing. Membership is nil for users who never joined a gym.
+type AdminUserFormat struct {
+	UserFormat
+	Membership *MembershipFormat `json:"membership"`
+}
+
+// TokenFormat is the pair of tokens handed out on login, registration and
+// refresh.
+type TokenFormat struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	Role         string    `json:"role"`
+	UserId       uuid.UUID `json:"userId"`
+}
+
+// LoggedInUserFormat is the profile of the logged in user. The body and gym
+// fields are only set for members, not for admins.
+type LoggedInUserFormat struct {
+	IdUser        uuid.UUID         `json:"idUser"`
+	FirstName     string            `json:"firstName"`
+	LastName      string            `json:"lastName"`
+	Email         string            `json:"email"`
+	JenisKelamin  int               `json:"jenisKelamin"`
+	FrekuensiGym  *int              `json:"frekuensiGym,omitempty"`
+	TargetKalori  *int              `json:"targetKalori,omitempty"`
+	TinggiBadan   *int              `json:"tinggiBadan,omitempty"`
+	Umur          int               `json:"umur"`
+	BeratBadan    int               `json:"beratBadan"`
+	Role          string            `json:"role"`
+	Foto          string            `json:"foto"`
+	FotoMedium    string            `json:"fotoMedium"`
+	FotoThumbnail string            `json:"fotoThumbnail"`
+	NoTelepon     string            `json:"noTelepon"`
+	KodeGym       *string           `json:"KodeGym,omitempty"`
+	Gym           *string           `json:"Gym,omitempty"`
+	Membership    *MembershipFormat `json:"membership"`
+}
+
+// ErasureFormat tells users who deleted their account when it will be
+// erased for good.
+type ErasureFormat struct {
+	ErasureScheduledAt time.Time `json:"erasure_scheduled_at"`
+}
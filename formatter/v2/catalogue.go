@@ -0,0 +1,151 @@
+package v2
+
+import (
+	"kalorize-api/app/models"
+	"kalorize-api/formatter"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Makanan struct {
+	Id          string   `json:"id"`
+	Nama        string   `json:"nama"`
+	Bahan       []string `json:"bahan"`
+	CookingStep []string `json:"cookingStep"`
+	Kalori      int      `json:"kalori"`
+	Protein     int      `json:"protein"`
+	Photo       Photo    `json:"photo"`
+}
+
+func FormatMakanan(makanan formatter.MakananFormat) Makanan {
+	return Makanan{
+		Id:          makanan.ID,
+		Nama:        makanan.Nama,
+		Bahan:       makanan.Bahan,
+		CookingStep: makanan.CookingStep,
+		Kalori:      makanan.Kalori,
+		Protein:     makanan.Protein,
+		Photo:       Photo{Url: makanan.Foto, MediumUrl: makanan.FotoMedium, ThumbnailUrl: makanan.FotoThumbnail},
+	}
+}
+
+func FormatMakanans(makanans []formatter.MakananFormat) []Makanan {
+	makanansFormatted := make([]Makanan, 0, len(makanans))
+	for _, makanan := range makanans {
+		makanansFormatted = append(makanansFormatted, FormatMakanan(makanan))
+	}
+	return makanansFormatted
+}
+
+// MenuItem is a makanan as sold by one franchise. Tersedia is false while the
+// item is switched off or marked out of stock.
+type MenuItem struct {
+	Makanan
+	Harga       int        `json:"harga"`
+	Tersedia    bool       `json:"tersedia"`
+	HabisSampai *time.Time `json:"habisSampai"`
+}
+
+func FormatMenu(menu []formatter.FranchiseMenuFormat) []MenuItem {
+	menuFormatted := make([]MenuItem, 0, len(menu))
+	for _, item := range menu {
+		menuFormatted = append(menuFormatted, MenuItem{
+			Makanan:     FormatMakanan(item.MakananFormat),
+			Harga:       item.Harga,
+			Tersedia:    item.Tersedia,
+			HabisSampai: item.HabisSampai,
+		})
+	}
+	return menuFormatted
+}
+
+type Gym struct {
+	Id         uuid.UUID `json:"id"`
+	Nama       string    `json:"nama"`
+	Alamat     string    `json:"alamat"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	LinkGoogle string    `json:"linkGoogle"`
+	Photo      Photo     `json:"photo"`
+	Active     bool      `json:"active"`
+}
+
+func FormatGym(gym models.Gym) Gym {
+	return Gym{
+		Id:         gym.IdGym,
+		Nama:       gym.NamaGym,
+		Alamat:     gym.AlamatGym,
+		Latitude:   gym.Latitude,
+		Longitude:  gym.Longitude,
+		LinkGoogle: gym.LinkGoogle,
+		Photo:      Photo{Url: gym.PhotoUrl, MediumUrl: gym.PhotoMediumUrl, ThumbnailUrl: gym.PhotoThumbnailUrl},
+		Active:     gym.Active(),
+	}
+}
+
+func FormatGyms(gyms []models.Gym) []Gym {
+	gymsFormatted := make([]Gym, 0, len(gyms))
+	for _, gym := range gyms {
+		gymsFormatted = append(gymsFormatted, FormatGym(gym))
+	}
+	return gymsFormatted
+}
+
+type NearbyGym struct {
+	Gym
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+func FormatNearbyGyms(gyms []formatter.NearbyGymFormat) []NearbyGym {
+	gymsFormatted := make([]NearbyGym, 0, len(gyms))
+	for _, gym := range gyms {
+		gymsFormatted = append(gymsFormatted, NearbyGym{Gym: FormatGym(gym.Gym), DistanceKm: gym.DistanceKm})
+	}
+	return gymsFormatted
+}
+
+type Franchise struct {
+	Id        uuid.UUID `json:"id"`
+	Nama      string    `json:"nama"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Lokasi    string    `json:"lokasi"`
+	NoTelepon string    `json:"noTelepon"`
+	Email     string    `json:"email"`
+	Photo     Photo     `json:"photo"`
+}
+
+func FormatFranchise(franchise formatter.FranchiseFormat) Franchise {
+	return Franchise{
+		Id:        franchise.IdFranchise,
+		Nama:      franchise.NamaFranchise,
+		Latitude:  franchise.LatitudeFranchise,
+		Longitude: franchise.LongitudeFranchise,
+		Lokasi:    franchise.LokasiFranchise,
+		NoTelepon: franchise.NoTeleponFranchise,
+		Email:     franchise.EmailFranchise,
+		Photo:     Photo{Url: franchise.FotoFranchise, MediumUrl: franchise.FotoMedium, ThumbnailUrl: franchise.FotoThumbnail},
+	}
+}
+
+func FormatFranchises(franchises []formatter.FranchiseFormat) []Franchise {
+	franchisesFormatted := make([]Franchise, 0, len(franchises))
+	for _, franchise := range franchises {
+		franchisesFormatted = append(franchisesFormatted, FormatFranchise(franchise))
+	}
+	return franchisesFormatted
+}
+
+type NearbyFranchise struct {
+	Franchise
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+func FormatNearbyFranchises(franchises []formatter.NearbyFranchiseFormat) []NearbyFranchise {
+	franchisesFormatted := make([]NearbyFranchise, 0, len(franchises))
+	for _, franchise := range franchises {
+		franchisesFormatted = append(franchisesFormatted, NearbyFranchise{Franchise: FormatFranchise(franchise.FranchiseFormat), DistanceKm: franchise.DistanceKm})
+	}
+	return franchisesFormatted
+}
@@ -0,0 +1,179 @@
+package v2
+
+import (
+	"kalorize-api/app/models"
+	"kalorize-api/formatter"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HistoryEntry is what a user logged for one day.
+type HistoryEntry struct {
+	Id           uuid.UUID `json:"id"`
+	Date         time.Time `json:"date"`
+	BreakfastId  string    `json:"breakfastId"`
+	LunchId      string    `json:"lunchId"`
+	DinnerId     string    `json:"dinnerId"`
+	TotalKalori  int       `json:"totalKalori"`
+	TotalProtein int       `json:"totalProtein"`
+}
+
+func FormatHistoryEntry(history models.History) HistoryEntry {
+	return HistoryEntry{
+		Id:           history.IdHistory,
+		Date:         history.TanggalDibuat,
+		BreakfastId:  history.IdBreakfast,
+		LunchId:      history.IdLunch,
+		DinnerId:     history.IdDinner,
+		TotalKalori:  history.TotalKalori,
+		TotalProtein: history.TotalProtein,
+	}
+}
+
+// History is what a user ate on one day, with the makanan of each meal.
+type History struct {
+	Breakfast    Makanan `json:"breakfast"`
+	Lunch        Makanan `json:"lunch"`
+	Dinner       Makanan `json:"dinner"`
+	TotalKalori  int     `json:"totalKalori"`
+	TotalProtein int     `json:"totalProtein"`
+}
+
+func FormatHistory(history formatter.HistoryFormat) History {
+	return History{
+		Breakfast:    FormatMakanan(history.Breakfast),
+		Lunch:        FormatMakanan(history.Lunch),
+		Dinner:       FormatMakanan(history.Dinner),
+		TotalKalori:  history.TotalKalori,
+		TotalProtein: history.TotalProtein,
+	}
+}
+
+type CartItem struct {
+	MakananId   string `json:"makananId"`
+	NamaMakanan string `json:"namaMakanan"`
+	Jumlah      int    `json:"jumlah"`
+	Harga       int    `json:"harga"`
+	Kalori      int    `json:"kalori"`
+	Protein     int    `json:"protein"`
+	Tersedia    bool   `json:"tersedia"`
+}
+
+// Cart is the makanan a user is about to order. FranchiseId is null while the
+// cart is empty.
+type Cart struct {
+	FranchiseId  *uuid.UUID `json:"franchiseId"`
+	Items        []CartItem `json:"items"`
+	TotalHarga   int        `json:"totalHarga"`
+	TotalKalori  int        `json:"totalKalori"`
+	TotalProtein int        `json:"totalProtein"`
+}
+
+func FormatCart(cart formatter.CartFormat) Cart {
+	cartFormatted := Cart{
+		FranchiseId:  cart.IdFranchise,
+		Items:        make([]CartItem, 0, len(cart.Items)),
+		TotalHarga:   cart.TotalHarga,
+		TotalKalori:  cart.TotalKalori,
+		TotalProtein: cart.TotalProtein,
+	}
+	for _, item := range cart.Items {
+		cartFormatted.Items = append(cartFormatted.Items, CartItem{
+			MakananId:   item.IdMakanan,
+			NamaMakanan: item.NamaMakanan,
+			Jumlah:      item.Jumlah,
+			Harga:       item.Harga,
+			Kalori:      item.Kalori,
+			Protein:     item.Protein,
+			Tersedia:    item.Tersedia,
+		})
+	}
+	return cartFormatted
+}
+
+type OrderItem struct {
+	Id          uuid.UUID `json:"id"`
+	MakananId   string    `json:"makananId"`
+	NamaMakanan string    `json:"namaMakanan"`
+	Jumlah      int       `json:"jumlah"`
+	Harga       int       `json:"harga"`
+	Kalori      int       `json:"kalori"`
+	Protein     int       `json:"protein"`
+}
+
+type Order struct {
+	Id               uuid.UUID   `json:"id"`
+	FranchiseId      uuid.UUID   `json:"franchiseId"`
+	Status           string      `json:"status"`
+	WaktuMakan       string      `json:"waktuMakan"`
+	Catatan          string      `json:"catatan"`
+	TotalHarga       int         `json:"totalHarga"`
+	TotalKalori      int         `json:"totalKalori"`
+	TotalProtein     int         `json:"totalProtein"`
+	PaymentProvider  string      `json:"paymentProvider"`
+	PaymentReference string      `json:"paymentReference"`
+	Items            []OrderItem `json:"items"`
+	CreatedAt        time.Time   `json:"createdAt"`
+	UpdatedAt        time.Time   `json:"updatedAt"`
+}
+
+func FormatOrder(order formatter.OrderFormat) Order {
+	orderFormatted := Order{
+		Id:               order.IdOrder,
+		FranchiseId:      order.IdFranchise,
+		Status:           order.Status,
+		WaktuMakan:       order.WaktuMakan,
+		Catatan:          order.Catatan,
+		TotalHarga:       order.TotalHarga,
+		TotalKalori:      order.TotalKalori,
+		TotalProtein:     order.TotalProtein,
+		PaymentProvider:  order.PaymentProvider,
+		PaymentReference: order.PaymentReference,
+		Items:            make([]OrderItem, 0, len(order.Items)),
+		CreatedAt:        order.CreatedAt,
+		UpdatedAt:        order.UpdatedAt,
+	}
+	for _, item := range order.Items {
+		orderFormatted.Items = append(orderFormatted.Items, OrderItem{
+			Id:          item.IdOrderItem,
+			MakananId:   item.IdMakanan,
+			NamaMakanan: item.NamaMakanan,
+			Jumlah:      item.Jumlah,
+			Harga:       item.Harga,
+			Kalori:      item.Kalori,
+			Protein:     item.Protein,
+		})
+	}
+	return orderFormatted
+}
+
+func FormatOrders(orders []formatter.OrderFormat) []Order {
+	ordersFormatted := make([]Order, 0, len(orders))
+	for _, order := range orders {
+		ordersFormatted = append(ordersFormatted, FormatOrder(order))
+	}
+	return ordersFormatted
+}
+
+// DataExport is an export of everything stored about the user. It can be
+// downloaded once Status is ready, until ExpiresAt.
+type DataExport struct {
+	Id          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"`
+	Size        int64      `json:"size"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+func FormatDataExport(dataExport models.DataExport) DataExport {
+	return DataExport{
+		Id:          dataExport.IdExport,
+		Status:      dataExport.Status,
+		Size:        dataExport.Size,
+		CreatedAt:   dataExport.CreatedAt,
+		CompletedAt: dataExport.CompletedAt,
+		ExpiresAt:   dataExport.ExpiresAt,
+	}
+}
@@ -0,0 +1,182 @@
+// Package v2 formats the response data of version 2 of the API. Every field
+// is camelCase, an entity's own id is "id" and references to other entities
+// are "<entity>Id". Photos are grouped in a Photo with the URL of each size.
+package v2
+
+import (
+	"kalorize-api/formatter"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Pagination describes the page of a listing.
+type Pagination struct {
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	Total      int `json:"total"`
+	TotalPages int `json:"totalPages"`
+}
+
+func FormatPagination(page int, limit int, total int) Pagination {
+	return Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + limit - 1) / limit,
+	}
+}
+
+type Photo struct {
+	Url          string `json:"url"`
+	MediumUrl    string `json:"mediumUrl"`
+	ThumbnailUrl string `json:"thumbnailUrl"`
+}
+
+type Tokens struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	Role         string    `json:"role"`
+	UserId       uuid.UUID `json:"userId"`
+}
+
+func FormatTokens(tokens formatter.TokenFormat) Tokens {
+	return Tokens{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		Role:         tokens.Role,
+		UserId:       tokens.UserId,
+	}
+}
+
+type User struct {
+	Id           uuid.UUID `json:"id"`
+	Fullname     string    `json:"fullname"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"`
+	JenisKelamin int       `json:"jenisKelamin"`
+	Umur         int       `json:"umur"`
+	BeratBadan   int       `json:"beratBadan"`
+	TinggiBadan  int       `json:"tinggiBadan"`
+	FrekuensiGym int       `json:"frekuensiGym"`
+	TargetKalori int       `json:"targetKalori"`
+	ReferalCode  string    `json:"referalCode"`
+	NoTelepon    string    `json:"noTelepon"`
+	Photo        Photo     `json:"photo"`
+}
+
+func FormatUser(user formatter.UserFormat) User {
+	return User{
+		Id:           user.IdUser,
+		Fullname:     user.Fullname,
+		Email:        user.Email,
+		Role:         user.Role,
+		JenisKelamin: user.JenisKelamin,
+		Umur:         user.Umur,
+		BeratBadan:   user.BeratBadan,
+		TinggiBadan:  user.TinggiBadan,
+		FrekuensiGym: user.FrekuensiGym,
+		TargetKalori: user.TargetKalori,
+		ReferalCode:  user.ReferalCode,
+		NoTelepon:    user.NoTelepon,
+		Photo:        Photo{Url: user.FotoUrl, MediumUrl: user.FotoMediumUrl, ThumbnailUrl: user.FotoThumbnailUrl},
+	}
+}
+
+// Me is the profile of the logged in user. The body and gym fields are null
+// for admins; GymName and Membership are null for members without a
+// membership in effect.
+type Me struct {
+	Id           uuid.UUID   `json:"id"`
+	FirstName    string      `json:"firstName"`
+	LastName     string      `json:"lastName"`
+	Email        string      `json:"email"`
+	Role         string      `json:"role"`
+	JenisKelamin int         `json:"jenisKelamin"`
+	Umur         int         `json:"umur"`
+	BeratBadan   int         `json:"beratBadan"`
+	TinggiBadan  *int        `json:"tinggiBadan"`
+	FrekuensiGym *int        `json:"frekuensiGym"`
+	TargetKalori *int        `json:"targetKalori"`
+	NoTelepon    string      `json:"noTelepon"`
+	Photo        Photo       `json:"photo"`
+	GymName      *string     `json:"gymName"`
+	Membership   *Membership `json:"membership"`
+}
+
+func FormatMe(user formatter.LoggedInUserFormat) Me {
+	me := Me{
+		Id:           user.IdUser,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Email:        user.Email,
+		Role:         user.Role,
+		JenisKelamin: user.JenisKelamin,
+		Umur:         user.Umur,
+		BeratBadan:   user.BeratBadan,
+		TinggiBadan:  user.TinggiBadan,
+		FrekuensiGym: user.FrekuensiGym,
+		TargetKalori: user.TargetKalori,
+		NoTelepon:    user.NoTelepon,
+		Photo:        Photo{Url: user.Foto, MediumUrl: user.FotoMedium, ThumbnailUrl: user.FotoThumbnail},
+	}
+	if user.Membership != nil {
+		membership := FormatMembership(*user.Membership)
+		me.Membership = &membership
+		me.GymName = user.Gym
+	}
+	return me
+}
+
+type Erasure struct {
+	ErasureScheduledAt time.Time `json:"erasureScheduledAt"`
+}
+
+func FormatErasure(erasure formatter.ErasureFormat) Erasure {
+	return Erasure{ErasureScheduledAt: erasure.ErasureScheduledAt}
+}
+
+type Membership struct {
+	Id         uuid.UUID `json:"id"`
+	GymId      uuid.UUID `json:"gymId"`
+	KodeGym    string    `json:"kodeGym"`
+	Plan       string    `json:"plan"`
+	Status     string    `json:"status"`
+	StartDate  time.Time `json:"startDate"`
+	EndDate    time.Time `json:"endDate"`
+	GraceUntil time.Time `json:"graceUntil"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func FormatMembership(membership formatter.MembershipFormat) Membership {
+	return Membership{
+		Id:         membership.IdMembership,
+		GymId:      membership.IdGym,
+		KodeGym:    membership.KodeGym,
+		Plan:       membership.Plan,
+		Status:     membership.Status,
+		StartDate:  membership.StartDate,
+		EndDate:    membership.EndDate,
+		GraceUntil: membership.GraceUntil,
+		CreatedAt:  membership.CreatedAt,
+	}
+}
+
+// Memberships is the membership in effect, if any, and every membership of
+// the user.
+type Memberships struct {
+	Current *Membership  `json:"current"`
+	History []Membership `json:"history"`
+}
+
+func FormatMemberships(memberships formatter.MembershipsFormat) Memberships {
+	membershipsFormatted := Memberships{History: make([]Membership, 0, len(memberships.History))}
+	for _, membership := range memberships.History {
+		membershipsFormatted.History = append(membershipsFormatted.History, FormatMembership(membership))
+	}
+	if memberships.Current != nil {
+		current := FormatMembership(*memberships.Current)
+		membershipsFormatted.Current = &current
+	}
+	return membershipsFormatted
+}
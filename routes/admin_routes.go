@@ -2,22 +2,47 @@ package routes
 
 import (
 	"kalorize-api/app/controllers"
+	"kalorize-api/app/storage"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-func RoutesAdmin(apiv1 *echo.Group, db *gorm.DB) {
-	adminController := controllers.NewAdminController(db)
+func RoutesAdmin(apiv1 *echo.Group, db *gorm.DB, fileStorage storage.Storage) {
+	adminController := controllers.NewAdminController(db, fileStorage)
 
 	apiv1.POST("/admin/create-makanan", adminController.RegisterMakanan)
+	apiv1.PUT("/admin/update-makanan-photo/:id", adminController.UpdateMakananPhoto)
+	apiv1.PUT("/admin/update-makanan-translation/:id/:locale", adminController.TranslateMakanan)
+	apiv1.POST("/admin/attach-franchise-makanan", adminController.AttachFranchiseMakanan)
+	apiv1.DELETE("/admin/detach-franchise-makanan/:franchiseId/:makananId", adminController.DetachFranchiseMakanan)
 	apiv1.POST("/admin/create-gym", adminController.RegisterGym)
+	apiv1.GET("/admin/get-all-gym", adminController.GetAllGym)
+	apiv1.GET("/admin/get-gym/:id", adminController.GetGymById)
+	apiv1.PUT("/admin/update-gym/:id", adminController.UpdateGym)
+	apiv1.PUT("/admin/update-gym-photo/:id", adminController.UpdateGymPhoto)
+	apiv1.PUT("/admin/deactivate-gym/:id", adminController.DeactivateGym)
+	apiv1.PUT("/admin/activate-gym/:id", adminController.ActivateGym)
+	apiv1.DELETE("/admin/delete-gym/:id", adminController.DeleteGym)
+	apiv1.GET("/admin/get-gym-members/:id", adminController.GetGymMembers)
 	apiv1.POST("/admin/create-franchise", adminController.RegisterFranchise)
+	apiv1.GET("/admin/get-all-franchise", adminController.GetAllFranchise)
+	apiv1.GET("/admin/get-franchise/:id", adminController.GetFranchiseById)
+	apiv1.PUT("/admin/update-franchise/:id", adminController.UpdateFranchise)
+	apiv1.PUT("/admin/update-franchise-photo/:id", adminController.UpdateFranchisePhoto)
+	apiv1.DELETE("/admin/delete-franchise/:id", adminController.DeleteFranchise)
 	apiv1.POST("/admin/create-gymcode", adminController.GenerateGymToken)
+	apiv1.GET("/admin/get-all-gymcode", adminController.GetAllKodeGym)
+	apiv1.PUT("/admin/revoke-gymcode/:id", adminController.RevokeKodeGym)
+	apiv1.POST("/admin/assign-gym-owner", adminController.AssignGymOwner)
 	apiv1.POST("/admin/create-user", adminController.RegisterUser)
 	apiv1.GET("/admin/get-all-user", adminController.GetAllUser)
 	apiv1.GET("/admin/get-user/:id", adminController.GetUserById)
 	apiv1.PUT("/admin/update-user/:id", adminController.UpdateUser)
 	apiv1.DELETE("/admin/delete-user/:id", adminController.DeleteUser)
+	apiv1.PUT("/admin/restore-user/:id", adminController.RestoreUser)
+	apiv1.DELETE("/admin/erase-user/:id", adminController.EraseUser)
+	apiv1.POST("/admin/bulk-users", adminController.BulkUpdateUsers)
+	apiv1.GET("/admin/audit-logs", adminController.GetAuditLogs)
 }
 	
\ No newline at end of file
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"kalorize-api/app/controllers"
+	"kalorize-api/app/storage"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func RouteDataExport(apiv1 *echo.Group, db *gorm.DB, fileStorage storage.Storage) {
+	dataExportController := controllers.NewDataExportController(db, fileStorage)
+
+	apiv1.POST("/user/export", dataExportController.RequestExport)
+	apiv1.GET("/user/export/:id", dataExportController.GetExport)
+	apiv1.GET("/user/export/:id/download", dataExportController.DownloadExport)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"kalorize-api/app/controllers"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func RouteFranchise(apiv1 *echo.Group, db *gorm.DB) {
+	franchiseController := controllers.NewFranchiseController(db)
+
+	apiv1.GET("/franchise", franchiseController.GetAllFranchise)
+	apiv1.POST("/franchise/login", franchiseController.Login)
+	apiv1.GET("/franchise/me/menu", franchiseController.GetOwnMenu)
+	apiv1.PUT("/franchise/me/menu/:makananId", franchiseController.UpdateMenuItem)
+	apiv1.PUT("/franchise/me/menu/:makananId/out-of-stock", franchiseController.SetOutOfStock)
+	apiv1.DELETE("/franchise/me/menu/:makananId/out-of-stock", franchiseController.ClearOutOfStock)
+	apiv1.GET("/franchise/nearby", franchiseController.GetNearbyFranchise)
+	apiv1.GET("/franchise/:id", franchiseController.GetFranchiseById)
+	apiv1.GET("/franchise/:id/menu", franchiseController.GetFranchiseMenu)
+}
@@ -1,16 +1,20 @@
 package routes
 
 import (
+	"kalorize-api/app/controllers"
+
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
 func GymOwnerRoute(apiv1 *echo.Group, db *gorm.DB) {
-	// gymOwnerController := controllers.NewGymOwnerController(db)
+	gymOwnerController := controllers.NewGymOwnerController(db)
 
-	// apiv1.POST("/gym-owner", gymOwnerController.CreateGymOwner)
-	// apiv1.GET("/gym-owner", gymOwnerController.GetGymOwner)
-	// apiv1.GET("/gym-owner/:id", gymOwnerController.GetGymOwnerById)
-	// apiv1.PUT("/gym-owner/:id", gymOwnerController.UpdateGymOwner)
-	// apiv1.DELETE("/gym-owner/:id", gymOwnerController.DeleteGymOwner)
+	apiv1.GET("/gym-owner/gyms", gymOwnerController.GetGyms)
+	apiv1.PUT("/gym-owner/gyms/:id", gymOwnerController.UpdateGym)
+	apiv1.GET("/gym-owner/gyms/:id/codes", gymOwnerController.GetKodeGym)
+	apiv1.POST("/gym-owner/gyms/:id/codes", gymOwnerController.GenerateKodeGym)
+	apiv1.PUT("/gym-owner/gyms/:id/codes/:kodeId/revoke", gymOwnerController.RevokeKodeGym)
+	apiv1.GET("/gym-owner/gyms/:id/members", gymOwnerController.GetMembers)
+	apiv1.GET("/gym-owner/gyms/:id/adherence", gymOwnerController.GetAdherence)
 }
@@ -11,6 +11,7 @@ func RouteGym(apiv1 *echo.Group, db *gorm.DB) {
 	gymController := controllers.NewGymController(db)
 
 	apiv1.GET("/gym", gymController.GetAllGym)
+	apiv1.GET("/gym/nearby", gymController.GetNearbyGym)
 	apiv1.POST("/gym/:id", gymController.CheckGymCode)
 	apiv1.POST("/gym/used/:id", gymController.IsUsed)
 }
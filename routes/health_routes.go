@@ -0,0 +1,74 @@
+package routes
+
+import (
+	"context"
+	"kalorize-api/app/controllers"
+	"kalorize-api/app/metrics"
+	"kalorize-api/app/storage"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// RouteHealth adds the probes used by the platform. They live outside /api so
+// they are neither versioned nor documented.
+func RouteHealth(e *echo.Echo, db *gorm.DB, fileStorage storage.Storage) {
+	sqlDB, dbErr := db.DB()
+	if dbErr == nil {
+		metrics.RegisterDB(sqlDB)
+	}
+
+	// Liveness: the process is up and serving.
+	e.GET("/healthz", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	// Readiness: the database and the file storage can be reached.
+	e.GET("/readyz", func(c echo.Context) error {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), readinessTimeout)
+		defer cancel()
+
+		checks := map[string]func(context.Context) error{
+			"database": func(ctx context.Context) error {
+				if dbErr != nil {
+					return dbErr
+				}
+				return sqlDB.PingContext(ctx)
+			},
+			"storage": fileStorage.Ping,
+		}
+		status := http.StatusOK
+		results := map[string]string{}
+		for name, check := range checks {
+			results[name] = "ok"
+			if err := check(ctx); err != nil {
+				slog.WarnContext(ctx, "readiness check failed", "check", name, "error", err)
+				results[name] = "unavailable"
+				status = http.StatusServiceUnavailable
+			}
+		}
+		overall := "ok"
+		if status != http.StatusOK {
+			overall = "unavailable"
+		}
+		return c.JSON(status, map[string]interface{}{"status": overall, "checks": results})
+	})
+}
+
+// InitMetrics returns the server of the Prometheus metrics. It listens on its
+// own port, which only the scraper can reach, because the metrics name every
+// route and are served without authentication.
+func InitMetrics() *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+	return e
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMetricsServedOnlyByMetricsServer(t *testing.T) {
+	public := newTestServer(t)
+	metricsServer := InitMetrics()
+
+	tests := []struct {
+		name   string
+		server *echo.Echo
+		path   string
+		want   int
+	}{
+		{"metrics on the public server", public, "/metrics", http.StatusNotFound},
+		{"probe on the public server", public, "/healthz", http.StatusOK},
+		{"metrics on the metrics server", metricsServer, "/metrics", http.StatusOK},
+		{"probe on the metrics server", metricsServer, "/healthz", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			test.server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if recorder.Code != test.want {
+				t.Errorf("GET %s = %d, want %d", test.path, recorder.Code, test.want)
+			}
+		})
+	}
+}
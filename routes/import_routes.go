@@ -1,7 +1,7 @@
 package routes
 
 import (
-	"log"
+	"kalorize-api/utils"
 	"os"
 
 	"github.com/labstack/echo/v4"
@@ -13,15 +13,15 @@ func RouteImportDatabase(apiv1 *echo.Group, db *gorm.DB) {
 		sqlFilePath := "kalorize.sql"
 		sqlContent, err := os.ReadFile(sqlFilePath)
 		if err != nil {
-			log.Fatalf("Error reading SQL file: %v", err)
+			return utils.Internal("Error reading SQL file", err)
 		}
 		execute, err := db.DB()
 		if err != nil {
-			log.Fatalf("Error executing SQL file: %v", err)
+			return utils.Internal("Error executing SQL file", err)
 		}
 		success, err := execute.Exec(string(sqlContent))
 		if err != nil {
-			log.Fatalf("Error executing SQL file: %v", err)
+			return utils.Internal("Error executing SQL file", err)
 		}
 		return c.JSON(200, success)
 
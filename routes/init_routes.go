@@ -1,16 +1,73 @@
 package routes
 
 import (
+	"kalorize-api/app/controllers"
+	"kalorize-api/app/payment"
+	"kalorize-api/app/storage"
+	"kalorize-api/config"
+	"net"
+	"net/http"
+	"strings"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"gorm.io/gorm"
 )
 
-func Init() (*echo.Group, *echo.Echo) {
+func Init(serverConfig config.ServerConfig, corsConfig config.CORSConfig) (*echo.Group, *echo.Echo) {
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.IPExtractor = clientIP(serverConfig.ClientIPHeader)
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+	e.Use(controllers.RequestID, controllers.AccessLog, controllers.Metrics)
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"http://kalorize-api.fly.dev", "*"},
+		AllowOrigins:  corsConfig.AllowOrigins,
+		ExposeHeaders: []string{"Deprecation", "Link"},
 	}))
 	apiv1 := e.Group("/api/v1")
-	e.Debug = true
 	return apiv1, e
 }
+
+// clientIP returns the IP extractor behind c.RealIP(). It reads header,
+// which the proxy in front of the server overwrites on every request, or the
+// connection address when no header is configured. X-Forwarded-For and
+// X-Real-IP are never trusted, as any client can send them.
+func clientIP(header string) echo.IPExtractor {
+	direct := echo.ExtractIPDirect()
+	if header == "" {
+		return direct
+	}
+	return func(request *http.Request) string {
+		if ip := net.ParseIP(strings.TrimSpace(request.Header.Get(header))); ip != nil {
+			return ip.String()
+		}
+		return direct(request)
+	}
+}
+
+// Register adds every route of the API to apiv1 and to a version 2 group,
+// the docs last so they describe all the others, and the health probes.
+func Register(apiv1 *echo.Group, e *echo.Echo, db *gorm.DB, fileStorage storage.Storage, paymentProvider payment.Provider, accountConfig config.AccountConfig) {
+	apiv1.Use(deprecateV1, controllers.RejectDeactivated(db))
+	RouteAuth(apiv1, db)
+	RouteMakanan(apiv1, db)
+	RouteQuestionnaire(apiv1, db)
+	RoutesAdmin(apiv1, db, fileStorage)
+	RouteUser(apiv1, db, fileStorage, accountConfig)
+	RoutePhotoStatic(apiv1, fileStorage)
+	RouteImportDatabase(apiv1, db)
+	RouteGym(apiv1, db)
+	GymOwnerRoute(apiv1, db)
+	RouteFranchise(apiv1, db)
+	RouteOrder(apiv1, db, paymentProvider)
+	RouteDataExport(apiv1, db, fileStorage)
+
+	apiv2 := e.Group(apiPrefixV2, controllers.V2, controllers.RejectDeactivated(db))
+	RouteV2(apiv2, db, fileStorage, paymentProvider, accountConfig)
+
+	RouteOpenAPI(apiv1, e, apiV1, Operations())
+	RouteOpenAPI(apiv2, e, apiV2, OperationsV2())
+
+	RouteHealth(e, db, fileStorage)
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"kalorize-api/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		headers map[string]string
+		want    string
+	}{
+		{name: "connection address", want: "192.0.2.1"},
+		{name: "forwarded headers ignored", headers: map[string]string{"X-Forwarded-For": "203.0.113.9", "X-Real-IP": "203.0.113.9", "Fly-Client-IP": "203.0.113.9"}, want: "192.0.2.1"},
+		{name: "proxy header", header: "Fly-Client-IP", headers: map[string]string{"Fly-Client-IP": "198.51.100.7", "X-Forwarded-For": "203.0.113.9"}, want: "198.51.100.7"},
+		{name: "malformed proxy header", header: "Fly-Client-IP", headers: map[string]string{"Fly-Client-IP": "not an ip"}, want: "192.0.2.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, e := Init(config.ServerConfig{ClientIPHeader: test.header}, config.CORSConfig{AllowOrigins: []string{"*"}})
+			e.GET("/ip", func(c echo.Context) error {
+				return c.String(http.StatusOK, c.RealIP())
+			})
+			request := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			request.RemoteAddr = "192.0.2.1:4321"
+			for key, value := range test.headers {
+				request.Header.Set(key, value)
+			}
+			recorder := httptest.NewRecorder()
+			e.ServeHTTP(recorder, request)
+			if got := recorder.Body.String(); got != test.want {
+				t.Errorf("RealIP = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
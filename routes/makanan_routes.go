@@ -13,4 +13,5 @@ func RouteMakanan(apiv1 *echo.Group, db *gorm.DB) {
 	apiv1.GET("/makanan", makananController.GetAllMakanan)
 	apiv1.GET("/makanan/csv", makananController.GetMakananCSV)
 	apiv1.GET("/makanan/:makananId", makananController.GetMakananById)
+	apiv1.GET("/makanan/:makananId/franchises", makananController.GetFranchiseByMakanan)
 }
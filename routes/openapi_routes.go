@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"kalorize-api/app/controllers"
+	"kalorize-api/app/openapi"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	apiPrefix   = "/api/v1"
+	apiPrefixV2 = "/api/v2"
+)
+
+var (
+	apiV1 = openapi.API{
+		Info: openapi.Info{
+			Title:       "Kalorize API",
+			Description: "Meal planning for gym members. Messages follow Accept-Language (id or en). Operations marked deprecated have a successor in version 2, linked from their Link header.",
+			Version:     "1",
+		},
+		Prefix:   apiPrefix,
+		Envelope: controllers.EnvelopeV1,
+	}
+	apiV2 = openapi.API{
+		Info: openapi.Info{
+			Title:       "Kalorize API",
+			Description: "Meal planning for gym members. Status codes report the outcome; bodies hold data, a page of data or an error. Messages follow Accept-Language (id or en).",
+			Version:     "2",
+		},
+		Prefix:   apiPrefixV2,
+		Envelope: controllers.EnvelopeV2,
+	}
+)
+
+// routeOperations documents the routes defined in this package rather than
+// by a controller.
+var routeOperations = map[string]openapi.Operation{
+	"GET /storage/*": {Tag: "Storage", Summary: "Get a stored photo", Public: true, Query: []openapi.Parameter{openapi.Query("expires", "integer", "Expiry of a signed URL, as a Unix time; absent from permanent photo URLs"), openapi.RequiredQuery("signature", "string", "Signature of the URL")}, Produces: "image/*"},
+	"POST /import":   {Tag: "Storage", Summary: "Load kalorize.sql into the database"},
+}
+
+// docsOperations documents the routes added by RouteOpenAPI to each version.
+var docsOperations = map[string]openapi.Operation{
+	"GET /openapi.json": {Tag: "Docs", Summary: "Get this document", Public: true, Produces: echo.MIMEApplicationJSON},
+	"GET /docs":         {Tag: "Docs", Summary: "Browse this document", Public: true, Produces: echo.MIMETextHTML},
+}
+
+// Operations documents every version 1 route registered by Register. Those
+// with a successor are deprecated.
+func Operations() map[string]openapi.Operation {
+	operations := merge(controllers.Operations, routeOperations, docsOperations)
+	for key := range v1Successors {
+		if operation, ok := operations[key]; ok {
+			operation.Deprecated = true
+			operations[key] = operation
+		}
+	}
+	return operations
+}
+
+// OperationsV2 documents every version 2 route registered by Register.
+func OperationsV2() map[string]openapi.Operation {
+	return merge(controllers.OperationsV2, docsOperations)
+}
+
+func merge(operationSets ...map[string]openapi.Operation) map[string]openapi.Operation {
+	operations := map[string]openapi.Operation{}
+	for _, operationSet := range operationSets {
+		for key, operation := range operationSet {
+			operations[key] = operation
+		}
+	}
+	return operations
+}
+
+// RouteOpenAPI serves the OpenAPI document of the routes of api and a Swagger
+// UI page to browse it. The document is built on the first request, once all
+// routes are registered.
+func RouteOpenAPI(group *echo.Group, e *echo.Echo, api openapi.API, operations map[string]openapi.Operation) {
+	var (
+		once     sync.Once
+		document openapi.Document
+	)
+	group.GET("/openapi.json", func(c echo.Context) error {
+		once.Do(func() {
+			document, _, _ = openapi.Build(api, e, operations)
+		})
+		return c.JSON(http.StatusOK, document)
+	})
+	group.GET("/docs", func(c echo.Context) error {
+		c.Response().Header().Set("Content-Security-Policy", docsPolicy)
+		return c.HTML(http.StatusOK, docsPage)
+	})
+}
+
+// swaggerUI is the exact Swagger UI release the docs page loads. Published
+// npm versions cannot change, so pinning one keeps the page from picking up
+// a new release unreviewed; bump it deliberately.
+const swaggerUI = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+const docsScript = `window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });`
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Kalorize API</title>
+  <link rel="stylesheet" href="` + swaggerUI + `/swagger-ui.css" crossorigin="anonymous" referrerpolicy="no-referrer">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="` + swaggerUI + `/swagger-ui-bundle.js" crossorigin="anonymous" referrerpolicy="no-referrer"></script>
+  <script>` + docsScript + `</script>
+</body>
+</html>
+`
+
+// docsPolicy only lets the docs page run the pinned Swagger UI bundle and
+// its own inline script, and only fetch the document from this server.
+var docsPolicy = func() string {
+	sum := sha256.Sum256([]byte(docsScript))
+	return "default-src 'self'; " +
+		"script-src " + swaggerUI + "/swagger-ui-bundle.js 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'; " +
+		"style-src 'self' 'unsafe-inline' " + swaggerUI + "/swagger-ui.css; " +
+		"img-src 'self' data:; object-src 'none'; base-uri 'none'; frame-ancestors 'none'"
+}()
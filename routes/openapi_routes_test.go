@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"kalorize-api/app/openapi"
+	"kalorize-api/app/payment"
+	"kalorize-api/app/storage"
+	"kalorize-api/config"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	e := newTestServer(t)
+
+	for _, version := range []struct {
+		api        openapi.API
+		operations map[string]openapi.Operation
+	}{
+		{apiV1, Operations()},
+		{apiV2, OperationsV2()},
+	} {
+		document, undocumented, unused := openapi.Build(version.api, e, version.operations)
+		for _, route := range undocumented {
+			t.Errorf("route %s%s has no operation", version.api.Prefix, route)
+		}
+		for _, operation := range unused {
+			t.Errorf("operation %s of %s matches no route", operation, version.api.Prefix)
+		}
+		if _, err := json.Marshal(document); err != nil {
+			t.Errorf("marshal document of %s: %v", version.api.Prefix, err)
+		}
+	}
+}
+
+func TestV1SuccessorsExist(t *testing.T) {
+	e := newTestServer(t)
+
+	routes := map[string]bool{}
+	paths := map[string]bool{}
+	for _, route := range e.Routes() {
+		routes[route.Method+" "+route.Path] = true
+		paths[route.Path] = true
+	}
+	for key, successor := range v1Successors {
+		method, path, _ := strings.Cut(key, " ")
+		if !routes[method+" "+apiPrefix+path] {
+			t.Errorf("successor of %s: no such version 1 route", key)
+		}
+		if !paths[apiPrefixV2+successor] {
+			t.Errorf("successor of %s: no version 2 route at %s", key, successor)
+		}
+	}
+}
+
+func newTestServer(t *testing.T) *echo.Echo {
+	e, _ := newTestServerWithDB(t)
+	return e
+}
+
+// newTestServerWithDB serves every route from an empty in-memory database,
+// which it returns for the test to fill.
+func newTestServerWithDB(t *testing.T) (*echo.Echo, *gorm.DB) {
+	db, err := config.InitDB(config.DatabaseConfig{Driver: config.DriverSQLite, DBName: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileStorage, err := storage.New(config.StorageConfig{Driver: "local", LocalPath: t.TempDir(), SigningKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paymentProvider, err := payment.New(config.PaymentConfig{Driver: "fake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiv1, e := Init(config.ServerConfig{}, config.CORSConfig{AllowOrigins: []string{"*"}})
+	Register(apiv1, e, db, fileStorage, paymentProvider, config.AccountConfig{DeletionGraceDays: 30, ErasureInterval: time.Hour})
+	return e, db
+}
+
+func TestDocsPage(t *testing.T) {
+	e := newTestServer(t)
+	for _, path := range []string{apiPrefix + "/docs", apiPrefixV2 + "/docs"} {
+		recorder := httptest.NewRecorder()
+		e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d", path, recorder.Code)
+		}
+		page := recorder.Body.String()
+		policy := recorder.Header().Get("Content-Security-Policy")
+
+		// Every asset comes from the pinned release, loaded without credentials.
+		for _, match := range regexp.MustCompile(`(?:src|href)="([^"]+)"([^>]*)>`).FindAllStringSubmatch(page, -1) {
+			if !strings.HasPrefix(match[1], swaggerUI+"/") || !strings.Contains(match[2], `crossorigin="anonymous"`) {
+				t.Errorf("%s loads %s%s, want the pinned release with crossorigin", path, match[1], match[2])
+			}
+		}
+		if !regexp.MustCompile(`@\d+\.\d+\.\d+$`).MatchString(swaggerUI) {
+			t.Errorf("Swagger UI %s is not pinned to an exact version", swaggerUI)
+		}
+
+		// The policy allows the inline script and nothing else inline.
+		inline := regexp.MustCompile(`<script>(.*)</script>`).FindStringSubmatch(page)
+		if inline == nil {
+			t.Fatalf("%s has no inline script", path)
+		}
+		sum := sha256.Sum256([]byte(inline[1]))
+		if !strings.Contains(policy, "'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"'") || strings.Contains(policy, "script-src 'unsafe-inline'") {
+			t.Errorf("Content-Security-Policy %q does not allow exactly the inline script", policy)
+		}
+		if !strings.Contains(policy, "script-src "+swaggerUI+"/swagger-ui-bundle.js ") {
+			t.Errorf("Content-Security-Policy %q does not limit scripts to the pinned bundle", policy)
+		}
+	}
+}
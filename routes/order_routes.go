@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"kalorize-api/app/controllers"
+	"kalorize-api/app/payment"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func RouteOrder(apiv1 *echo.Group, db *gorm.DB, paymentProvider payment.Provider) {
+	orderController := controllers.NewOrderController(db, paymentProvider)
+
+	apiv1.GET("/order/cart", orderController.GetCart)
+	apiv1.PUT("/order/cart", orderController.SetCartItem)
+	apiv1.DELETE("/order/cart", orderController.ClearCart)
+	apiv1.POST("/order", orderController.PlaceOrder)
+	apiv1.GET("/order", orderController.GetOrders)
+	apiv1.GET("/order/:id", orderController.GetOrderById)
+	apiv1.PUT("/order/:id/cancel", orderController.CancelOrder)
+
+	apiv1.GET("/franchise/me/orders", orderController.GetFranchiseOrders)
+	apiv1.PUT("/franchise/me/orders/:id/status", orderController.UpdateFranchiseOrderStatus)
+}
@@ -1,15 +1,50 @@
 package routes
 
-import "github.com/labstack/echo/v4"
+import (
+	"bufio"
+	"errors"
+	"kalorize-api/app/storage"
+	"kalorize-api/utils"
+	"net/http"
+	"strings"
 
-func RoutePhotoStatic(apiv1 *echo.Group) {
-	apiv1.Static("/storage", "storage")
-	apiv1.GET("/storage/:filename", func(c echo.Context) error {
-		format := c.Param("filename")[len(c.Param("filename"))-3:]
-		if format == "jpg" || format == "png" {
-			return c.File("storage/" + c.Param("filename"))
-		} else {
-			return c.File("storage/default.png")
+	"github.com/labstack/echo/v4"
+)
+
+const defaultPhoto = "default.png"
+
+func RoutePhotoStatic(apiv1 *echo.Group, fileStorage storage.Storage) {
+	apiv1.GET("/storage/*", func(c echo.Context) error {
+		key := c.Param("*")
+		local, ok := fileStorage.(*storage.LocalStorage)
+		if !ok {
+			// Remote backends serve their objects themselves.
+			return c.Redirect(http.StatusFound, fileStorage.URL(key))
+		}
+		if !local.Verify(key, c.QueryParam("expires"), c.QueryParam("signature")) {
+			return utils.Forbidden("invalid_signature")
+		}
+
+		reader, err := local.Get(c.Request().Context(), key)
+		if errors.Is(err, storage.ErrNotFound) && key != defaultPhoto {
+			reader, err = local.Get(c.Request().Context(), defaultPhoto)
+		}
+		if errors.Is(err, storage.ErrNotFound) {
+			return echo.ErrNotFound
+		}
+		if err != nil {
+			return utils.Internal("Failed to read stored file", err)
+		}
+		defer reader.Close()
+
+		// Decide the type from the file content rather than its name, and
+		// only ever serve images.
+		buffered := bufio.NewReaderSize(reader, 512)
+		head, _ := buffered.Peek(512)
+		contentType := http.DetectContentType(head)
+		if !strings.HasPrefix(contentType, "image/") {
+			return echo.ErrNotFound
 		}
+		return c.Stream(http.StatusOK, contentType, buffered)
 	})
 }
@@ -0,0 +1,93 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"kalorize-api/app/storage"
+	"kalorize-api/config"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRoutePhotoStatic(t *testing.T) {
+	local, err := storage.NewLocalStorage(config.StorageConfig{Driver: "local", LocalPath: t.TempDir(), SigningKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	for key, content := range map[string][]byte{
+		"images/a.png": photo.Bytes(),
+		defaultPhoto:   photo.Bytes(),
+		"notes/a.txt":  []byte("not an image"),
+		"images/file":  []byte("a file where a directory is expected"),
+	} {
+		if err := local.Put(context.Background(), key, bytes.NewReader(content), storage.PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	apiv1, e := Init(config.ServerConfig{}, config.CORSConfig{AllowOrigins: []string{"*"}})
+	RoutePhotoStatic(apiv1, local)
+
+	signed := func(key string) string {
+		signedURL, err := url.Parse(local.URL(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "/api/v1/storage/" + key + "?" + signedURL.RawQuery
+	}
+	tests := []struct {
+		name     string
+		path     string
+		want     int
+		wantCode string
+	}{
+		{name: "signed image", path: signed("images/a.png"), want: http.StatusOK},
+		{name: "missing image falls back to the default", path: signed("images/missing.png"), want: http.StatusOK},
+		{name: "missing signature", path: "/api/v1/storage/images/a.png", want: http.StatusForbidden, wantCode: "invalid_signature"},
+		{name: "signature of another key", path: strings.Replace(signed("images/a.png"), "images/a.png", "images/b.png", 1), want: http.StatusForbidden, wantCode: "invalid_signature"},
+		{name: "not an image", path: signed("notes/a.txt"), want: http.StatusNotFound},
+		{name: "unreadable file", path: signed("images/file/a.png"), want: http.StatusInternalServerError, wantCode: "internal_error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			request.Header.Set("Accept-Language", "en")
+			e.ServeHTTP(recorder, request)
+			if recorder.Code != test.want {
+				t.Fatalf("GET %s = %d, want %d", test.path, recorder.Code, test.want)
+			}
+			if test.want == http.StatusOK {
+				if contentType := recorder.Header().Get("Content-Type"); contentType != "image/png" {
+					t.Errorf("Content-Type = %q, want image/png", contentType)
+				}
+				return
+			}
+			if test.wantCode == "" {
+				return
+			}
+			var body struct {
+				Code     string `json:"code"`
+				Messages string `json:"messages"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q: %v", recorder.Body, err)
+			}
+			if body.Code != test.wantCode || body.Messages == test.wantCode {
+				t.Errorf("body = %+v, want code %s with a catalogue message", body, test.wantCode)
+			}
+			if strings.Contains(recorder.Body.String(), "not a directory") {
+				t.Errorf("body %q leaks the internal error", recorder.Body)
+			}
+		})
+	}
+}
@@ -2,17 +2,22 @@ package routes
 
 import (
 	"kalorize-api/app/controllers"
+	"kalorize-api/app/storage"
+	"kalorize-api/config"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-func RouteUser(apiv1 *echo.Group, db *gorm.DB) {
-	userController := controllers.NewUserController(db)
+func RouteUser(apiv1 *echo.Group, db *gorm.DB, fileStorage storage.Storage, accountConfig config.AccountConfig) {
+	userController := controllers.NewUserController(db, fileStorage, accountConfig.DeletionGrace())
 
 	apiv1.PUT("/edit-user", userController.EditUser)
 	apiv1.PUT("/edit-password", userController.EditPassword)
 	apiv1.PUT("/edit-photo", userController.EditPhoto)
 	apiv1.POST("/user/history", userController.CreateHistory)
 	apiv1.GET("/user/history", userController.GetHistoryBaseDateTime)
+	apiv1.GET("/user/membership", userController.GetMembership)
+	apiv1.POST("/user/membership/renew", userController.RenewMembership)
+	apiv1.DELETE("/user", userController.DeleteAccount)
 }
@@ -0,0 +1,119 @@
+package routes
+
+import (
+	"kalorize-api/app/controllers"
+	"kalorize-api/app/payment"
+	"kalorize-api/app/storage"
+	"kalorize-api/config"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// RouteV2 adds the member facing routes of version 2. Admin, gym owner and
+// franchise operator routes are only served by version 1.
+func RouteV2(apiv2 *echo.Group, db *gorm.DB, fileStorage storage.Storage, paymentProvider payment.Provider, accountConfig config.AccountConfig) {
+	authController := controllers.NewAuthController(db)
+	userController := controllers.NewUserController(db, fileStorage, accountConfig.DeletionGrace())
+	dataExportController := controllers.NewDataExportController(db, fileStorage)
+	makananController := controllers.NewMakananController(db)
+	gymController := controllers.NewGymController(db)
+	franchiseController := controllers.NewFranchiseController(db)
+	orderController := controllers.NewOrderController(db, paymentProvider)
+
+	apiv2.POST("/auth/login", authController.LoginV2)
+	apiv2.POST("/auth/register", authController.RegisterV2)
+	apiv2.POST("/auth/refresh", authController.RefreshV2)
+	apiv2.POST("/auth/logout", authController.LogoutV2)
+
+	apiv2.GET("/me", authController.GetUserV2)
+	apiv2.PUT("/me", userController.EditUserV2)
+	apiv2.DELETE("/me", userController.DeleteAccountV2)
+	apiv2.PUT("/me/password", userController.EditPasswordV2)
+	apiv2.PUT("/me/photo", userController.EditPhotoV2)
+	apiv2.POST("/me/history", userController.CreateHistoryV2)
+	apiv2.GET("/me/history", userController.GetHistoryV2)
+	apiv2.GET("/me/membership", userController.GetMembershipV2)
+	apiv2.POST("/me/membership/renewals", userController.RenewMembershipV2)
+	apiv2.POST("/me/exports", dataExportController.RequestExportV2)
+	apiv2.GET("/me/exports/:id", dataExportController.GetExportV2)
+	apiv2.GET("/me/exports/:id/download", dataExportController.DownloadExport)
+
+	apiv2.GET("/makanan", makananController.GetAllMakananV2)
+	apiv2.GET("/makanan/:makananId", makananController.GetMakananByIdV2)
+	apiv2.GET("/makanan/:makananId/franchises", makananController.GetFranchiseByMakananV2)
+
+	apiv2.GET("/gyms", gymController.GetAllGymV2)
+	apiv2.GET("/gyms/nearby", gymController.GetNearbyGymV2)
+	apiv2.POST("/gym-codes/check", gymController.CheckKodeGymV2)
+
+	apiv2.GET("/franchises", franchiseController.GetAllFranchiseV2)
+	apiv2.GET("/franchises/nearby", franchiseController.GetNearbyFranchiseV2)
+	apiv2.GET("/franchises/:id", franchiseController.GetFranchiseByIdV2)
+	apiv2.GET("/franchises/:id/menu", franchiseController.GetFranchiseMenuV2)
+
+	apiv2.GET("/cart", orderController.GetCartV2)
+	apiv2.PUT("/cart/items", orderController.SetCartItemV2)
+	apiv2.DELETE("/cart", orderController.ClearCartV2)
+	apiv2.POST("/orders", orderController.PlaceOrderV2)
+	apiv2.GET("/orders", orderController.GetOrdersV2)
+	apiv2.GET("/orders/:id", orderController.GetOrderByIdV2)
+	apiv2.POST("/orders/:id/cancel", orderController.CancelOrderV2)
+}
+
+// v1Successors maps the version 1 routes that have a version 2 equivalent to
+// the path of that equivalent, relative to /api/v1 and /api/v2.
+var v1Successors = map[string]string{
+	"POST /login":                        "/auth/login",
+	"POST /register":                     "/auth/register",
+	"POST /refresh":                      "/auth/refresh",
+	"POST /logout":                       "/auth/logout",
+	"GET /user":                          "/me",
+	"PUT /edit-user":                     "/me",
+	"DELETE /user":                       "/me",
+	"PUT /edit-password":                 "/me/password",
+	"PUT /edit-photo":                    "/me/photo",
+	"POST /user/history":                 "/me/history",
+	"GET /user/history":                  "/me/history",
+	"GET /user/membership":               "/me/membership",
+	"POST /user/membership/renew":        "/me/membership/renewals",
+	"POST /user/export":                  "/me/exports",
+	"GET /user/export/:id":               "/me/exports/:id",
+	"GET /user/export/:id/download":      "/me/exports/:id/download",
+	"GET /makanan":                       "/makanan",
+	"GET /makanan/:makananId":            "/makanan/:makananId",
+	"GET /makanan/:makananId/franchises": "/makanan/:makananId/franchises",
+	"GET /gym":                           "/gyms",
+	"GET /gym/nearby":                    "/gyms/nearby",
+	"POST /gym/:id":                      "/gym-codes/check",
+	"GET /franchise":                     "/franchises",
+	"GET /franchise/nearby":              "/franchises/nearby",
+	"GET /franchise/:id":                 "/franchises/:id",
+	"GET /franchise/:id/menu":            "/franchises/:id/menu",
+	"GET /order/cart":                    "/cart",
+	"PUT /order/cart":                    "/cart/items",
+	"DELETE /order/cart":                 "/cart",
+	"POST /order":                        "/orders",
+	"GET /order":                         "/orders",
+	"GET /order/:id":                     "/orders/:id",
+	"PUT /order/:id/cancel":              "/orders/:id/cancel",
+}
+
+// deprecateV1 marks the responses of version 1 routes that have a successor
+// as deprecated and links to the successor, with the path parameters of the
+// request filled in.
+func deprecateV1(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		successor, ok := v1Successors[c.Request().Method+" "+strings.TrimPrefix(c.Path(), apiPrefix)]
+		if ok {
+			for _, name := range c.ParamNames() {
+				successor = strings.Replace(successor, ":"+name, url.PathEscape(c.Param(name)), 1)
+			}
+			c.Response().Header().Set("Deprecation", "true")
+			c.Response().Header().Set("Link", "<"+apiPrefixV2+successor+`>; rel="successor-version"`)
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,128 @@
+package routes
+
+import (
+	"encoding/json"
+	"kalorize-api/app/models"
+	"kalorize-api/utils"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestV2(t *testing.T) {
+	e, db := newTestServerWithDB(t)
+	for i := 1; i <= 5; i++ {
+		makanan := models.Makanan{IdMakanan: strconv.Itoa(i), Nama: "Makanan " + strconv.Itoa(i), Bahan: "nasi", CookingStep: "masak"}
+		if err := db.Create(&makanan).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := models.User{IdUser: uuid.New(), Fullname: "Budi", Email: "budi@t.io", Role: "user"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	token, err := utils.GenerateJWTAccessToken(user.IdUser, user.Fullname, user.Email, utils.JWTSecret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(t *testing.T, path string) (*httptest.ResponseRecorder, map[string]json.RawMessage) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Accept-Language", "en")
+		request.Header.Set("Authorization", "Bearer "+token)
+		e.ServeHTTP(recorder, request)
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s: body %q: %v", path, recorder.Body, err)
+		}
+		return recorder, body
+	}
+
+	t.Run("page of a listing", func(t *testing.T) {
+		recorder, body := get(t, apiPrefixV2+"/makanan?page=2&limit=2")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", recorder.Code)
+		}
+		if keys := sortedKeys(body); !reflect.DeepEqual(keys, []string{"data", "pagination"}) {
+			t.Errorf("body keys = %v, want only data and pagination", keys)
+		}
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(body["data"], &items); err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("%d items, want 2", len(items))
+		}
+		if keys := sortedKeys(items[0]); !reflect.DeepEqual(keys, []string{"bahan", "cookingStep", "id", "kalori", "nama", "photo", "protein"}) {
+			t.Errorf("item keys = %v, want the camelCase DTO", keys)
+		}
+		var pagination map[string]int
+		if err := json.Unmarshal(body["pagination"], &pagination); err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]int{"page": 2, "limit": 2, "total": 5, "totalPages": 3}; !reflect.DeepEqual(pagination, want) {
+			t.Errorf("pagination = %v, want %v", pagination, want)
+		}
+		if recorder.Header().Get("Deprecation") != "" {
+			t.Error("a version 2 route is marked as deprecated")
+		}
+	})
+
+	t.Run("page past the end", func(t *testing.T) {
+		_, body := get(t, apiPrefixV2+"/makanan?page=4&limit=2")
+		if string(body["data"]) != "[]" {
+			t.Errorf("data = %s, want an empty list", body["data"])
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		recorder, body := get(t, apiPrefixV2+"/makanan?limit=0")
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", recorder.Code)
+		}
+		var errorBody struct {
+			Code   string `json:"code"`
+			Fields []struct {
+				Field string `json:"field"`
+			} `json:"fields"`
+		}
+		if err := json.Unmarshal(body["error"], &errorBody); err != nil || len(body) != 1 {
+			t.Fatalf("body = %v, want only an error", body)
+		}
+		if errorBody.Code != "invalid_query" || len(errorBody.Fields) != 1 || errorBody.Fields[0].Field != "limit" {
+			t.Errorf("error = %+v, want invalid_query on limit", errorBody)
+		}
+	})
+
+	t.Run("deprecated version 1 route", func(t *testing.T) {
+		recorder, _ := get(t, apiPrefix+"/makanan/3")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", recorder.Code)
+		}
+		if recorder.Header().Get("Deprecation") != "true" || recorder.Header().Get("Link") != `</api/v2/makanan/3>; rel="successor-version"` {
+			t.Errorf("Deprecation %q, Link %q, want the route deprecated in favour of /api/v2/makanan/3", recorder.Header().Get("Deprecation"), recorder.Header().Get("Link"))
+		}
+	})
+
+	t.Run("version 1 route without a successor", func(t *testing.T) {
+		recorder, _ := get(t, apiPrefix+"/openapi.json")
+		if recorder.Header().Get("Deprecation") != "" || recorder.Header().Get("Link") != "" {
+			t.Errorf("Deprecation %q, Link %q, want neither", recorder.Header().Get("Deprecation"), recorder.Header().Get("Link"))
+		}
+	})
+}
+
+func sortedKeys(object map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
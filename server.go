@@ -1,29 +1,129 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"kalorize-api/app/logging"
+	"kalorize-api/app/payment"
+	"kalorize-api/app/services"
+	"kalorize-api/app/storage"
 	"kalorize-api/config"
 	"kalorize-api/routes"
+	"kalorize-api/utils"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 func main() {
-	db := config.InitDB()
-	config.AutoMigration(db)
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("loading configuration failed", err)
+	}
+	logger, err := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fatal("creating logger failed", err)
+	}
+	slog.SetDefault(logger)
+	slog.Info("configuration loaded", "profile", cfg.Profile, "config", cfg.Redacted())
+	utils.SetJWTSecret(cfg.JWT.Secret)
+	services.SetAuditFingerprintKey(cfg.Audit.FingerprintKey)
+
+	db, err := config.InitDB(cfg.Database)
+	if err != nil {
+		fatal("connecting to the database failed", err)
+	}
+	fileStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		fatal("creating file storage failed", err)
+	}
+	paymentProvider, err := payment.New(cfg.Payment)
+	if err != nil {
+		fatal("creating payment provider failed", err)
+	}
 
 	// Route
-	route, e := routes.Init()
+	route, e := routes.Init(cfg.Server, cfg.CORS)
+
+	routes.Register(route, e, db, fileStorage, paymentProvider, cfg.Account)
 
-	routes.RouteAuth(route, db)
-	routes.RouteMakanan(route, db)
-	routes.RouteQuestionnaire(route, db)
-	routes.RoutesAdmin(route, db)
-	routes.RouteUser(route, db)
-	routes.RoutePhotoStatic(route)
-	routes.RouteImportDatabase(route, db)
-	routes.RouteGym(route, db)
+	// SIGTERM is what Fly sends before stopping a machine; a second signal
+	// kills the process without waiting.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	erasureDone := make(chan struct{})
+	go func() {
+		defer close(erasureDone)
+		services.NewErasureJob(db, fileStorage, cfg.Account.ErasureInterval).Run(ctx)
+	}()
 
 	// Start server
-	port := 8080
-	address := fmt.Sprintf("0.0.0.0:%d", port)
-	e.Logger.Fatal(e.Start(address))
+	address := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+	e.Server.ReadTimeout = cfg.Server.ReadTimeout
+	e.Server.WriteTimeout = cfg.Server.WriteTimeout
+	e.Server.IdleTimeout = cfg.Server.IdleTimeout
+	metricsServer := routes.InitMetrics()
+	metricsAddress := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.MetricsPort)
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- e.Start(address)
+	}()
+	go func() {
+		serverErr <- metricsServer.Start(metricsAddress)
+	}()
+	slog.Info("server started", "address", address, "metrics_address", metricsAddress)
+
+	select {
+	case err := <-serverErr:
+		fatal("server stopped", err)
+	case <-ctx.Done():
+	}
+	stop()
+	slog.Info("shutting down", "timeout", cfg.Server.ShutdownTimeout)
+	shutdown(e, metricsServer, db, erasureDone, cfg.Server.ShutdownTimeout)
+	slog.Info("server stopped")
+}
+
+// shutdown stops accepting connections and waits, up to timeout, for the
+// in-flight requests, the erasure job and the background work of services
+// before closing the database. The metrics server stops last so the final
+// requests are still counted while it can be scraped.
+func shutdown(e *echo.Echo, metricsServer *echo.Echo, db *gorm.DB, erasureDone <-chan struct{}, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := e.Shutdown(ctx); err != nil {
+		slog.Error("draining requests failed", "error", err)
+	}
+	select {
+	case <-erasureDone:
+	case <-ctx.Done():
+		slog.Error("erasure job did not stop in time")
+	}
+	if err := services.WaitBackground(ctx); err != nil {
+		slog.Error("background work did not finish in time", "error", err)
+	}
+
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		slog.Error("stopping the metrics server failed", "error", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.Close()
+	}
+	if err != nil {
+		slog.Error("closing the database failed", "error", err)
+	}
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestShutdown(t *testing.T) {
+	tests := []struct {
+		name       string
+		handleFor  time.Duration
+		erasureFor time.Duration
+		wantStatus int
+	}{
+		{"drains in-flight requests", 100 * time.Millisecond, 0, http.StatusOK},
+		{"waits for the erasure job", 0, 100 * time.Millisecond, http.StatusOK},
+		{"gives up at the timeout", 5 * time.Second, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			started := make(chan struct{})
+			e := startTestServer(t, func(c echo.Context) error {
+				close(started)
+				time.Sleep(test.handleFor)
+				return c.NoContent(http.StatusOK)
+			})
+			metricsServer := startTestServer(t, func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+			status := make(chan int, 1)
+			go func() {
+				response, err := http.Get("http://" + e.ListenerAddr().String())
+				if err != nil {
+					status <- 0
+					return
+				}
+				response.Body.Close()
+				status <- response.StatusCode
+			}()
+			<-started
+
+			erasureDone := make(chan struct{})
+			erasureStopped := false
+			go func() {
+				time.Sleep(test.erasureFor)
+				erasureStopped = true
+				close(erasureDone)
+			}()
+
+			const timeout = 500 * time.Millisecond
+			begin := time.Now()
+			shutdown(e, metricsServer, db, erasureDone, timeout)
+			if elapsed := time.Since(begin); elapsed > timeout+250*time.Millisecond {
+				t.Errorf("shutdown took %s, want at most the %s timeout", elapsed, timeout)
+			}
+
+			if test.erasureFor > 0 && !erasureStopped {
+				t.Error("shutdown returned before the erasure job stopped")
+			}
+			if test.wantStatus != 0 {
+				if got := <-status; got != test.wantStatus {
+					t.Errorf("in-flight request = %d, want %d", got, test.wantStatus)
+				}
+			}
+			if _, err := http.Get("http://" + e.ListenerAddr().String()); err == nil {
+				t.Error("the server still accepts requests")
+			}
+			if _, err := http.Get("http://" + metricsServer.ListenerAddr().String()); err == nil {
+				t.Error("the metrics server still accepts requests")
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sqlDB.Ping(); err == nil {
+				t.Error("the database is still open")
+			}
+		})
+	}
+}
+
+// startTestServer serves handler on a free local port until the test ends.
+func startTestServer(t *testing.T, handler echo.HandlerFunc) *echo.Echo {
+	t.Helper()
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.GET("/", handler)
+	go e.Start("127.0.0.1:0")
+	t.Cleanup(func() { e.Close() })
+	for i := 0; e.ListenerAddr() == nil; i++ {
+		if i == 100 {
+			t.Fatal("the server did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return e
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestMeta describes the request behind a change, for the audit log.
+type RequestMeta struct {
+	IpAddress string
+	UserAgent string
+	Method    string
+	Path      string
+}
+
+// AuditLogFilter narrows the audit log listing. Empty fields match every
+// entry; To is exclusive.
+type AuditLogFilter struct {
+	IdActor    *uuid.UUID
+	EntityType string
+	EntityId   string
+	Action     string
+	From       time.Time
+	To         time.Time
+	Page       int
+	Limit      int
+}
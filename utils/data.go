@@ -2,7 +2,7 @@ package utils
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
@@ -13,11 +13,11 @@ func ParseDataEmail(bearerToken string) (email string, err error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte("kalorize"), nil
+		return []byte(jwtSecret), nil
 	})
 
 	if err != nil {
-		log.Printf("Error parsing JWT token: %v", err)
+		slog.Debug("parsing JWT failed", "error", err)
 		return "", err
 	}
 
@@ -25,7 +25,7 @@ func ParseDataEmail(bearerToken string) (email string, err error) {
 		emailClaim := claims["Email"]
 		if emailClaim == nil {
 			err := fmt.Errorf("email claim is missing in JWT token")
-			log.Printf("Error: %v", err)
+			slog.Debug("invalid JWT claims", "error", err)
 			return "", err
 		}
 		email = emailClaim.(string)
@@ -38,11 +38,11 @@ func ParseDataFullname(bearerToken string) (email string, err error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte("kalorize"), nil
+		return []byte(jwtSecret), nil
 	})
 
 	if err != nil {
-		log.Printf("Error parsing JWT token: %v", err)
+		slog.Debug("parsing JWT failed", "error", err)
 		return "", err
 	}
 
@@ -50,7 +50,7 @@ func ParseDataFullname(bearerToken string) (email string, err error) {
 		emailClaim := claims["Fullname"]
 		if emailClaim == nil {
 			err := fmt.Errorf("email claim is missing in JWT token")
-			log.Printf("Error: %v", err)
+			slog.Debug("invalid JWT claims", "error", err)
 			return "", err
 		}
 		email = emailClaim.(string)
@@ -63,31 +63,50 @@ func ParseDataId(bearerToken string) (id uuid.UUID, err error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte("kalorize"), nil
+		return []byte(jwtSecret), nil
 	})
 	if err != nil {
-		log.Printf("Error parsing JWT token: %v", err)
+		slog.Debug("parsing JWT failed", "error", err)
 		return id, err
 	}
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		log.Println("Claims are valid")
 		idClaim, ok := claims["IdUser"].(string)
 		if !ok {
 			err := fmt.Errorf("id claim is missing or not a string in JWT token")
-			log.Printf("Error: %v", err)
+			slog.Debug("invalid JWT claims", "error", err)
 			return id, err
 		}
-		log.Printf("IdUser claim: %s", idClaim)
 		id, err = uuid.Parse(idClaim)
 		if err != nil {
-			log.Printf("Error parsing UUID: %v", err)
+			slog.Debug("invalid JWT claims", "error", err)
 			return id, err
 		}
-		log.Printf("Parsed UUID: %s", id.String())
 	} else {
 		err := fmt.Errorf("claims are not of type jwt.MapClaims or token is invalid")
-		log.Printf("Error: %v", err)
+		slog.Debug("invalid JWT claims", "error", err)
 		return id, err
 	}
 	return id, nil
 }
+
+func ParseDataIdFranchise(bearerToken string) (id uuid.UUID, err error) {
+	token, err := jwt.Parse(bearerToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil {
+		slog.Debug("parsing JWT failed", "error", err)
+		return id, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return id, fmt.Errorf("claims are not of type jwt.MapClaims or token is invalid")
+	}
+	idClaim, ok := claims["IdFranchise"].(string)
+	if !ok {
+		return id, fmt.Errorf("id franchise claim is missing or not a string in JWT token")
+	}
+	return uuid.Parse(idClaim)
+}
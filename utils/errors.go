@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorKind classifies a domain error. The HTTP error handler turns the kind
+// into the status code of the response.
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPaymentRequired
+)
+
+// Error is returned by services for anything the client should be told
+// about. Code is a stable, machine-readable identifier such as
+// "gym_not_found" and doubles as the key of the message shown to people,
+// which is looked up in the catalogue in the language of the request.
+type Error struct {
+	Kind   ErrorKind
+	Code   string
+	Args   []interface{}
+	Fields []FieldError
+	// Err is the underlying cause. It is logged but never sent to clients.
+	Err error
+}
+
+// FieldError describes why a single request field was rejected. Message is
+// filled in from Rule and Params when the response is written.
+type FieldError struct {
+	Field   string        `json:"field"`
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Rule    string        `json:"-"`
+	Params  []interface{} `json:"-"`
+}
+
+// Field reports that field broke rule, for example Field("limit",
+// "between", 1, 100).
+func Field(field string, rule string, params ...interface{}) FieldError {
+	return FieldError{Field: field, Code: rule, Rule: rule, Params: params}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) StatusCode() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindPaymentRequired:
+		return http.StatusPaymentRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func Invalid(code string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Fields: fields}
+}
+
+func Unauthorized(code string, args ...interface{}) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Args: args}
+}
+
+func Forbidden(code string, args ...interface{}) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Args: args}
+}
+
+func NotFound(code string, args ...interface{}) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Args: args}
+}
+
+func Conflict(code string, args ...interface{}) *Error {
+	return &Error{Kind: KindConflict, Code: code, Args: args}
+}
+
+func PaymentRequired(code string, args ...interface{}) *Error {
+	return &Error{Kind: KindPaymentRequired, Code: code, Args: args}
+}
+
+// Internal reports a failure that is not the client's fault. context and err
+// are kept for the logs; clients only see a generic message.
+func Internal(context string, err error) *Error {
+	if err == nil {
+		err = errors.New(context)
+	} else {
+		err = fmt.Errorf("%s: %w", context, err)
+	}
+	return &Error{Kind: KindInternal, Code: "internal_error", Err: err}
+}
@@ -8,8 +8,15 @@ func GetExpiredTime() time.Time {
 	return expiredTime
 }
 
-func GetExpiredTimeGym() time.Time {
-	now := time.Now()
-	expiredTime := now.Add(30 * 24 * time.Hour)
-	return expiredTime
+// GetMembershipEndTime returns when a membership on the given plan that
+// starts at start runs out.
+func GetMembershipEndTime(start time.Time, plan string) time.Time {
+	switch plan {
+	case "quarterly":
+		return start.AddDate(0, 3, 0)
+	case "yearly":
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
 }
@@ -4,16 +4,28 @@ import (
 	"math/rand"
 	"strconv"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type MakananRequest struct {
-	Nama          string   `json:"nama"`
-	Jenis         string   `json:"jenis"`
-	Bahan         []string `json:"bahan"`
-	CookingStep   []string `json:"cookingStep"`
-	Kalori        int      `json:"kalori"`
-	ListFranchise []string `json:"listFranchise"`
-	Protein       int      `json:"protein"`
+	Nama          string      `json:"nama" validate:"max=100"`
+	Jenis         string      `json:"jenis"`
+	Bahan         []string    `json:"bahan" validate:"dive,required"`
+	CookingStep   []string    `json:"cookingStep" validate:"dive,required"`
+	Kalori        int         `json:"kalori" validate:"nutrisi"`
+	ListFranchise []uuid.UUID `json:"listFranchise"`
+	Protein       int         `json:"protein" validate:"nutrisi"`
+}
+
+// MakananTranslationRequest sets the text of a makanan in a language other
+// than Indonesian.
+type MakananTranslationRequest struct {
+	IdMakanan   string   `json:"idMakanan" validate:"required"`
+	Locale      string   `json:"locale" validate:"enum=translation_locale"`
+	Nama        string   `json:"nama" validate:"required,max=100"`
+	Bahan       []string `json:"bahan" validate:"required,min=1,dive,required"`
+	CookingStep []string `json:"cookingStep" validate:"required,min=1,dive,required"`
 }
 
 func GenerateIdMakanan(namaMakanan string) string {
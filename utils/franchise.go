@@ -1,13 +1,34 @@
 package utils
 
+import "github.com/google/uuid"
+
 type FranchiseRequest struct {
-	NamaFranchise      string  `json:"namaFranchise"`
-	AlamatFranchise    string  `json:"alamatFranchise"`
-	LongitudeFranchise float64 `json:"longitudeFranchise"`
-	LatitudeFranchise  float64 `json:"latitudeFranchise"`
-	EmailFranchise     string  `json:"emailFranchise"`
-	PasswordFranchise  string  `json:"passwordFranchise"`
-	NoTeleponFranchise string  `json:"noTeleponFranchise"`
+	NamaFranchise      string  `json:"namaFranchise" validate:"max=100"`
+	AlamatFranchise    string  `json:"alamatFranchise" validate:"max=255"`
+	LongitudeFranchise float64 `json:"longitudeFranchise" validate:"lng"`
+	LatitudeFranchise  float64 `json:"latitudeFranchise" validate:"lat"`
+	EmailFranchise     string  `json:"emailFranchise" validate:"omitempty,email"`
+	PasswordFranchise  string  `json:"passwordFranchise" validate:"omitempty,min=8"`
+	NoTeleponFranchise string  `json:"noTeleponFranchise" validate:"omitempty,phone"`
 	FotoFranchise      string  `json:"fotoFranchise"`
 	LokasiFranchise    string  `json:"lokasiFranchise"`
 }
+
+// FranchiseMenuRequest updates a franchise's own menu item. Fields left nil
+// are kept as they are.
+type FranchiseMenuRequest struct {
+	Harga    *int
+	Tersedia *bool
+}
+
+type CartItemRequest struct {
+	IdFranchise uuid.UUID
+	IdMakanan   string
+	Jumlah      int
+}
+
+type OrderRequest struct {
+	WaktuMakan     string
+	Catatan        string
+	IdempotencyKey string
+}
@@ -0,0 +1,55 @@
+package utils
+
+import "math"
+
+const earthRadiusKm = 6371.0
+
+// HaversineDistance returns the great-circle distance in kilometres between
+// two coordinates given in degrees.
+func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox is a latitude/longitude rectangle that contains every point
+// within a radius of a centre, used to prefilter rows in SQL before the exact
+// haversine distance is computed.
+type BoundingBox struct {
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+}
+
+func NewBoundingBox(lat, lon, radiusKm float64) BoundingBox {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	box := BoundingBox{
+		MinLat: math.Max(lat-dLat, -90),
+		MaxLat: math.Min(lat+dLat, 90),
+		MinLon: -180,
+		MaxLon: 180,
+	}
+	// Near the poles or across the antimeridian the longitude range wraps,
+	// so only latitude is used to narrow the search there.
+	if box.MinLat > -90 && box.MaxLat < 90 {
+		dLon := dLat / math.Cos(toRadians(lat))
+		if lon-dLon >= -180 && lon+dLon <= 180 {
+			box.MinLon = lon - dLon
+			box.MaxLon = lon + dLon
+		}
+	}
+	return box
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+type NearbyRequest struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+	Page      int
+	Limit     int
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		wantKm                 float64
+	}{
+		{"same point", -6.2, 106.8, -6.2, 106.8, 0},
+		{"Jakarta to Bandung", -6.2, 106.8167, -6.9175, 107.6191, 119.26},
+		{"one degree along the equator", 0, 0, 0, 1, 111.19},
+		{"one degree along a meridian", 10, 20, 11, 20, 111.19},
+		{"across the antimeridian", 0, 179.9, 0, -179.9, 22.24},
+		{"both longitudes at the antimeridian", 10, 180, 10, -180, 0},
+		{"the pole at any longitude", 90, 0, 90, 135, 0},
+		{"pole to pole", 90, 0, -90, 0, math.Pi * earthRadiusKm},
+		{"antipodes", 0, 0, 0, 180, math.Pi * earthRadiusKm},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := HaversineDistance(test.lat1, test.lon1, test.lat2, test.lon2)
+			if math.Abs(got-test.wantKm) > 0.05 {
+				t.Errorf("HaversineDistance = %.3f km, want %.3f km", got, test.wantKm)
+			}
+			if back := HaversineDistance(test.lat2, test.lon2, test.lat1, test.lon1); math.Abs(back-got) > 1e-9 {
+				t.Errorf("distance back = %.3f km, want %.3f km", back, got)
+			}
+		})
+	}
+}
+
+func TestNewBoundingBox(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		radiusKm float64
+		want     BoundingBox
+		// inside are points within radiusKm of the centre.
+		inside [][2]float64
+	}{
+		{
+			name: "equator", lat: 0, lon: 0, radiusKm: 111.2,
+			want:   BoundingBox{MinLat: -1, MaxLat: 1, MinLon: -1, MaxLon: 1},
+			inside: [][2]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}},
+		},
+		{
+			name: "longitude widens away from the equator", lat: 60, lon: 10, radiusKm: 111.2,
+			want:   BoundingBox{MinLat: 59, MaxLat: 61, MinLon: 8, MaxLon: 12},
+			inside: [][2]float64{{60, 11.99}, {60, 8.01}},
+		},
+		{
+			name: "across the antimeridian", lat: -16.5, lon: 179.9, radiusKm: 50,
+			want:   BoundingBox{MinLat: -16.95, MaxLat: -16.05, MinLon: -180, MaxLon: 180},
+			inside: [][2]float64{{-16.5, -179.9}, {-16.5, 179.5}},
+		},
+		{
+			name: "across the pole", lat: 89.9, lon: 45, radiusKm: 50,
+			want:   BoundingBox{MinLat: 89.45, MaxLat: 90, MinLon: -180, MaxLon: 180},
+			inside: [][2]float64{{89.9, -135}, {89.7, 45}},
+		},
+		{
+			name: "zero radius", lat: -6.2, lon: 106.8, radiusKm: 0,
+			want:   BoundingBox{MinLat: -6.2, MaxLat: -6.2, MinLon: 106.8, MaxLon: 106.8},
+			inside: [][2]float64{{-6.2, 106.8}},
+		},
+	}
+	near := func(got, want float64) bool { return math.Abs(got-want) < 0.01 }
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			box := NewBoundingBox(test.lat, test.lon, test.radiusKm)
+			if !near(box.MinLat, test.want.MinLat) || !near(box.MaxLat, test.want.MaxLat) ||
+				!near(box.MinLon, test.want.MinLon) || !near(box.MaxLon, test.want.MaxLon) {
+				t.Errorf("NewBoundingBox = %+v, want %+v", box, test.want)
+			}
+			for _, point := range test.inside {
+				if HaversineDistance(test.lat, test.lon, point[0], point[1]) > test.radiusKm {
+					t.Fatalf("fixture (%v, %v) is outside the radius", point[0], point[1])
+				}
+				if point[0] < box.MinLat || point[0] > box.MaxLat || point[1] < box.MinLon || point[1] > box.MaxLon {
+					t.Errorf("(%v, %v) is within the radius but outside the box %+v", point[0], point[1], box)
+				}
+			}
+		})
+	}
+}
@@ -1,9 +1,19 @@
 package utils
 
+import "github.com/google/uuid"
+
 type GymRequest struct {
-	NamaGym    string  `json:"namaGym"`
-	AlamatGym  string  `json:"alamatGym"`
-	Latitude   float64 `json:"latitude"`
-	Longitude  float64 `json:"longitude"`
-	LinkGoogle string  `json:"linkGoogle"`
+	NamaGym    string  `json:"namaGym" validate:"max=100"`
+	AlamatGym  string  `json:"alamatGym" validate:"max=255"`
+	Latitude   float64 `json:"latitude" validate:"lat"`
+	Longitude  float64 `json:"longitude" validate:"lng"`
+	LinkGoogle string  `json:"linkGoogle" validate:"omitempty,url"`
+}
+
+type KodeGymRequest struct {
+	IdGym          uuid.UUID `json:"uid"`
+	Mode           string    `json:"mode" validate:"omitempty,enum=kode_gym_mode"`
+	Plan           string    `json:"plan" validate:"omitempty,enum=plan"`
+	MaxRedemptions int       `json:"maxRedemptions" validate:"min=0"`
+	ExpiredDays    int       `json:"expiredDays" validate:"min=0"`
 }
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"strings"
-	"unicode"
 )
 
 func GenerateKodeGym(namaGym string) string {
@@ -11,21 +10,3 @@ func GenerateKodeGym(namaGym string) string {
 	kodeGym := strings.Split(namaGym, " ")[0]
 	return kodeGym + RandomInt(5)
 }
-
-func GetAlphabetFromCode(code string) string {
-	alphabet := ""
-	for _, char := range code {
-		if unicode.IsLetter(char) {
-			alphabet += string(char)
-		} else {
-			break
-		}
-	}
-	return alphabet
-}
-
-func CheckGymLikeness(gymName string, gymCode string) bool {
-	//remove the number from gymCode
-	gymCode = GetAlphabetFromCode(gymCode)
-	return strings.Contains(strings.ToLower(gymName), strings.ToLower(gymCode))
-}
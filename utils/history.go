@@ -3,10 +3,10 @@ package utils
 import "time"
 
 type HistoryRequest struct {
-	IdBreakfast   string
-	IdLunch       string
-	IdDinner      string
-	TotalProtein  int
-	TotalKalori   int
-	TanggalDibuat time.Time
+	IdBreakfast   string    `json:"breakfastId" validate:"max=36"`
+	IdLunch       string    `json:"lunchId" validate:"max=36"`
+	IdDinner      string    `json:"dinnerId" validate:"max=36"`
+	TotalProtein  int       `json:"totalProtein" validate:"nutrisi"`
+	TotalKalori   int       `json:"totalCalories" validate:"nutrisi"`
+	TanggalDibuat time.Time `json:"tanggalDibuat"`
 }
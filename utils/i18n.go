@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locales the API speaks. Indonesian is used when the client does not ask
+// for a supported language.
+const (
+	LocaleID      = "id"
+	LocaleEN      = "en"
+	DefaultLocale = LocaleID
+)
+
+// Locale picks the supported language the client prefers most from an
+// Accept-Language header such as "en-US,en;q=0.9,id;q=0.8".
+func Locale(acceptLanguage string) string {
+	type preference struct {
+		language string
+		quality  float64
+	}
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if language == "" || quality <= 0 {
+			continue
+		}
+		preferences = append(preferences, preference{language, quality})
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	for _, preference := range preferences {
+		if _, ok := messages[preference.language]; ok {
+			return preference.language
+		}
+	}
+	return DefaultLocale
+}
+
+// T returns the message for key in locale, formatted with args. Keys missing
+// from locale fall back to Indonesian, and unknown keys are returned as is.
+func T(locale string, key string, args ...interface{}) string {
+	message, ok := messages[locale][key]
+	if !ok {
+		message, ok = messages[DefaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Localize fills in the human-readable parts of response for locale: the
+// message, which services set to a catalogue key, and the message of every
+// field error.
+func Localize(locale string, response Response) Response {
+	response.Messages = T(locale, response.Messages, response.MessageArgs...)
+	response.Errors = LocalizeFields(locale, response.Errors)
+	return response
+}
+
+// LocalizeFields fills in the message of every field error for locale.
+func LocalizeFields(locale string, fields []FieldError) []FieldError {
+	if len(fields) == 0 {
+		return fields
+	}
+	localized := make([]FieldError, len(fields))
+	for i, field := range fields {
+		field.Message = T(locale, "field."+field.Rule, append([]interface{}{field.Field}, field.Params...)...)
+		localized[i] = field
+	}
+	return localized
+}
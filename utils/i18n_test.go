@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", LocaleID},
+		{"en", LocaleEN},
+		{"EN-us", LocaleEN},
+		{"en-US,en;q=0.9,id;q=0.8", LocaleEN},
+		{"id;q=0.5, en;q=0.9", LocaleEN},
+		{"fr-FR,en;q=0.5", LocaleEN},
+		{"fr, de", LocaleID},
+		{"en;q=0, id", LocaleID},
+		{"en;q=abc, id;q=0.1", LocaleID},
+		{"*", LocaleID},
+	}
+	for _, test := range tests {
+		t.Run(test.acceptLanguage, func(t *testing.T) {
+			if got := Locale(test.acceptLanguage); got != test.want {
+				t.Errorf("Locale(%q) = %q, want %q", test.acceptLanguage, got, test.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(LocaleEN, "email_taken"); got != "Email is already registered" {
+		t.Errorf("T(en, email_taken) = %q", got)
+	}
+	if got := T(LocaleID, "field.required", "email"); got != "email wajib diisi" {
+		t.Errorf("T(id, field.required) = %q", got)
+	}
+	if got := T("fr", "email_taken"); got != messages[DefaultLocale]["email_taken"] {
+		t.Errorf("T(fr, email_taken) = %q, want the Indonesian message", got)
+	}
+	if got := T(LocaleEN, "no_such_key"); got != "no_such_key" {
+		t.Errorf("T(en, no_such_key) = %q, want the key", got)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	response := Localize(LocaleEN, Response{
+		StatusCode: 400,
+		Messages:   "validation_failed",
+		Code:       "validation_failed",
+		Errors:     []FieldError{Field("limit", "between", 1, 100)},
+	})
+	if response.Messages != "Invalid request" || response.Code != "validation_failed" {
+		t.Errorf("Localize = %q (%s), want the English message and the code kept", response.Messages, response.Code)
+	}
+	if len(response.Errors) != 1 || !strings.Contains(response.Errors[0].Message, "limit") || !strings.Contains(response.Errors[0].Message, "100") {
+		t.Errorf("field errors = %+v, want the field and its bounds in the message", response.Errors)
+	}
+}
+
+var formatVerb = regexp.MustCompile(`%[sdvfq]`)
+
+// TestMessagesComplete checks that every locale has every message, taking
+// the same arguments.
+func TestMessagesComplete(t *testing.T) {
+	for locale, catalogue := range messages {
+		for key, message := range messages[DefaultLocale] {
+			translated, ok := catalogue[key]
+			if !ok {
+				t.Errorf("%s has no message for %s", locale, key)
+				continue
+			}
+			if got, want := formatVerb.FindAllString(translated, -1), formatVerb.FindAllString(message, -1); strings.Join(got, "") != strings.Join(want, "") {
+				t.Errorf("%s message for %s takes %v, want %v", locale, key, got, want)
+			}
+		}
+		for key := range catalogue {
+			if _, ok := messages[DefaultLocale][key]; !ok {
+				t.Errorf("%s has %s, which %s lacks", locale, key, DefaultLocale)
+			}
+		}
+	}
+}
+
+var serviceErrorCode = regexp.MustCompile(`utils\.(?:Invalid|Unauthorized|Forbidden|NotFound|Conflict|PaymentRequired)\("([a-z_]+)"`)
+
+// TestServiceErrorsHaveMessages checks that every code the services and
+// controllers return is in the catalogue, so no client is shown a bare code.
+func TestServiceErrorsHaveMessages(t *testing.T) {
+	files, err := filepath.Glob("../app/*/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var missing []string
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range serviceErrorCode.FindAllSubmatch(source, -1) {
+			if _, ok := messages[DefaultLocale][string(match[1])]; !ok {
+				missing = append(missing, filepath.Base(file)+": "+string(match[1]))
+			}
+		}
+	}
+	sort.Strings(missing)
+	for _, code := range missing {
+		t.Errorf("no message for %s", code)
+	}
+}
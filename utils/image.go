@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	MaxPhotoSize   = 5 << 20
+	maxPhotoPixels = 25_000_000
+)
+
+var (
+	ErrPhotoTooLarge    = errors.New("photo is larger than 5 MB")
+	ErrPhotoUnsupported = errors.New("photo is not a JPEG, PNG or WebP image")
+)
+
+type PhotoVariant struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// ProcessedPhoto holds the re-encoded variants of an upload. Hash is derived
+// from the uploaded bytes, so identical uploads map to the same filenames and
+// different uploads never overwrite each other.
+type ProcessedPhoto struct {
+	Hash      string
+	Original  PhotoVariant
+	Medium    PhotoVariant
+	Thumbnail PhotoVariant
+}
+
+// ProcessPhoto validates an uploaded image by its content, applies and strips
+// EXIF metadata and renders the standard sizes. Every variant is re-encoded,
+// which drops EXIF and any other embedded metadata.
+func ProcessPhoto(reader io.Reader) (ProcessedPhoto, error) {
+	var photo ProcessedPhoto
+	data, err := io.ReadAll(io.LimitReader(reader, MaxPhotoSize+1))
+	if err != nil {
+		return photo, err
+	}
+	if len(data) > MaxPhotoSize {
+		return photo, ErrPhotoTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/webp" {
+		return photo, ErrPhotoUnsupported
+	}
+	// Check the dimensions before decoding so a tiny file cannot expand into
+	// a huge bitmap.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width*config.Height > maxPhotoPixels {
+		return photo, ErrPhotoUnsupported
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return photo, ErrPhotoUnsupported
+	}
+	if contentType == "image/jpeg" {
+		img = orientImage(img, jpegOrientation(data))
+	}
+
+	sum := sha256.Sum256(data)
+	photo.Hash = hex.EncodeToString(sum[:16])
+	// WebP has no encoder in the standard library; PNG keeps its quality and
+	// transparency.
+	encodeAsJPEG := contentType == "image/jpeg"
+	if photo.Original, err = encodePhotoVariant("original", img, encodeAsJPEG); err != nil {
+		return photo, err
+	}
+	if photo.Medium, err = encodePhotoVariant("medium", fitImage(img, 800), encodeAsJPEG); err != nil {
+		return photo, err
+	}
+	if photo.Thumbnail, err = encodePhotoVariant("thumbnail", fitImage(img, 200), encodeAsJPEG); err != nil {
+		return photo, err
+	}
+	return photo, nil
+}
+
+func encodePhotoVariant(name string, img image.Image, asJPEG bool) (PhotoVariant, error) {
+	var buf bytes.Buffer
+	variant := PhotoVariant{Name: name}
+	if asJPEG {
+		variant.ContentType = "image/jpeg"
+		variant.Extension = ".jpg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return variant, err
+		}
+	} else {
+		variant.ContentType = "image/png"
+		variant.Extension = ".png"
+		if err := png.Encode(&buf, img); err != nil {
+			return variant, err
+		}
+	}
+	variant.Data = buf.Bytes()
+	return variant, nil
+}
+
+// fitImage scales img down so its longest side is at most maxSide. Smaller
+// images are returned untouched.
+func fitImage(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+	if width >= height {
+		height = height * maxSide / width
+		width = maxSide
+	} else {
+		width = width * maxSide / height
+		height = maxSide
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation (1-8) of a JPEG, or 1 when
+// there is none. Phone cameras store rotated pixels and rely on this tag,
+// so it has to be applied before the metadata is dropped.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		if marker == 0xE1 && length >= 8 && string(data[i+4:i+10]) == "Exif\x00\x00" {
+			return exifOrientation(data[i+10 : end])
+		}
+		i = end
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// orientImage rotates and flips img so it displays upright for the given
+// EXIF orientation.
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
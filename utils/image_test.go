@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+)
+
+// The fixtures in testdata show red, green, blue and white quadrants (from
+// top left to bottom right) on a 48x32 image once their EXIF orientation is
+// applied. Their pixels are stored rotated or flipped accordingly.
+func TestProcessPhotoOrientation(t *testing.T) {
+	want := []struct {
+		x, y  int
+		color color.RGBA
+	}{
+		{12, 8, color.RGBA{255, 0, 0, 255}},
+		{36, 8, color.RGBA{0, 255, 0, 255}},
+		{12, 24, color.RGBA{0, 0, 255, 255}},
+		{36, 24, color.RGBA{255, 255, 255, 255}},
+	}
+	for orientation := 1; orientation <= 8; orientation++ {
+		t.Run(fmt.Sprintf("orientation %d", orientation), func(t *testing.T) {
+			data, err := os.ReadFile(fmt.Sprintf("testdata/orientation_%d.jpg", orientation))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := jpegOrientation(data); got != orientation {
+				t.Fatalf("jpegOrientation = %d, want %d", got, orientation)
+			}
+			photo, err := ProcessPhoto(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("ProcessPhoto: %v", err)
+			}
+			if jpegOrientation(photo.Original.Data) != 1 {
+				t.Error("original variant still carries an EXIF orientation")
+			}
+			img, _, err := image.Decode(bytes.NewReader(photo.Original.Data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := img.Bounds().Size(); size != (image.Point{48, 32}) {
+				t.Fatalf("size = %v, want 48x32", size)
+			}
+			for _, pixel := range want {
+				if got := img.At(pixel.x, pixel.y); !closeColor(got, pixel.color) {
+					t.Errorf("pixel (%d, %d) = %v, want %v", pixel.x, pixel.y, got, pixel.color)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessPhotoLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"at most 5 MB", paddedPNG(t, MaxPhotoSize), nil},
+		{"over 5 MB", paddedPNG(t, MaxPhotoSize+1), ErrPhotoTooLarge},
+		{"over 25 megapixels", encodePNG(t, image.NewGray(image.Rect(0, 0, 5001, 5000))), ErrPhotoUnsupported},
+		{"header claiming over 25 megapixels", pngHeader(100000, 100000), ErrPhotoUnsupported},
+		{"not an image", []byte("hello, this is not a photo"), ErrPhotoUnsupported},
+		{"GIF", []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), ErrPhotoUnsupported},
+		{"empty", nil, ErrPhotoUnsupported},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ProcessPhoto(bytes.NewReader(test.data))
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("ProcessPhoto error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessPhotoVariants(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		original      image.Point
+		medium        image.Point
+		thumbnail     image.Point
+	}{
+		{"landscape", 1600, 800, image.Pt(1600, 800), image.Pt(800, 400), image.Pt(200, 100)},
+		{"portrait", 600, 1200, image.Pt(600, 1200), image.Pt(400, 800), image.Pt(100, 200)},
+		{"between sizes", 500, 250, image.Pt(500, 250), image.Pt(500, 250), image.Pt(200, 100)},
+		{"smaller than a thumbnail", 120, 60, image.Pt(120, 60), image.Pt(120, 60), image.Pt(120, 60)},
+		{"very thin", 2000, 2, image.Pt(2000, 2), image.Pt(800, 1), image.Pt(200, 1)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, test.width, test.height)))
+			photo, err := ProcessPhoto(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("ProcessPhoto: %v", err)
+			}
+			for _, variant := range []struct {
+				variant PhotoVariant
+				want    image.Point
+			}{
+				{photo.Original, test.original},
+				{photo.Medium, test.medium},
+				{photo.Thumbnail, test.thumbnail},
+			} {
+				config, _, err := image.DecodeConfig(bytes.NewReader(variant.variant.Data))
+				if err != nil {
+					t.Fatalf("%s: %v", variant.variant.Name, err)
+				}
+				if got := image.Pt(config.Width, config.Height); got != variant.want {
+					t.Errorf("%s size = %v, want %v", variant.variant.Name, got, variant.want)
+				}
+				if variant.variant.ContentType != "image/png" || variant.variant.Extension != ".png" {
+					t.Errorf("%s type = %s %s, want image/png .png", variant.variant.Name, variant.variant.ContentType, variant.variant.Extension)
+				}
+			}
+
+			again, err := ProcessPhoto(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again.Hash != photo.Hash {
+				t.Errorf("hash of the same upload changed: %s, then %s", photo.Hash, again.Hash)
+			}
+		})
+	}
+}
+
+func closeColor(got color.Color, want color.RGBA) bool {
+	r, g, b, _ := got.RGBA()
+	near := func(got uint32, want uint8) bool {
+		diff := int(got>>8) - int(want)
+		return diff > -60 && diff < 60
+	}
+	return near(r, want.R) && near(g, want.G) && near(b, want.B)
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// paddedPNG returns a small PNG followed by junk up to size bytes. Decoders
+// stop at the IEND chunk, so only the size sets it apart from a valid photo.
+func paddedPNG(t *testing.T, size int) []byte {
+	data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	return append(data, make([]byte, size-len(data))...)
+}
+
+// pngHeader returns a PNG with a header claiming the given dimensions and no
+// pixel data.
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 2, 0, 0, 0)
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4))
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
@@ -7,6 +7,20 @@ import (
 	"github.com/google/uuid"
 )
 
+var jwtSecret = "kalorize"
+
+// SetJWTSecret replaces the signing key used for issuing and parsing tokens.
+// It is called once on startup with the configured secret.
+func SetJWTSecret(secret string) {
+	if secret != "" {
+		jwtSecret = secret
+	}
+}
+
+func JWTSecret() string {
+	return jwtSecret
+}
+
 func GenerateJWTAccessToken(id uuid.UUID, fullname, email, key string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"IdUser":   id.String(),
@@ -34,3 +48,34 @@ func GenerateJWTRefreshToken(id uuid.UUID, fullname, email, key string) (string,
 	}
 	return tokenString, err
 }
+
+// GenerateJWTFranchiseToken issues the access token for a franchise operator.
+// It carries IdFranchise instead of the Email claim, so it is not accepted by
+// the endpoints that look users up by email.
+func GenerateJWTFranchiseToken(id uuid.UUID, namaFranchise, key string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"IdFranchise":   id.String(),
+		"NamaFranchise": namaFranchise,
+		"exp":           time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day()+1, 0, 0, 0, 0, time.Now().Location()).Unix(),
+	})
+	tokenString, err := token.SignedString([]byte(key))
+	if err != nil {
+		return err.Error(), err
+	}
+	return tokenString, err
+}
+
+// TokenExpiry reads the exp claim of a token without verifying it, for
+// reporting purposes only. It returns nil when the token has no expiry.
+func TokenExpiry(tokenString string) *time.Time {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
+		return nil
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil
+	}
+	expiry := time.Unix(int64(exp), 0)
+	return &expiry
+}
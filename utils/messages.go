@@ -0,0 +1,201 @@
+package utils
+
+// messages is the catalogue of texts shown to people, by locale and key.
+// Error keys are the error codes; field.<rule> keys describe a rejected
+// field and receive the field name followed by the rule parameters.
+var messages = map[string]map[string]string{
+	LocaleID: {
+		// Success
+		"success":                   "Berhasil",
+		"kode_gym_valid":            "Kode gym valid",
+		"kode_gym_used":             "Kode gym sudah digunakan",
+		"history_created":           "History baru berhasil dibuat",
+		"history_updated":           "History berhasil diperbarui",
+		"account_erasure_scheduled": "Akun kamu akan dihapus permanen pada %s. Login sebelum tanggal itu untuk membatalkannya",
+		"export_processing":         "Ekspor data sedang diproses",
+		"not_enough_members":        "Belum cukup member untuk ditampilkan",
+
+		// Errors
+		"internal_error":          "Terjadi kesalahan pada server, coba lagi nanti",
+		"validation_failed":       "Data yang dikirim tidak valid",
+		"invalid_query":           "Parameter query tidak valid",
+		"invalid_id":              "ID tidak valid",
+		"invalid_form":            "Form tidak valid",
+		"file_required":           "File wajib diunggah",
+		"photo_too_large":         "Foto tidak boleh lebih dari 5 MB",
+		"photo_unsupported":       "Foto harus berupa gambar JPEG, PNG atau WebP",
+		"unauthorized":            "Kamu belum login atau sesi kamu sudah habis",
+		"invalid_token":           "Token tidak valid",
+		"forbidden":               "Kamu tidak punya akses ke sumber daya ini",
+		"credentials_required":    "Email dan password tidak boleh kosong",
+		"email_invalid":           "Email kamu tidak valid",
+		"email_not_registered":    "Email kamu belum terdaftar",
+		"email_taken":             "Email sudah terdaftar",
+		"wrong_password":          "Password kamu salah",
+		"wrong_old_password":      "Password lama kamu salah",
+		"password_mismatch":       "Password dan konfirmasi password tidak sama",
+		"fields_required":         "Semua field harus diisi",
+		"account_deactivated":     "Akun kamu dinonaktifkan",
+		"account_deleted":         "Akun kamu sudah dihapus",
+		"user_not_found":          "User tidak ditemukan",
+		"user_erased":             "User sudah dihapus permanen",
+		"user_not_restorable":     "User tidak sedang dihapus atau sudah dihapus permanen",
+		"cannot_erase_self":       "Admin tidak bisa menghapus akunnya sendiri",
+		"membership_missing":      "User %s belum punya membership",
+		"unknown_action":          "Aksi tidak dikenal",
+		"gym_not_found":           "Gym tidak ditemukan",
+		"gym_inactive":            "Gym tidak menerima member baru",
+		"kode_gym_not_found":      "Kode gym tidak ditemukan",
+		"kode_gym_invalid":        "Kode gym tidak sesuai",
+		"kode_gym_unused":         "Kode gym belum digunakan",
+		"kode_gym_already_used":   "Kode gym sudah pernah kamu gunakan",
+		"kode_gym_expired":        "Kode gym sudah kedaluwarsa",
+		"kode_gym_revoked":        "Kode gym sudah dicabut",
+		"kode_gym_exhausted":      "Kode gym sudah mencapai batas penggunaan",
+		"expiry_in_past":          "Waktu kedaluwarsa harus di masa depan",
+		"franchise_not_found":     "Franchise tidak ditemukan",
+		"franchise_email_taken":   "Email franchise sudah terdaftar",
+		"makanan_not_found":       "Makanan tidak ditemukan",
+		"makanan_unavailable":     "%s sedang tidak tersedia",
+		"menu_item_not_found":     "Makanan tidak ada di menu franchise",
+		"cart_empty":              "Keranjang kosong",
+		"cart_other_franchise":    "Keranjang berisi makanan dari franchise lain, kosongkan keranjang dulu",
+		"order_not_found":         "Pesanan tidak ditemukan",
+		"order_cancelled":         "Pesanan sudah dibatalkan",
+		"order_in_progress":       "Pesanan sudah diproses franchise",
+		"order_status_changed":    "Status pesanan sudah berubah, muat ulang pesanan",
+		"order_status_transition": "Status pesanan tidak bisa diubah dari %s ke %s",
+		"payment_declined":        "Pembayaran ditolak",
+		"export_not_found":        "Ekspor data tidak ditemukan",
+		"export_not_ready":        "Ekspor data belum siap atau sudah kedaluwarsa",
+		"invalid_signature":       "Tautan file tidak valid atau sudah kedaluwarsa",
+
+		// Errors raised by the framework
+		"bad_request":              "Request tidak valid",
+		"not_found":                "Halaman tidak ditemukan",
+		"method_not_allowed":       "Metode tidak diizinkan",
+		"unsupported_media_type":   "Tipe konten tidak didukung",
+		"request_entity_too_large": "Request terlalu besar",
+		"too_many_requests":        "Terlalu banyak request, coba lagi nanti",
+		"internal_server_error":    "Terjadi kesalahan pada server, coba lagi nanti",
+		"service_unavailable":      "Layanan sedang tidak tersedia, coba lagi nanti",
+
+		// Field errors
+		"field.required":   "%s wajib diisi",
+		"field.email":      "%s harus berupa alamat email yang valid",
+		"field.url":        "%s harus berupa URL yang valid",
+		"field.phone":      "%s harus berupa nomor telepon Indonesia",
+		"field.lat":        "%s harus berupa angka antara -90 dan 90",
+		"field.lng":        "%s harus berupa angka antara -180 dan 180",
+		"field.oneof":      "%s harus salah satu dari %s",
+		"field.min":        "%s paling sedikit %v",
+		"field.max":        "%s paling banyak %v",
+		"field.min_length": "%s paling sedikit berisi %v item atau karakter",
+		"field.max_length": "%s paling banyak berisi %v item atau karakter",
+		"field.gt":         "%s harus lebih dari %v",
+		"field.between":    "%s harus antara %v dan %v",
+		"field.radius":     "%s harus lebih dari 0 dan paling banyak %v km",
+		"field.eqfield":    "%s harus sama dengan %s",
+		"field.uuid":       "%s harus berupa ID yang valid",
+		"field.datetime":   "%s harus berupa tanggal atau waktu RFC 3339",
+		"field.boolean":    "%s harus true atau false",
+		"field.invalid":    "%s tidak valid",
+	},
+	LocaleEN: {
+		// Success
+		"success":                   "Success",
+		"kode_gym_valid":            "Gym code is valid",
+		"kode_gym_used":             "Gym code has been used",
+		"history_created":           "New history created successfully",
+		"history_updated":           "History updated successfully",
+		"account_erasure_scheduled": "Your account will be permanently deleted on %s. Log in before then to cancel",
+		"export_processing":         "Your data export is being prepared",
+		"not_enough_members":        "Not enough members to show yet",
+
+		// Errors
+		"internal_error":          "Something went wrong on our side, please try again later",
+		"validation_failed":       "Invalid request",
+		"invalid_query":           "Invalid query parameters",
+		"invalid_id":              "Invalid id",
+		"invalid_form":            "Invalid form",
+		"file_required":           "A file must be uploaded",
+		"photo_too_large":         "Photos must not be larger than 5 MB",
+		"photo_unsupported":       "Photos must be JPEG, PNG or WebP images",
+		"unauthorized":            "You are not logged in or your session has expired",
+		"invalid_token":           "Invalid token",
+		"forbidden":               "You do not have access to this resource",
+		"credentials_required":    "Email and password must not be empty",
+		"email_invalid":           "Your email is not valid",
+		"email_not_registered":    "Your email is not registered",
+		"email_taken":             "Email is already registered",
+		"wrong_password":          "Your password is wrong",
+		"wrong_old_password":      "Your old password is wrong",
+		"password_mismatch":       "Password and password confirmation do not match",
+		"fields_required":         "All fields are required",
+		"account_deactivated":     "Your account has been deactivated",
+		"account_deleted":         "Your account has been deleted",
+		"user_not_found":          "User not found",
+		"user_erased":             "User has already been erased",
+		"user_not_restorable":     "User is not deleted or has already been erased",
+		"cannot_erase_self":       "Admins cannot erase themselves",
+		"membership_missing":      "User %s has no membership",
+		"unknown_action":          "Unknown action",
+		"gym_not_found":           "Gym not found",
+		"gym_inactive":            "The gym is not accepting new members",
+		"kode_gym_not_found":      "Gym code not found",
+		"kode_gym_invalid":        "Gym code does not match",
+		"kode_gym_unused":         "Gym code has not been used",
+		"kode_gym_already_used":   "You have already used this gym code",
+		"kode_gym_expired":        "Gym code has expired",
+		"kode_gym_revoked":        "Gym code has been revoked",
+		"kode_gym_exhausted":      "Gym code has reached its usage limit",
+		"expiry_in_past":          "Expiry time must be in the future",
+		"franchise_not_found":     "Franchise not found",
+		"franchise_email_taken":   "Franchise email already registered",
+		"makanan_not_found":       "Food not found",
+		"makanan_unavailable":     "%s is currently unavailable",
+		"menu_item_not_found":     "Food is not on the franchise menu",
+		"cart_empty":              "Your cart is empty",
+		"cart_other_franchise":    "Your cart has food from another franchise, empty it first",
+		"order_not_found":         "Order not found",
+		"order_cancelled":         "The order has been cancelled",
+		"order_in_progress":       "The franchise is already processing the order",
+		"order_status_changed":    "The order status has changed, reload the order",
+		"order_status_transition": "Order status cannot change from %s to %s",
+		"payment_declined":        "Payment declined",
+		"export_not_found":        "Data export not found",
+		"export_not_ready":        "Data export is not ready or has expired",
+		"invalid_signature":       "The file link is invalid or has expired",
+
+		// Errors raised by the framework
+		"bad_request":              "Bad request",
+		"not_found":                "Not found",
+		"method_not_allowed":       "Method not allowed",
+		"unsupported_media_type":   "Unsupported media type",
+		"request_entity_too_large": "Request entity too large",
+		"too_many_requests":        "Too many requests, please try again later",
+		"internal_server_error":    "Something went wrong on our side, please try again later",
+		"service_unavailable":      "Service unavailable, please try again later",
+
+		// Field errors
+		"field.required":   "%s is required",
+		"field.email":      "%s must be a valid email address",
+		"field.url":        "%s must be a valid URL",
+		"field.phone":      "%s must be an Indonesian phone number",
+		"field.lat":        "%s must be a number between -90 and 90",
+		"field.lng":        "%s must be a number between -180 and 180",
+		"field.oneof":      "%s must be one of %s",
+		"field.min":        "%s must be at least %v",
+		"field.max":        "%s must be at most %v",
+		"field.min_length": "%s must have at least %v items or characters",
+		"field.max_length": "%s must have at most %v items or characters",
+		"field.gt":         "%s must be greater than %v",
+		"field.between":    "%s must be between %v and %v",
+		"field.radius":     "%s must be greater than 0 and at most %v km",
+		"field.eqfield":    "%s must match %s",
+		"field.uuid":       "%s must be a valid id",
+		"field.datetime":   "%s must be a date or an RFC 3339 time",
+		"field.boolean":    "%s must be true or false",
+		"field.invalid":    "%s is not valid",
+	},
+}
@@ -0,0 +1,22 @@
+package utils
+
+// Page is the response data of a paginated listing.
+type Page struct {
+	Items interface{} `json:"items"`
+	Page  int         `json:"page"`
+	Limit int         `json:"limit"`
+	Total int         `json:"total"`
+}
+
+// PageBounds returns the slice bounds of a 1-based page over total items.
+func PageBounds(total int, page int, limit int) (int, int) {
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -5,5 +5,4 @@ import "mime/multipart"
 type UploadedPhoto struct {
 	Handler *multipart.FileHeader
 	File    multipart.File
-	Alias   string
 }
@@ -1,7 +1,14 @@
 package utils
 
+// Response is the envelope of every JSON response. Services set Messages to
+// a catalogue key, with MessageArgs for keys that take arguments; it is
+// translated into the language of the request before being written.
 type Response struct {
-	StatusCode int         `json:"statusCode"`
-	Messages   string      `json:"messages"`
-	Data       interface{} `json:"data"`
+	StatusCode int          `json:"statusCode"`
+	Messages   string       `json:"messages"`
+	Data       interface{}  `json:"data"`
+	Code       string       `json:"code,omitempty"`
+	Errors     []FieldError `json:"errors,omitempty"`
+
+	MessageArgs []interface{} `json:"-"`
 }
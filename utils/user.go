@@ -2,23 +2,26 @@ package utils
 
 import "github.com/google/uuid"
 
+// UserRequest carries user fields into the services. It is shared by
+// registration, profile edits and the questionnaire, so fields are optional
+// here and each payload decides which ones are required.
 type UserRequest struct {
-	IdUser               uuid.UUID `json:"id_user"`
-	Fullname             string    `json:"fullname"`
-	Email                string    `json:"email"`
+	IdUser               uuid.UUID `json:"idUser"`
+	Fullname             string    `json:"fullname" validate:"max=100"`
+	Email                string    `json:"email" validate:"omitempty,email"`
 	Password             string    `json:"password"`
-	PasswordConfirmation string    `json:"password_confirmation"`
-	JenisKelamin         int       `json:"jenis_kelamin"`
-	Role                 string    `json:"role"`
-	NoTelepon            string    `json:"no_telepon"`
-	ReferalCode          string    `json:"referal_code"`
-	Umur                 int       `json:"umur"`
-	BeratBadan           int       `json:"berat_badan"`
-	TinggiBadan          int       `json:"tinggi_badan"`
-	FrekuensiGym         int       `json:"frekuensi_gym"`
-	TargetKalori         int       `json:"target_kalori"`
+	PasswordConfirmation string    `json:"passwordConfirmation"`
+	JenisKelamin         int       `json:"jenisKelamin" validate:"jenis_kelamin"`
+	Role                 string    `json:"role" validate:"omitempty,enum=role"`
+	NoTelepon            string    `json:"noTelepon" validate:"omitempty,phone"`
+	ReferalCode          string    `json:"referalCode"`
+	Umur                 int       `json:"umur" validate:"umur"`
+	BeratBadan           int       `json:"beratBadan" validate:"berat_badan"`
+	TinggiBadan          int       `json:"tinggiBadan" validate:"tinggi_badan"`
+	FrekuensiGym         int       `json:"frekuensiGym" validate:"frekuensi_gym"`
+	TargetKalori         int       `json:"targetKalori" validate:"target_kalori"`
 	Foto                 string    `json:"foto"`
-	FotoUrl              string    `json:"foto_url"`
+	FotoUrl              string    `json:"fotoUrl"`
 }
 
 func ValidateAndAssign(target *string, source string) {
@@ -32,3 +35,32 @@ func ValidateAndAssignInt(target *int, source *int) {
 		*target = *source
 	}
 }
+
+// UserFilter narrows the admin user listing. Empty fields match every user.
+type UserFilter struct {
+	Search           string
+	Role             string
+	IdGym            *uuid.UUID
+	MembershipStatus string
+	Deactivated      *bool
+	Deleted          bool
+	Sort             string
+	Order            string
+	Page             int
+	Limit            int
+}
+
+const (
+	BulkUserDeactivate       = "deactivate"
+	BulkUserActivate         = "activate"
+	BulkUserChangeRole       = "change_role"
+	BulkUserExtendMembership = "extend_membership"
+)
+
+// BulkUserRequest applies one action to several users at once.
+type BulkUserRequest struct {
+	IdUsers []uuid.UUID
+	Action  string
+	Role    string
+	Days    int
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"errors"
+	"kalorize-api/app/models"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// enums lists the values accepted by the enum=<name> rule.
+var enums = map[string][]string{
+	"role":          {"user", "admin", "gym_owner"},
+	"kode_gym_mode": {models.KodeGymSingleUse, models.KodeGymMultiUse},
+	"plan":          {models.MembershipMonthly, models.MembershipQuarterly, models.MembershipYearly},
+	"waktu_makan":   {"breakfast", "lunch", "dinner"},
+	"order_status":  {models.OrderPlaced, models.OrderAccepted, models.OrderReady, models.OrderPickedUp, models.OrderCancelled},
+	"bulk_action":   {BulkUserDeactivate, BulkUserActivate, BulkUserChangeRole, BulkUserExtendMembership},
+	// Indonesian is stored on the makanan itself, so it is not a translation.
+	"translation_locale": {LocaleEN},
+}
+
+// aliases name the ranges of the profile and nutrition fields, so payloads
+// and request DTOs share a single definition of what is valid.
+var aliases = map[string]string{
+	"umur":          "min=0,max=100",
+	"berat_badan":   "min=0,max=500",
+	"tinggi_badan":  "min=0,max=300",
+	"jenis_kelamin": "min=0,max=1",
+	"frekuensi_gym": "min=0,max=3",
+	"target_kalori": "min=0,max=2",
+	"nutrisi":       "min=0,max=10000",
+}
+
+// phonePattern matches Indonesian mobile and landline numbers written with a
+// leading 0, 62 or +62, once spaces and dashes are removed.
+var phonePattern = regexp.MustCompile(`^(?:\+62|62|0)[2-9][0-9]{7,11}$`)
+
+var validate = NewValidator()
+
+// NewValidator returns a validator with the custom rules of the API:
+//
+//   - phone: an Indonesian phone number
+//   - lat, lng: a finite latitude or longitude
+//   - enum=<name>: one of the values listed in enums
+//
+// plus the aliases for profile and nutrition ranges. Fields are reported by
+// their JSON (or form) name, so errors match what the client sent.
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "query"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				break
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		phone := strings.NewReplacer(" ", "", "-", "").Replace(fl.Field().String())
+		return phonePattern.MatchString(phone)
+	})
+	v.RegisterValidation("lat", func(fl validator.FieldLevel) bool {
+		return isCoordinate(fl.Field().Float(), 90)
+	})
+	v.RegisterValidation("lng", func(fl validator.FieldLevel) bool {
+		return isCoordinate(fl.Field().Float(), 180)
+	})
+	v.RegisterValidation("enum", func(fl validator.FieldLevel) bool {
+		for _, value := range enums[fl.Param()] {
+			if fl.Field().String() == value {
+				return true
+			}
+		}
+		return false
+	})
+	for alias, tags := range aliases {
+		v.RegisterAlias(alias, tags)
+	}
+	return v
+}
+
+func isCoordinate(value float64, limit float64) bool {
+	return !math.IsNaN(value) && value >= -limit && value <= limit
+}
+
+// Validate checks request against its validate tags. It returns nil or a
+// validation *Error listing every rejected field.
+func Validate(request interface{}) error {
+	err := validate.Struct(request)
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return ValidationFailed(validationErrs)
+	}
+	return err
+}
+
+// ValidationFailed turns validator errors into a validation *Error with one
+// FieldError per rejected field.
+func ValidationFailed(validationErrs validator.ValidationErrors) *Error {
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		rule, params := fieldRule(fieldErr)
+		fields = append(fields, FieldError{
+			Field:  fieldErr.Field(),
+			Code:   fieldErr.Tag(),
+			Rule:   rule,
+			Params: params,
+		})
+	}
+	return Invalid("validation_failed", fields...)
+}
+
+// ValidationAlias returns the rules an alias such as umur stands for.
+func ValidationAlias(name string) (string, bool) {
+	tags, ok := aliases[name]
+	return tags, ok
+}
+
+// EnumValues returns the values accepted by enum=<name>.
+func EnumValues(name string) []string {
+	return enums[name]
+}
+
+// fieldRule picks the catalogue rule describing fieldErr and its parameters.
+// Aliases are described by the rule that failed, so umur reports the bound
+// that was crossed.
+func fieldRule(fieldErr validator.FieldError) (string, []interface{}) {
+	countable := fieldErr.Kind() == reflect.String || fieldErr.Kind() == reflect.Slice
+	switch fieldErr.ActualTag() {
+	case "required", "required_if":
+		return "required", nil
+	case "email", "url", "phone", "lat", "lng":
+		return fieldErr.ActualTag(), nil
+	case "enum":
+		return "oneof", []interface{}{strings.Join(enums[fieldErr.Param()], ", ")}
+	case "oneof":
+		return "oneof", []interface{}{strings.Join(strings.Fields(fieldErr.Param()), ", ")}
+	case "min", "gte":
+		if countable {
+			return "min_length", []interface{}{fieldErr.Param()}
+		}
+		return "min", []interface{}{fieldErr.Param()}
+	case "max", "lte":
+		if countable {
+			return "max_length", []interface{}{fieldErr.Param()}
+		}
+		return "max", []interface{}{fieldErr.Param()}
+	case "eqfield":
+		// Param is the Go name of the other field; clients know it by its
+		// camelCase JSON name.
+		other := fieldErr.Param()
+		return "eqfield", []interface{}{strings.ToLower(other[:1]) + other[1:]}
+	default:
+		return "invalid", nil
+	}
+}
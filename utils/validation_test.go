@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type validationRequest struct {
+	Phone     string   `json:"phone" validate:"omitempty,phone"`
+	Latitude  float64  `json:"latitude" validate:"lat"`
+	Longitude float64  `json:"longitude" validate:"lng"`
+	Role      string   `json:"role" validate:"omitempty,enum=role"`
+	Umur      int      `json:"umur" validate:"umur"`
+	Nama      string   `json:"nama" validate:"max=5"`
+	Bahan     []string `json:"bahan" validate:"min=1"`
+	Password  string   `json:"password"`
+	Confirm   string   `json:"passwordConfirmation" validate:"eqfield=Password"`
+	Internal  string   `json:"-" validate:"omitempty,email"`
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() validationRequest {
+		return validationRequest{Phone: "0812-3456-7890", Latitude: -6.2, Longitude: 106.8, Role: "gym_owner", Umur: 30, Nama: "Budi", Bahan: []string{"nasi"}}
+	}
+	tests := []struct {
+		name       string
+		change     func(request *validationRequest)
+		wantField  string
+		wantRule   string
+		wantParams []interface{}
+	}{
+		{name: "valid", change: func(request *validationRequest) {}},
+		{name: "phone with +62", change: func(request *validationRequest) { request.Phone = "+62 812 3456 7890" }},
+		{name: "landline", change: func(request *validationRequest) { request.Phone = "021-5550123" }},
+		{name: "foreign phone", change: func(request *validationRequest) { request.Phone = "+1 202 555 0123" }, wantField: "phone", wantRule: "phone"},
+		{name: "phone too short", change: func(request *validationRequest) { request.Phone = "081234" }, wantField: "phone", wantRule: "phone"},
+		{name: "latitude at the pole", change: func(request *validationRequest) { request.Latitude = 90 }},
+		{name: "latitude out of range", change: func(request *validationRequest) { request.Latitude = 90.5 }, wantField: "latitude", wantRule: "lat"},
+		{name: "latitude NaN", change: func(request *validationRequest) { request.Latitude = math.NaN() }, wantField: "latitude", wantRule: "lat"},
+		{name: "longitude at the antimeridian", change: func(request *validationRequest) { request.Longitude = -180 }},
+		{name: "longitude out of range", change: func(request *validationRequest) { request.Longitude = 181 }, wantField: "longitude", wantRule: "lng"},
+		{name: "longitude infinite", change: func(request *validationRequest) { request.Longitude = math.Inf(1) }, wantField: "longitude", wantRule: "lng"},
+		{name: "unknown enum value", change: func(request *validationRequest) { request.Role = "owner" }, wantField: "role", wantRule: "oneof", wantParams: []interface{}{"user, admin, gym_owner"}},
+		{name: "alias upper bound", change: func(request *validationRequest) { request.Umur = 101 }, wantField: "umur", wantRule: "max", wantParams: []interface{}{"100"}},
+		{name: "alias lower bound", change: func(request *validationRequest) { request.Umur = -1 }, wantField: "umur", wantRule: "min", wantParams: []interface{}{"0"}},
+		{name: "string too long", change: func(request *validationRequest) { request.Nama = "Budiman" }, wantField: "nama", wantRule: "max_length", wantParams: []interface{}{"5"}},
+		{name: "empty list", change: func(request *validationRequest) { request.Bahan = nil }, wantField: "bahan", wantRule: "min_length", wantParams: []interface{}{"1"}},
+		{name: "confirmation differs", change: func(request *validationRequest) { request.Password = "secret" }, wantField: "passwordConfirmation", wantRule: "eqfield", wantParams: []interface{}{"password"}},
+		{name: "field without a JSON name", change: func(request *validationRequest) { request.Internal = "nope" }, wantField: "Internal", wantRule: "email"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := valid()
+			test.change(&request)
+			err := Validate(request)
+			if test.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *Error
+			if !errors.As(err, &validationErr) || validationErr.Code != "validation_failed" || validationErr.Kind != KindValidation {
+				t.Fatalf("Validate = %v, want a validation_failed error", err)
+			}
+			if len(validationErr.Fields) != 1 {
+				t.Fatalf("fields = %+v, want only %s", validationErr.Fields, test.wantField)
+			}
+			field := validationErr.Fields[0]
+			if field.Field != test.wantField || field.Rule != test.wantRule || !reflect.DeepEqual(field.Params, test.wantParams) {
+				t.Errorf("field = %s %s %v, want %s %s %v", field.Field, field.Rule, field.Params, test.wantField, test.wantRule, test.wantParams)
+			}
+			for _, locale := range []string{LocaleID, LocaleEN} {
+				if message := LocalizeFields(locale, validationErr.Fields)[0].Message; message == "field."+field.Rule {
+					t.Errorf("%s has no %s message for rule %s", field.Field, locale, field.Rule)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationAlias(t *testing.T) {
+	if tags, ok := ValidationAlias("umur"); !ok || tags != "min=0,max=100" {
+		t.Errorf("ValidationAlias(umur) = %q, %v", tags, ok)
+	}
+	if _, ok := ValidationAlias("unknown"); ok {
+		t.Error("ValidationAlias(unknown) found an alias")
+	}
+	if values := EnumValues("waktu_makan"); !reflect.DeepEqual(values, []string{"breakfast", "lunch", "dinner"}) {
+		t.Errorf("EnumValues(waktu_makan) = %v", values)
+	}
+}